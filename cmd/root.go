@@ -23,6 +23,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/kitops-ml/kitops/pkg/cmd/convert"
+	"github.com/kitops-ml/kitops/pkg/cmd/deps"
 	"github.com/kitops-ml/kitops/pkg/cmd/dev"
 	"github.com/kitops-ml/kitops/pkg/cmd/diff"
 	"github.com/kitops-ml/kitops/pkg/cmd/info"
@@ -31,6 +33,7 @@ import (
 	"github.com/kitops-ml/kitops/pkg/cmd/kitimport"
 	"github.com/kitops-ml/kitops/pkg/cmd/kitinit"
 	"github.com/kitops-ml/kitops/pkg/cmd/list"
+	"github.com/kitops-ml/kitops/pkg/cmd/lock"
 	"github.com/kitops-ml/kitops/pkg/cmd/login"
 	"github.com/kitops-ml/kitops/pkg/cmd/logout"
 	"github.com/kitops-ml/kitops/pkg/cmd/pack"
@@ -39,6 +42,7 @@ import (
 	"github.com/kitops-ml/kitops/pkg/cmd/remove"
 	"github.com/kitops-ml/kitops/pkg/cmd/tag"
 	"github.com/kitops-ml/kitops/pkg/cmd/unpack"
+	"github.com/kitops-ml/kitops/pkg/cmd/validate"
 	"github.com/kitops-ml/kitops/pkg/cmd/version"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem/cache"
@@ -102,6 +106,7 @@ func RunCommand() *cobra.Command {
 			}
 			ctx := context.WithValue(cmd.Context(), constants.ConfigKey{}, configHome)
 			cache.SetCacheHome(constants.CachePath(configHome))
+			cache.SetUnpackedCacheHome(constants.UnpackedCachePath(configHome))
 			cmd.SetContext(ctx)
 
 			update.CheckForUpdate(configHome)
@@ -159,6 +164,10 @@ func addSubcommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(diff.DiffCommand())
 	rootCmd.AddCommand(kitimport.ImportCommand())
 	rootCmd.AddCommand(kitcache.CacheCommand())
+	rootCmd.AddCommand(convert.ConvertCommand())
+	rootCmd.AddCommand(validate.ValidateCommand())
+	rootCmd.AddCommand(lock.LockCommand())
+	rootCmd.AddCommand(deps.DepsCommand())
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -26,12 +26,17 @@ import (
 
 type (
 	KitFile struct {
-		ManifestVersion string    `json:"manifestVersion" yaml:"manifestVersion"`
-		Package         Package   `json:"package,omitempty" yaml:"package,omitempty"`
-		Model           *Model    `json:"model,omitempty" yaml:"model,omitempty"`
-		Code            []Code    `json:"code,omitempty" yaml:"code,omitempty"`
-		DataSets        []DataSet `json:"datasets,omitempty" yaml:"datasets,omitempty"`
-		Docs            []Docs    `json:"docs,omitempty" yaml:"docs,omitempty"`
+		ManifestVersion string     `json:"manifestVersion" yaml:"manifestVersion"`
+		Package         Package    `json:"package,omitempty" yaml:"package,omitempty"`
+		Model           *Model     `json:"model,omitempty" yaml:"model,omitempty"`
+		ModelCard       *ModelCard `json:"modelcard,omitempty" yaml:"modelcard,omitempty"`
+		Metrics         []Metric   `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+		Code            []Code     `json:"code,omitempty" yaml:"code,omitempty"`
+		DataSets        []DataSet  `json:"datasets,omitempty" yaml:"datasets,omitempty"`
+		Docs            []Docs     `json:"docs,omitempty" yaml:"docs,omitempty"`
+		// Include lists Kitfile fragments to merge into this Kitfile, relative to the context directory.
+		// Includes are resolved before the Kitfile is packed, so this is always empty in a modelkit.
+		Include []string `json:"include,omitempty" yaml:"include,omitempty"`
 	}
 
 	Package struct {
@@ -42,10 +47,35 @@ type (
 		Authors     []string `json:"authors,omitempty" yaml:"authors,omitempty,flow"`
 	}
 
+	// ModelCard describes how the model is intended to be used and the data it was trained on
+	ModelCard struct {
+		// IntendedUse describes the tasks and users the model is intended for
+		IntendedUse string `json:"intendedUse,omitempty" yaml:"intendedUse,omitempty"`
+		// Limitations describes known limitations, biases, and risks of the model
+		Limitations string `json:"limitations,omitempty" yaml:"limitations,omitempty"`
+		// TrainingData lists the data the model was trained on, either as names of datasets in the
+		// Kitfile or as external references (e.g. URLs or Hugging Face dataset IDs)
+		TrainingData []string `json:"trainingData,omitempty" yaml:"trainingData,omitempty"`
+	}
+
+	// Metric is the result of evaluating the model, optionally on a specific dataset and split
+	Metric struct {
+		// Name is the name of the metric (e.g. accuracy, f1)
+		Name  string  `json:"name" yaml:"name"`
+		Value float64 `json:"value" yaml:"value"`
+		// Dataset is the dataset the metric was measured on, either as the name of a dataset in
+		// the Kitfile or as an external reference
+		Dataset string `json:"dataset,omitempty" yaml:"dataset,omitempty"`
+		// Split is the split of the dataset the metric was measured on
+		Split string `json:"split,omitempty" yaml:"split,omitempty"`
+	}
+
 	Docs struct {
-		Path        string `json:"path" yaml:"path"`
-		Description string `json:"description,omitempty" yaml:"description,omitempty"`
-		*LayerInfo  `json:",inline" yaml:",inline"`
+		Path         string `json:"path" yaml:"path"`
+		Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+		MaxLayerSize string `json:"maxLayerSize,omitempty" yaml:"maxLayerSize,omitempty"`
+		Compression  string `json:"compression,omitempty" yaml:"compression,omitempty"`
+		*LayerInfo   `json:",inline" yaml:",inline"`
 	}
 
 	Model struct {
@@ -64,22 +94,32 @@ type (
 		//  * Numbers will be converted to decimal representations (0xFF -> 255, 1.2e+3 -> 1200)
 		//  * Maps will be sorted alphabetically by key
 		Parameters any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-		*LayerInfo `json:",inline" yaml:",inline"`
+		// MaxLayerSize is the maximum size of a layer for this element (e.g. "10GB"). Directories
+		// larger than this are split into multiple layers.
+		MaxLayerSize string `json:"maxLayerSize,omitempty" yaml:"maxLayerSize,omitempty"`
+		// Compression overrides the compression used for this element's layers ('none', 'gzip',
+		// 'gzip-fastest', or 'auto')
+		Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+		*LayerInfo  `json:",inline" yaml:",inline"`
 	}
 
 	ModelPart struct {
-		Name       string `json:"name,omitempty" yaml:"name,omitempty"`
-		Path       string `json:"path,omitempty" yaml:"path,omitempty"`
-		License    string `json:"license,omitempty" yaml:"license,omitempty"`
-		Type       string `json:"type,omitempty" yaml:"type,omitempty"`
-		*LayerInfo `json:",inline" yaml:",inline"`
+		Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+		Path         string `json:"path,omitempty" yaml:"path,omitempty"`
+		License      string `json:"license,omitempty" yaml:"license,omitempty"`
+		Type         string `json:"type,omitempty" yaml:"type,omitempty"`
+		MaxLayerSize string `json:"maxLayerSize,omitempty" yaml:"maxLayerSize,omitempty"`
+		Compression  string `json:"compression,omitempty" yaml:"compression,omitempty"`
+		*LayerInfo   `json:",inline" yaml:",inline"`
 	}
 
 	Code struct {
-		Path        string `json:"path,omitempty" yaml:"path,omitempty"`
-		Description string `json:"description,omitempty" yaml:"description,omitempty"`
-		License     string `json:"license,omitempty" yaml:"license,omitempty"`
-		*LayerInfo  `json:",inline" yaml:",inline"`
+		Path         string `json:"path,omitempty" yaml:"path,omitempty"`
+		Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+		License      string `json:"license,omitempty" yaml:"license,omitempty"`
+		MaxLayerSize string `json:"maxLayerSize,omitempty" yaml:"maxLayerSize,omitempty"`
+		Compression  string `json:"compression,omitempty" yaml:"compression,omitempty"`
+		*LayerInfo   `json:",inline" yaml:",inline"`
 	}
 
 	DataSet struct {
@@ -87,6 +127,11 @@ type (
 		Path        string `json:"path,omitempty" yaml:"path,omitempty"`
 		Description string `json:"description,omitempty" yaml:"description,omitempty"`
 		License     string `json:"license,omitempty" yaml:"license,omitempty"`
+		// Format is the format of the files in the dataset (e.g. parquet, csv, jsonl)
+		Format string `json:"format,omitempty" yaml:"format,omitempty"`
+		// Splits divides the files in the dataset into named subsets, such as train, validation,
+		// and test, that can be unpacked individually.
+		Splits []DatasetSplit `json:"splits,omitempty" yaml:"splits,omitempty"`
 		// Parameters is an arbitrary section of yaml that can be used to store any additional
 		// metadata relevant to the dataset, with a few caveats:
 		//  * Only a json-compatible subset of yaml is supported
@@ -95,7 +140,50 @@ type (
 		//  * Maps will be sorted alphabetically by key
 		//  * It's recommended to store metadata like preprocessing steps, formats, etc.
 		Parameters any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-		*LayerInfo `json:",inline" yaml:",inline"`
+		// Metadata describes the contents of the dataset. It is computed when packing with
+		// --dataset-metadata, replacing any existing value.
+		Metadata     *DatasetMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+		MaxLayerSize string           `json:"maxLayerSize,omitempty" yaml:"maxLayerSize,omitempty"`
+		Compression  string           `json:"compression,omitempty" yaml:"compression,omitempty"`
+		*LayerInfo   `json:",inline" yaml:",inline"`
+	}
+
+	DatasetSplit struct {
+		Name string `json:"name" yaml:"name"`
+		// Path is a glob pattern matching the files and directories in the split, relative to the
+		// context directory. Matched paths must be within the dataset's path.
+		Path string `json:"path" yaml:"path"`
+		// Format is the format of the files in the split, if it differs from the dataset's format
+		Format string `json:"format,omitempty" yaml:"format,omitempty"`
+		// Records is the number of records (e.g. rows or examples) in the split
+		Records int64 `json:"records,omitempty" yaml:"records,omitempty"`
+	}
+
+	DatasetMetadata struct {
+		// Files is the number of files in the dataset
+		Files int `json:"files" yaml:"files"`
+		// Size is the total size of files in the dataset, in bytes
+		Size int64 `json:"size" yaml:"size"`
+		// Tables describes tabular files (csv, jsonl, and parquet) in the dataset. Files with the
+		// same format and columns are described by a single table.
+		Tables []DatasetTable `json:"tables,omitempty" yaml:"tables,omitempty"`
+	}
+
+	DatasetTable struct {
+		// Format is the format of the files in the table (csv, jsonl, or parquet)
+		Format string `json:"format" yaml:"format"`
+		// Files is the number of files in the table
+		Files int `json:"files" yaml:"files"`
+		// Rows is the total number of rows in the table's files
+		Rows    int64           `json:"rows" yaml:"rows"`
+		Columns []DatasetColumn `json:"columns,omitempty" yaml:"columns,omitempty"`
+	}
+
+	DatasetColumn struct {
+		Name string `json:"name" yaml:"name"`
+		// Type is the type of values in the column, as stored in the file for parquet files, or
+		// as inferred from its values for other formats
+		Type string `json:"type" yaml:"type"`
 	}
 
 	LayerInfo struct {
@@ -103,9 +191,38 @@ type (
 		Digest string `json:"digest,omitempty" yaml:"-"`
 		// Diff ID (uncompressed digest) for the layer corresponding to this element
 		DiffId string `json:"diffId,omitempty" yaml:"-"`
+		// Shards lists the layers for this element, in order, if its path was split into multiple
+		// layers. In this case, Digest and DiffId refer to the first shard.
+		Shards []LayerShard `json:"shards,omitempty" yaml:"-"`
+	}
+
+	LayerShard struct {
+		// Digest for the layer containing this shard
+		Digest string `json:"digest"`
+		// Diff ID (uncompressed digest) for the layer containing this shard
+		DiffId string `json:"diffId"`
 	}
 )
 
+// NumLayers returns the number of layers used to store the element.
+func (li *LayerInfo) NumLayers() int {
+	if li == nil || len(li.Shards) == 0 {
+		return 1
+	}
+	return len(li.Shards)
+}
+
+// Shard returns the layer info for the idx-th layer used to store the element.
+func (li *LayerInfo) Shard(idx int) *LayerInfo {
+	if li == nil || len(li.Shards) == 0 {
+		return li
+	}
+	return &LayerInfo{
+		Digest: li.Shards[idx].Digest,
+		DiffId: li.Shards[idx].DiffId,
+	}
+}
+
 func (kf *KitFile) LoadModel(kitfileContent io.ReadCloser) error {
 	decoder := yaml.NewDecoder(kitfileContent)
 	decoder.KnownFields(true)
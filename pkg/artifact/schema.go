@@ -0,0 +1,176 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package artifact
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema used to describe the Kitfile format.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+}
+
+// fieldDescriptions holds descriptions for fields in the Kitfile schema, keyed by <struct name>.<field name>
+var fieldDescriptions = map[string]string{
+	"KitFile.ManifestVersion": "Version of the Kitfile format",
+	"KitFile.Include":         "Kitfile fragments to merge into this Kitfile, relative to the context directory",
+	"KitFile.Package":         "Metadata describing the modelkit",
+	"KitFile.Model":           "The model packaged in the modelkit",
+	"KitFile.Code":            "Code included in the modelkit",
+	"KitFile.DataSets":        "Datasets included in the modelkit",
+	"KitFile.ModelCard":       "Description of how the model is intended to be used and the data it was trained on",
+	"KitFile.Metrics":         "Results of evaluating the model",
+	"KitFile.Docs":            "Documentation included in the modelkit",
+	"Package.Name":            "Name of the modelkit",
+	"Package.Version":         "Version of the modelkit",
+	"Package.Description":     "Description of the modelkit",
+	"Package.License":         "SPDX license expression for the modelkit",
+	"Package.Authors":         "Authors of the modelkit",
+	"Model.Path":              "Path to the model, or a reference to another modelkit",
+	"Model.License":           "SPDX license expression for the model",
+	"Model.Framework":         "Framework used by the model",
+	"Model.Format":            "Format of the model files (e.g. gguf, safetensors)",
+	"Model.Parts":             "Additional files that are part of the model",
+	"Model.Parameters":        "Arbitrary data describing the model",
+	"ModelPart.Type":          "Type of the model part",
+	"DataSet.Path":            "Path to the dataset, or a reference to another modelkit",
+	"DataSet.Parameters":      "Arbitrary metadata describing the dataset",
+	"Code.Path":               "Path to the code, or a reference to another modelkit",
+	"DataSet.Format":          "Format of the files in the dataset (e.g. parquet, csv, jsonl)",
+	"DataSet.Splits":          "Named subsets of the dataset, such as train, validation, and test",
+	"DatasetSplit.Name":       "Name of the split, used to select it when unpacking",
+	"DatasetSplit.Path":       "Glob pattern matching the files in the split, relative to the modelkit context directory",
+	"DatasetSplit.Format":     "Format of the files in the split, if different from the dataset's format",
+	"DatasetSplit.Records":    "Number of records in the split",
+	"DataSet.Metadata":        "Information about the contents of the dataset, computed when packing with --dataset-metadata",
+	"DatasetMetadata.Files":   "Number of files in the dataset",
+	"DatasetMetadata.Size":    "Total size of files in the dataset, in bytes",
+	"DatasetMetadata.Tables":  "Tabular files in the dataset, grouped by format and columns",
+	"DatasetTable.Format":     "Format of the files (csv, jsonl, or parquet)",
+	"DatasetTable.Files":      "Number of files in the table",
+	"DatasetTable.Rows":       "Total number of rows in the table's files",
+	"DatasetColumn.Type":      "Type of the values in the column",
+	"ModelCard.IntendedUse":   "Tasks and users the model is intended for",
+	"ModelCard.Limitations":   "Known limitations, biases, and risks of the model",
+	"ModelCard.TrainingData":  "Data the model was trained on, as names of datasets in the Kitfile or external references",
+	"Metric.Name":             "Name of the metric (e.g. accuracy, f1)",
+	"Metric.Value":            "Value of the metric",
+	"Metric.Dataset":          "Dataset the metric was measured on, as the name of a dataset in the Kitfile or an external reference",
+	"Metric.Split":            "Split of the dataset the metric was measured on",
+	"*.Path":                  "Path to the file or directory, relative to the modelkit context directory",
+	"*.MaxLayerSize":          "Maximum size of a layer (e.g. 10GB); larger directories are split into multiple layers",
+	"*.Compression":           "Compression to use for layers",
+}
+
+// fieldEnums lists the allowed values for fields in the Kitfile schema, keyed as in fieldDescriptions
+var fieldEnums = map[string][]string{
+	"*.Compression": {"none", "gzip", "gzip-fastest", "auto"},
+}
+
+// KitfileJSONSchema returns a JSON Schema describing the Kitfile format. The schema is generated from the
+// KitFile type, and describes the YAML representation of a Kitfile (fields that are only set when packing,
+// such as layer digests, are not included).
+func KitfileJSONSchema() *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(KitFile{}))
+	schema.Schema = jsonSchemaDraft
+	schema.Title = "Kitfile"
+	schema.Description = "Configuration for a KitOps modelkit"
+	return schema
+}
+
+// MarshalKitfileJSONSchema returns the indented JSON encoding of KitfileJSONSchema.
+func MarshalKitfileJSONSchema() ([]byte, error) {
+	schemaBytes, err := json.MarshalIndent(KitfileJSONSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(schemaBytes, '\n'), nil
+}
+
+func schemaForType(t reflect.Type) *JSONSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Int, reflect.Int64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Struct:
+		additionalProperties := false
+		schema := &JSONSchema{
+			Type:                 "object",
+			Properties:           map[string]*JSONSchema{},
+			AdditionalProperties: &additionalProperties,
+		}
+		addStructFields(schema, t)
+		return schema
+	default:
+		// Fields such as parameters may contain any value
+		return &JSONSchema{}
+	}
+}
+
+// addStructFields adds the fields of struct type t to schema, including the fields of structs that are
+// inlined in the YAML representation of t.
+func addStructFields(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			addStructFields(schema, fieldType)
+			continue
+		}
+		fieldSchema := schemaForType(field.Type)
+		key := t.Name() + "." + field.Name
+		if description, ok := fieldDescriptions[key]; ok {
+			fieldSchema.Description = description
+		} else if description, ok := fieldDescriptions["*."+field.Name]; ok {
+			fieldSchema.Description = description
+		}
+		if enum, ok := fieldEnums["*."+field.Name]; ok {
+			fieldSchema.Enum = enum
+		}
+		schema.Properties[name] = fieldSchema
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
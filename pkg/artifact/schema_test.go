@@ -0,0 +1,46 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package artifact
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "update kitfile.schema.json from the KitFile type")
+
+// TestKitfileSchemaUpToDate checks that the published schema matches the KitFile type. To update it,
+// run 'go test ./pkg/artifact -run TestKitfileSchemaUpToDate -update-schema'
+func TestKitfileSchemaUpToDate(t *testing.T) {
+	generated, err := MarshalKitfileJSONSchema()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if *updateSchema {
+		if err := os.WriteFile("kitfile.schema.json", generated, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	published, err := os.ReadFile("kitfile.schema.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(published), string(generated), "kitfile.schema.json is out of date")
+}
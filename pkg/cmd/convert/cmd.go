@@ -0,0 +1,154 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package convert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	shortDesc = "Convert a modelkit to or from a ModelPack artifact"
+	longDesc  = `Convert a modelkit in local storage to a CNCF ModelPack artifact, or a ModelPack
+artifact to a modelkit.
+
+Conversion reuses the layers of the source as-is; only the manifest and config
+are rewritten. Layer media types are translated between the KitOps and ModelPack
+formats, the path of each layer is recorded in the ModelPack filepath annotation,
+and the Kitfile is translated to and from the ModelPack config.
+
+When converting to ModelPack, the original Kitfile is stored in the manifest
+annotations, so converting the artifact back restores the original modelkit.
+The translation of layer types is lossy, however: ModelPack has no equivalent of
+model parts, so all parts are stored as weight configuration (weight.config)
+layers, even if they contain weights such as LoRA adapters. Other ModelPack tools
+see these parts as configuration rather than as weights.
+ModelPack artifacts created by other tools are converted using a Kitfile
+generated from their config. Modelkits with chunked layers, and ModelPack
+artifacts with raw or zstd-compressed layers, cannot be converted.
+
+The converted artifact is stored under TARGET if specified. Otherwise, the tag
+of SOURCE is moved to the converted artifact. If SOURCE is a digest and no
+TARGET is specified, the converted artifact is stored untagged and its digest
+is printed.
+
+ModelPack artifacts can also be read directly by commands such as 'kit info'
+and 'kit unpack' without converting them first.`
+
+	example = `# Convert a modelkit to a ModelPack artifact, replacing the tag
+kit convert mymodel:latest --to modelpack
+
+# Convert a modelkit to a ModelPack artifact under a new tag
+kit convert mymodel:latest mymodel:latest-modelpack --to modelpack
+
+# Convert a ModelPack artifact to a modelkit
+kit convert mymodel:latest-modelpack mymodel:latest --to kitops`
+)
+
+const (
+	formatModelPack = "modelpack"
+	formatKitOps    = "kitops"
+)
+
+type convertOptions struct {
+	configHome string
+	to         string
+	sourceRef  *registry.Reference
+	targetRef  *registry.Reference
+}
+
+func (opts *convertOptions) complete(ctx context.Context, args []string) error {
+	configHome, ok := ctx.Value(constants.ConfigKey{}).(string)
+	if !ok {
+		return fmt.Errorf("default config path not set on command context")
+	}
+	opts.configHome = configHome
+
+	switch opts.to {
+	case formatModelPack, formatKitOps:
+	case "":
+		return fmt.Errorf("target format must be specified with --to")
+	default:
+		return fmt.Errorf("invalid target format %s: must be one of '%s' or '%s'", opts.to, formatModelPack, formatKitOps)
+	}
+
+	sourceRef, extraTags, err := util.ParseReference(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse reference: %w", err)
+	}
+	if len(extraTags) > 0 {
+		return fmt.Errorf("source reference cannot include multiple tags")
+	}
+	opts.sourceRef = sourceRef
+
+	opts.targetRef = sourceRef
+	if len(args) > 1 {
+		targetRef, extraTags, err := util.ParseReference(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to parse reference: %w", err)
+		}
+		if len(extraTags) > 0 {
+			return fmt.Errorf("target reference cannot include multiple tags")
+		}
+		if util.ReferenceIsDigest(targetRef.Reference) {
+			return fmt.Errorf("target reference must be a tag")
+		}
+		opts.targetRef = targetRef
+	}
+	return nil
+}
+
+func ConvertCommand() *cobra.Command {
+	opts := &convertOptions{}
+	cmd := &cobra.Command{
+		Use:     "convert SOURCE_MODELKIT [TARGET_MODELKIT] --to {modelpack|kitops}",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: example,
+		RunE:    runCommand(opts),
+		Args:    cobra.RangeArgs(1, 2),
+	}
+	cmd.Flags().StringVar(&opts.to, "to", "", "Format to convert to: 'modelpack' or 'kitops'")
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+func runCommand(opts *convertOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := opts.complete(cmd.Context(), args); err != nil {
+			return output.Fatalf("Invalid arguments: %s", err)
+		}
+		desc, err := runConvert(cmd.Context(), opts)
+		if err != nil {
+			return output.Fatalf("Failed to convert modelkit: %s", err)
+		}
+		if util.ReferenceIsDigest(opts.targetRef.Reference) {
+			output.Infof("Converted %s to %s: %s", util.FormatRepositoryForDisplay(opts.sourceRef.String()), opts.to, desc.Digest)
+		} else {
+			output.Infof("Converted %s to %s: %s (%s)", util.FormatRepositoryForDisplay(opts.sourceRef.String()), opts.to,
+				util.FormatRepositoryForDisplay(opts.targetRef.String()), desc.Digest)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/lib/modelpack"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// runConvert converts the artifact referenced by opts.sourceRef and stores the result under opts.targetRef,
+// returning the descriptor of the converted manifest.
+func runConvert(ctx context.Context, opts *convertOptions) (ocispec.Descriptor, error) {
+	storageHome := constants.StoragePath(opts.configHome)
+	sourceRepo, err := local.NewLocalRepo(storageHome, opts.sourceRef)
+	if err != nil {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to open local storage: %w", err)
+	}
+	_, manifest, err := util.ResolveManifest(ctx, sourceRepo, opts.sourceRef.Reference)
+	if err != nil {
+		return ocispec.DescriptorEmptyJSON, err
+	}
+	configBytes, err := content.FetchAll(ctx, sourceRepo, manifest.Config)
+	if err != nil {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var converted *ocispec.Manifest
+	var convertedConfig []byte
+	isModelPack := modelpack.IsModelPackManifest(manifest)
+	switch opts.to {
+	case formatModelPack:
+		if isModelPack {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("%s is already a ModelPack artifact", opts.sourceRef.Reference)
+		}
+		kitfile, err := util.GetConfig(ctx, sourceRepo, manifest.Config)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+		// Datasets and code from referenced modelkits are not stored in this modelkit's layers, and would be lost
+		for _, ref := range util.ReferencesFromKitfile(kitfile) {
+			if ref.BaseType != constants.ModelType {
+				return ocispec.DescriptorEmptyJSON, fmt.Errorf("cannot convert modelkit with %s that references modelkit %s", ref.BaseType, ref.Ref)
+			}
+		}
+		diffID := func(desc ocispec.Descriptor) (digest.Digest, error) {
+			return computeDiffID(ctx, sourceRepo, desc)
+		}
+		cursor := kfutils.NewLayerCursor(kitfile)
+		nextEntry := func(baseType string) (string, *artifact.LayerInfo, error) {
+			entry, err := cursor.Next(baseType)
+			if err != nil {
+				return "", nil, err
+			}
+			return entry.Path, entry.LayerInfo, nil
+		}
+		converted, convertedConfig, err = modelpack.FromModelKit(manifest, kitfile, configBytes, nextEntry, diffID)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+	case formatKitOps:
+		if !isModelPack {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("%s is already a modelkit", opts.sourceRef.Reference)
+		}
+		converted, convertedConfig, err = modelpack.ToModelKit(manifest, configBytes)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+	}
+
+	targetRepo := sourceRepo
+	if opts.targetRef.Registry != opts.sourceRef.Registry || opts.targetRef.Repository != opts.sourceRef.Repository {
+		// Local repos share blob storage, so only the config and manifest need to be saved to the target repo
+		targetRepo, err = local.NewLocalRepo(storageHome, opts.targetRef)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to open local storage: %w", err)
+		}
+	}
+	if err := pushIfNotExists(ctx, targetRepo, converted.Config, convertedConfig); err != nil {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to save config: %w", err)
+	}
+	manifestBytes, err := json.Marshal(converted)
+	if err != nil {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestBytes)
+	if err := pushIfNotExists(ctx, targetRepo, manifestDesc, manifestBytes); err != nil {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to save manifest: %w", err)
+	}
+	output.Debugf("Saved converted manifest %s", manifestDesc.Digest)
+
+	if !util.ReferenceIsDigest(opts.targetRef.Reference) {
+		if err := targetRepo.Tag(ctx, manifestDesc, opts.targetRef.Reference); err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to tag converted modelkit: %w", err)
+		}
+	}
+	return manifestDesc, nil
+}
+
+func pushIfNotExists(ctx context.Context, repo local.LocalRepo, desc ocispec.Descriptor, blob []byte) error {
+	exists, err := repo.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return repo.Push(ctx, desc, bytes.NewReader(blob))
+}
+
+// computeDiffID returns the digest of the uncompressed contents of a layer, for modelkits packed without
+// diffIDs in their Kitfile.
+func computeDiffID(ctx context.Context, repo local.LocalRepo, desc ocispec.Descriptor) (digest.Digest, error) {
+	rc, err := repo.Fetch(ctx, desc)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	var r io.Reader = rc
+	switch compression := constants.ParseMediaType(desc.MediaType).Compression; compression {
+	case constants.GzipCompression, constants.GzipFastestCompression:
+		gzr, err := gzip.NewReader(rc)
+		if err != nil {
+			return "", err
+		}
+		defer gzr.Close()
+		r = gzr
+	case constants.NoneCompression:
+	default:
+		return "", fmt.Errorf("unsupported compression %s", compression)
+	}
+	return digest.FromReader(r)
+}
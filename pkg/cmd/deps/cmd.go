@@ -0,0 +1,137 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package deps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	shortDesc = `Show the modelkits referenced by a modelkit`
+	longDesc  = `Show the graph of modelkits referenced by a modelkit.
+
+A modelkit can refer to other modelkits in the path of its model, datasets, or
+code, and those modelkits can in turn refer to others. This command follows the
+references in the same way as 'kit pull' and 'kit unpack', using the digests
+pinned when the modelkit was packed, and shows for each referenced modelkit the
+digest it resolves to, the layers it contributes, the registry it comes from,
+and whether it is available in local storage. Modelkits are read from local
+storage if present, and from the remote registry otherwise.
+
+Cycles in the references and references that cannot be resolved are shown in
+the graph, and cause the command to exit with an error.
+
+The graph can be printed as a tree (the default), as JSON, or in the Graphviz
+DOT format using the --output flag.`
+
+	example = `# Show the modelkits referenced by a modelkit
+kit deps registry.example.com/my-model:1.0.0
+
+# Render the graph as an image using Graphviz
+kit deps registry.example.com/my-model:1.0.0 --output dot | dot -Tpng -o deps.png`
+)
+
+const (
+	outputTree = "tree"
+	outputJson = "json"
+	outputDot  = "dot"
+)
+
+type depsOptions struct {
+	options.NetworkOptions
+	configHome string
+	modelRef   *registry.Reference
+	output     string
+}
+
+func (opts *depsOptions) complete(ctx context.Context, args []string) error {
+	configHome, ok := ctx.Value(constants.ConfigKey{}).(string)
+	if !ok {
+		return fmt.Errorf("default config path not set on command context")
+	}
+	opts.configHome = configHome
+
+	ref, extraTags, err := util.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	if len(extraTags) > 0 {
+		return fmt.Errorf("invalid reference format: extra tags are not supported: %s", strings.Join(extraTags, ", "))
+	}
+	opts.modelRef = ref
+
+	switch opts.output {
+	case outputTree, outputJson, outputDot:
+	default:
+		return fmt.Errorf("invalid output format %q: must be one of 'tree', 'json', or 'dot'", opts.output)
+	}
+
+	if err := opts.NetworkOptions.Complete(ctx, args); err != nil {
+		return err
+	}
+	return nil
+}
+
+func DepsCommand() *cobra.Command {
+	opts := &depsOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "deps [flags] MODELKIT",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: example,
+		RunE:    runCommand(opts),
+		Args:    cobra.ExactArgs(1),
+	}
+
+	opts.AddNetworkFlags(cmd)
+	cmd.Flags().StringVar(&opts.output, "output", outputTree, "Output format: 'tree', 'json', or 'dot'")
+	cmd.Flags().SortFlags = false
+
+	return cmd
+}
+
+func runCommand(opts *depsOptions) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := opts.complete(cmd.Context(), args); err != nil {
+			return output.Fatalf("Invalid arguments: %s", err)
+		}
+		graph, err := buildGraph(cmd.Context(), opts)
+		if err != nil {
+			return output.Fatalf("Error resolving modelkit: %s", err)
+		}
+		formatted, err := formatGraph(graph, opts.output)
+		if err != nil {
+			return output.Fatalln(err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), formatted)
+		if problems := graph.problems(); problems > 0 {
+			return output.Fatalf("Modelkit references contain %d cycles or unresolved references", problems)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// depNode is a modelkit in the graph of modelkit references. The root node is the modelkit the graph
+// was requested for; other nodes are modelkits referenced by an entry in their parent's Kitfile.
+type depNode struct {
+	Reference string `json:"reference"`
+	// Entry describes the entry in the parent's Kitfile that refers to this modelkit
+	Entry string `json:"entry,omitempty"`
+	// Pinned is the reference used to fetch the modelkit, if it was pinned to a digest when packing
+	Pinned   string `json:"pinned,omitempty"`
+	Registry string `json:"registry"`
+	Digest   string `json:"digest,omitempty"`
+	Local    bool   `json:"local"`
+	// Layers are the layers of the modelkit that are used by its parent
+	Layers     []depLayer `json:"layers,omitempty"`
+	Cycle      bool       `json:"cycle,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	References []*depNode `json:"references,omitempty"`
+}
+
+type depLayer struct {
+	Type   string `json:"type"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// problems returns the number of cycles and unresolved references in the graph rooted at n.
+func (n *depNode) problems() int {
+	count := 0
+	if n.Cycle || n.Error != "" {
+		count++
+	}
+	for _, ref := range n.References {
+		count += ref.problems()
+	}
+	return count
+}
+
+// buildGraph builds the graph of modelkits referenced by the modelkit in opts. References are followed as
+// when pulling or unpacking the modelkit: references pinned when packing are fetched by digest, and
+// references in a referenced modelkit are only followed for entries of the same type as the entry that
+// referred to it. Cycles and references that cannot be resolved are recorded in the graph rather than
+// returned as errors; an error is only returned if the requested modelkit cannot be found.
+func buildGraph(ctx context.Context, opts *depsOptions) (*depNode, error) {
+	root := &depNode{Reference: util.FormatRepositoryForDisplay(opts.modelRef.String())}
+	manifest, kitfile, err := fetchNode(ctx, opts, root, opts.modelRef)
+	if err != nil {
+		return nil, err
+	}
+	root.Layers = layersOfTypes(manifest)
+	lock, err := kfutils.LockFromManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	addReferences(ctx, opts, root, kitfile, util.ReferencesFromKitfile(kitfile), []string{root.Reference}, lock)
+	return root, nil
+}
+
+func addReferences(ctx context.Context, opts *depsOptions, node *depNode, kitfile *artifact.KitFile, refs []util.KitfileReference, refChain []string, lock *kfutils.Lock) {
+	for _, ref := range refs {
+		child := &depNode{Reference: ref.Ref, Entry: entryName(kitfile, ref)}
+		node.References = append(node.References, child)
+		if slices.Contains(refChain, ref.Ref) {
+			child.Cycle = true
+			continue
+		}
+		// The first entry in the chain is the requested modelkit, which does not count as a reference
+		if len(refChain) > constants.MaxModelRefChain {
+			child.Error = "reached maximum number of model references"
+			continue
+		}
+		pinned := lock.Pin(ref.Ref)
+		if pinned != ref.Ref {
+			child.Pinned = pinned
+		}
+		childRef, _, err := util.ParseReference(pinned)
+		if err != nil {
+			child.Error = err.Error()
+			continue
+		}
+		manifest, childKitfile, err := fetchNode(ctx, opts, child, childRef)
+		if err != nil {
+			child.Error = err.Error()
+			continue
+		}
+		baseTypes := []string{ref.BaseType}
+		if ref.BaseType == constants.ModelType {
+			baseTypes = append(baseTypes, constants.ModelPartType)
+		}
+		child.Layers = layersOfTypes(manifest, baseTypes...)
+		chain := append(slices.Clone(refChain), ref.Ref)
+		addReferences(ctx, opts, child, childKitfile, util.ReferencesOfType(childKitfile, ref.BaseType), chain, lock)
+	}
+}
+
+// fetchNode fetches the manifest and Kitfile for ref, checking local storage before the remote registry,
+// and records where the modelkit was found in node.
+func fetchNode(ctx context.Context, opts *depsOptions, node *depNode, ref *registry.Reference) (*ocispec.Manifest, *artifact.KitFile, error) {
+	node.Registry = ref.Registry
+	localRepo, err := local.NewLocalRepo(constants.StoragePath(opts.configHome), ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read local storage: %w", err)
+	}
+	desc, manifest, kitfile, localErr := util.ResolveManifestAndConfig(ctx, localRepo, ref.Reference)
+	if localErr == nil {
+		node.Local = true
+		node.Digest = desc.Digest.String()
+		return manifest, kitfile, nil
+	}
+	if ref.Registry == util.DefaultRegistry {
+		return nil, nil, fmt.Errorf("modelkit not found in local storage")
+	}
+
+	repository, err := remote.NewRepository(ctx, ref.Registry, ref.Repository, &opts.NetworkOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	desc, manifest, kitfile, err = util.ResolveManifestAndConfig(ctx, repository, ref.Reference)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch modelkit: %w", errors.Join(localErr, err))
+	}
+	node.Digest = desc.Digest.String()
+	return manifest, kitfile, nil
+}
+
+// layersOfTypes returns the layers in manifest with one of the given base types, or all layers if no
+// types are given.
+func layersOfTypes(manifest *ocispec.Manifest, baseTypes ...string) []depLayer {
+	var layers []depLayer
+	for _, layer := range manifest.Layers {
+		baseType := constants.ParseMediaType(layer.MediaType).BaseType
+		if len(baseTypes) > 0 && !slices.Contains(baseTypes, baseType) {
+			continue
+		}
+		layers = append(layers, depLayer{
+			Type:   baseType,
+			Digest: layer.Digest.String(),
+			Size:   layer.Size,
+		})
+	}
+	return layers
+}
+
+// entryName describes the entry in kitfile that contains ref.
+func entryName(kitfile *artifact.KitFile, ref util.KitfileReference) string {
+	switch ref.BaseType {
+	case constants.DatasetType:
+		if name := kitfile.DataSets[ref.Index].Name; name != "" {
+			return fmt.Sprintf("dataset %s", name)
+		}
+		return fmt.Sprintf("dataset %d", ref.Index)
+	case constants.CodeType:
+		return fmt.Sprintf("code %d", ref.Index)
+	default:
+		return ref.BaseType
+	}
+}
+
+func formatGraph(root *depNode, format string) (string, error) {
+	switch format {
+	case outputJson:
+		jsonBytes, err := json.MarshalIndent(root, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to format output: %w", err)
+		}
+		return string(jsonBytes), nil
+	case outputDot:
+		return formatDot(root), nil
+	default:
+		sb := &strings.Builder{}
+		sb.WriteString(describeNode(root))
+		formatTree(sb, root.References, "")
+		return strings.TrimSuffix(sb.String(), "\n"), nil
+	}
+}
+
+// formatTree writes nodes as a tree to sb, with each line prefixed by prefix.
+func formatTree(sb *strings.Builder, nodes []*depNode, prefix string) {
+	for idx, node := range nodes {
+		branch, childPrefix := "├── ", "│   "
+		if idx == len(nodes)-1 {
+			branch, childPrefix = "└── ", "    "
+		}
+		fmt.Fprintf(sb, "%s%s%s: %s", prefix, branch, node.Entry, describeNode(node))
+		formatTree(sb, node.References, prefix+childPrefix)
+	}
+}
+
+// describeNode returns a one-line description of node for tree output, ending in a newline.
+func describeNode(node *depNode) string {
+	sb := &strings.Builder{}
+	sb.WriteString(node.Reference)
+	switch {
+	case node.Cycle:
+		sb.WriteString(" (cycle)\n")
+		return sb.String()
+	case node.Error != "":
+		fmt.Fprintf(sb, " (unresolved: %s)\n", node.Error)
+		return sb.String()
+	}
+	fmt.Fprintf(sb, " %s", shortDigest(node.Digest))
+	var sources []string
+	if node.Registry != util.DefaultRegistry {
+		sources = append(sources, node.Registry)
+	}
+	if node.Local {
+		sources = append(sources, "local")
+	}
+	fmt.Fprintf(sb, " [%s]", strings.Join(sources, ", "))
+
+	var layerTypes []string
+	var size int64
+	for _, layer := range node.Layers {
+		if !slices.Contains(layerTypes, layer.Type) {
+			layerTypes = append(layerTypes, layer.Type)
+		}
+		size += layer.Size
+	}
+	if len(layerTypes) > 0 {
+		layersNoun := "layers"
+		if len(node.Layers) == 1 {
+			layersNoun = "layer"
+		}
+		fmt.Fprintf(sb, " %d %s (%s), %s", len(node.Layers), layersNoun, strings.Join(layerTypes, ", "), output.FormatBytes(size))
+	} else {
+		sb.WriteString(" no layers")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatDot formats the graph rooted at root in the Graphviz DOT format. Modelkits are identified by
+// reference, so a modelkit referenced more than once appears once in the graph, and cycles are shown as
+// edges back to an earlier node.
+func formatDot(root *depNode) string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph deps {\n")
+	written := map[string]bool{}
+	var writeNode func(node *depNode)
+	writeNode = func(node *depNode) {
+		if !node.Cycle && !written[node.Reference] {
+			written[node.Reference] = true
+			label := node.Reference
+			attrs := ""
+			if node.Error != "" {
+				label += "\nunresolved"
+				attrs = ", style=dashed, color=red"
+			} else {
+				label += "\n" + shortDigest(node.Digest)
+			}
+			fmt.Fprintf(sb, "  %s [label=%s%s];\n", strconv.Quote(node.Reference), strconv.Quote(label), attrs)
+		}
+		for _, ref := range node.References {
+			attrs := ""
+			if ref.Cycle {
+				attrs = ", color=red"
+			}
+			fmt.Fprintf(sb, "  %s -> %s [label=%s%s];\n", strconv.Quote(node.Reference), strconv.Quote(ref.Reference), strconv.Quote(ref.Entry), attrs)
+			writeNode(ref)
+		}
+	}
+	writeNode(root)
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func shortDigest(digest string) string {
+	if _, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+		return digest[:len(digest)-len(hex)+12]
+	}
+	return digest
+}
@@ -27,31 +27,14 @@ import (
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
 	"github.com/kitops-ml/kitops/pkg/lib/harness"
 	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
-	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 )
 
 func runDev(ctx context.Context, options *DevStartOptions) error {
-
-	kitfile := &artifact.KitFile{}
-
-	modelfile, err := os.Open(options.modelFile)
+	kitfile, err := loadKitfile(ctx, options)
 	if err != nil {
 		return err
 	}
-	defer modelfile.Close()
-	if err := kitfile.LoadModel(modelfile); err != nil {
-		return err
-	}
-	output.Infof("Loaded Kitfile: %s", options.modelFile)
-	if util.IsModelKitReference(kitfile.Model.Path) {
-		resolvedKitfile, err := kfutils.ResolveKitfile(ctx, options.configHome, kitfile.Model.Path, kitfile.Model.Path)
-		if err != nil {
-			return fmt.Errorf("failed to resolve referenced modelkit %s: %w", kitfile.Model.Path, err)
-		}
-		kitfile.Model.Path = resolvedKitfile.Model.Path
-		kitfile.Model.Parts = append(kitfile.Model.Parts, resolvedKitfile.Model.Parts...)
-	}
 	modelAbsPath, _, err := filesystem.VerifySubpath(options.contextDir, kitfile.Model.Path)
 	if err != nil {
 		return err
@@ -91,6 +74,36 @@ func stopDev(_ context.Context, options *DevBaseOptions) error {
 	return nil
 }
 
+// loadKitfile reads the Kitfile used by the development server. If its model refers to another modelkit,
+// it is merged with the referenced modelkits as when packing, so that their model path, parts, and
+// parameters are used.
+func loadKitfile(ctx context.Context, options *DevStartOptions) (*artifact.KitFile, error) {
+	kitfileContent, err := os.ReadFile(options.modelFile)
+	if err != nil {
+		return nil, err
+	}
+	kitfile, _, err := kfutils.LoadKitfile(kitfileContent, kfutils.LoadOptions{
+		ContextDir:       options.contextDir,
+		Variables:        options.variables,
+		ExpandParameters: options.ExpandParameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	output.Infof("Loaded Kitfile: %s", options.modelFile)
+
+	// Use the digests pinned by pack, if the Kitfile has been packed before
+	lock, err := kfutils.ReadLockFile(kfutils.LockFilePath(options.modelFile, options.contextDir))
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := kfutils.ResolveModelReferences(ctx, options.configHome, kitfile, "", lock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve referenced modelkit %s: %w", kitfile.Model.Path, err)
+	}
+	return resolved, nil
+}
+
 func findModelFile(absPath string) (string, error) {
 	stat, err := os.Lstat(absPath)
 	if err != nil {
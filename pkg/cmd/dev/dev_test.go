@@ -0,0 +1,142 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dev
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKitfileModelReference(t *testing.T) {
+	tmpDir := t.TempDir()
+	configHome := filepath.Join(tmpDir, "config")
+	baseDir := filepath.Join(tmpDir, "base")
+	contextDir := filepath.Join(tmpDir, "context")
+
+	// Pack the referenced modelkit into local storage
+	for _, file := range []string{"model/model.gguf", "model/tokenizer.json"} {
+		writeTestFile(t, filepath.Join(baseDir, file))
+	}
+	baseKitfile := loadTestKitfile(t, `
+manifestVersion: 1.0.0
+model:
+  path: model
+  parts:
+    - path: model/tokenizer.json
+  parameters:
+    temperature: 0.5
+    top_p: 0.9
+`)
+	saveTestModelKit(t, configHome, baseDir, baseKitfile, "base:v1")
+
+	kitfilePath := filepath.Join(contextDir, constants.DefaultKitfileName)
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	kitfileContent := `
+manifestVersion: 1.0.0
+model:
+  path: base:v1
+  parts:
+    - path: model/tokenizer.json
+  parameters:
+    temperature: 0.7
+`
+	if err := os.WriteFile(kitfilePath, []byte(kitfileContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kitfile, err := loadKitfile(context.Background(), &DevStartOptions{
+		DevBaseOptions: DevBaseOptions{configHome: configHome},
+		modelFile:      kitfilePath,
+		contextDir:     contextDir,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "model", kitfile.Model.Path)
+	assert.Equal(t, []artifact.ModelPart{{Path: "model/tokenizer.json"}}, kitfile.Model.Parts, "Parts should not be duplicated")
+	assert.Equal(t, map[string]any{"temperature": 0.7, "top_p": 0.9}, kitfile.Model.Parameters, "Parameters should be merged")
+}
+
+func loadTestKitfile(t *testing.T, content string) *artifact.KitFile {
+	kitfile := &artifact.KitFile{}
+	if err := kitfile.LoadModel(io.NopCloser(bytes.NewReader([]byte(content)))); err != nil {
+		t.Fatal(err)
+	}
+	return kitfile
+}
+
+func writeTestFile(t *testing.T, path string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// saveTestModelKit packs kitfile from contextDir into local storage in configHome and tags it with ref.
+func saveTestModelKit(t *testing.T, configHome, contextDir string, kitfile *artifact.KitFile, ref string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Layers are packed relative to the working directory
+	if err := os.Chdir(contextDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	modelRef, _, err := util.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localRepo, err := local.NewLocalRepo(constants.StoragePath(configHome), modelRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignore, err := filesystem.NewIgnoreFromContext(contextDir, kitfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc, err := kfutils.SaveModel(ctx, localRepo, kitfile, ignore, kfutils.SaveModelOptions{Compression: constants.NoneCompression})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := localRepo.Tag(ctx, *desc, modelRef.Reference); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 )
 
 type DevBaseOptions struct {
@@ -44,10 +46,12 @@ func (opts *DevBaseOptions) complete(ctx context.Context, _ []string) error {
 
 type DevStartOptions struct {
 	DevBaseOptions
+	options.KitfileTemplateOptions
 	host       string
 	port       int
 	modelFile  string
 	contextDir string
+	variables  map[string]string
 }
 
 func (opts *DevStartOptions) complete(ctx context.Context, args []string) error {
@@ -66,6 +70,11 @@ func (opts *DevStartOptions) complete(ctx context.Context, args []string) error
 		}
 		opts.modelFile = foundKitfile
 	}
+	variables, err := kfutils.ParseVariables(opts.ValuesFile, opts.SetVariables)
+	if err != nil {
+		return err
+	}
+	opts.variables = variables
 	if opts.host == "" {
 		opts.host = "127.0.0.1"
 	}
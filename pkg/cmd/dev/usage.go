@@ -25,7 +25,8 @@ const (
 	devStartLongDesc  = `Start development server (experimental) from a modelkit
 
 Start a development server for an unpacked modelkit, using a context directory
-that includes the model and a kitfile.`
+that includes the model and a kitfile. Variables in the kitfile are expanded as
+in 'kit pack', using the --set and --values flags and the environment.`
 
 	devStartExample = `# Serve the model located in the current directory
 kit dev start
@@ -45,6 +45,11 @@ ModelKits can be specified from either a local or from a remote registry.
 To specify a local ModelKit, prefix the reference with 'local://', e.g. 'local://jozu.ml/foo/bar'.
 To specify a remote ModelKit, prefix the reference with 'remote://', e.g. 'remote://jozu.ml/foo/bar'.
 If no prefix is specified, the local registry will be checked first.
+
+If datasets in the ModelKits were packed with 'kit pack --dataset-metadata', differences
+in the number of files, size, rows, and columns of datasets are shown as well.
+Differences in the model card and evaluation metrics recorded in the Kitfiles are
+also shown.
 `
 	examples = `# Compare two ModelKits
 kit diff jozu.ml/foo:latest jozu.ml/bar:latest
@@ -141,6 +146,28 @@ func runCommand(opts *diffOptions) func(cmd *cobra.Command, args []string) error
 		} else {
 			output.Infof("  Annotations does not match\n\n")
 		}
+		if datasetDifferences := CompareDatasets(diffA.Kitfile, diffB.Kitfile); len(datasetDifferences) > 0 {
+			output.Infoln("Datasets:")
+			output.Infoln("---------------------------------------")
+			for _, difference := range datasetDifferences {
+				output.Infof("  %s\n", difference)
+			}
+			output.Infoln("")
+		}
+		if modelCardDifferences := CompareModelCards(diffA.Kitfile, diffB.Kitfile); len(modelCardDifferences) > 0 {
+			output.Infoln("Model Card and Metrics:")
+			output.Infoln("---------------------------------------")
+			for _, difference := range modelCardDifferences {
+				output.Infof("  %s\n", difference)
+			}
+			output.Infoln("")
+		}
+		if !result.SameArtifactType {
+			output.Infoln("Artifact Types:")
+			output.Infoln("---------------------------------------")
+			output.Infof("  ModelKit1 Artifact Type: %s\n", formatArtifactType(diffA.Manifest.ArtifactType))
+			output.Infof("  ModelKit2 Artifact Type: %s\n\n", formatArtifactType(diffB.Manifest.ArtifactType))
+		}
 
 		displayLayers("Shared Layers", result.SharedLayers)
 		displayLayers(fmt.Sprintf("Unique Layers to ModelKit1 (%s)", opts.refA.String()), result.UniqueLayersA)
@@ -149,6 +176,13 @@ func runCommand(opts *diffOptions) func(cmd *cobra.Command, args []string) error
 	}
 }
 
+func formatArtifactType(artifactType string) string {
+	if artifactType == "" {
+		return "<none>"
+	}
+	return artifactType
+}
+
 func (opts *diffOptions) complete(ctx context.Context, args []string) error {
 
 	configHome, ok := ctx.Value(constants.ConfigKey{}).(string)
@@ -189,10 +223,14 @@ func displayLayers(title string, layers []ocispec.Descriptor) {
 	if len(layers) > 0 {
 		output.Infof(layerTableHeadings)
 		for _, layer := range layers {
+			size := output.FormatBytes(layer.Size)
+			if numShards := shardCount(layer); numShards > 1 {
+				size = fmt.Sprintf("%s (%d layers)", size, numShards)
+			}
 			output.Infof(layerTableFormat,
 				constants.FormatMediaTypeForUser(layer.MediaType),
 				layer.Digest[:17],
-				output.FormatBytes(layer.Size))
+				size)
 		}
 	} else {
 		output.Infoln("<none>")
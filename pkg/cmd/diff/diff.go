@@ -19,27 +19,33 @@ package diff
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry"
 
+	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
 )
 
-// Helper struct diffInfo holds the manifest and its descriptor for a ModelKit.
+// Helper struct diffInfo holds the manifest, its descriptor, and the Kitfile for a ModelKit.
 type diffInfo struct {
 	Manifest   *ocispec.Manifest
 	Descriptor ocispec.Descriptor
+	Kitfile    *artifact.KitFile
 }
 
 // Helper struct DiffResult contains the comparison results between two ModelKits.
 type DiffResult struct {
 	SameConfig       bool
+	SameArtifactType bool
 	AnnotationsMatch bool
 	SharedLayers     []ocispec.Descriptor
 	UniqueLayersA    []ocispec.Descriptor
@@ -52,6 +58,8 @@ func CompareManifests(manifestA *ocispec.Manifest, manifestB *ocispec.Manifest)
 
 	// Compare the config digests
 	result.SameConfig = manifestA.Config.Digest == manifestB.Config.Digest
+	// Modelkits may be stored with or without an artifactType (see util.IsModelKitManifest)
+	result.SameArtifactType = manifestA.ArtifactType == manifestB.ArtifactType
 
 	// Compare the annotations
 	numAnnotations := len(manifestA.Annotations)
@@ -71,17 +79,18 @@ func CompareManifests(manifestA *ocispec.Manifest, manifestB *ocispec.Manifest)
 		}
 	}
 
+	layersA, layersB := groupShards(manifestA.Layers), groupShards(manifestB.Layers)
 	layerMapA := make(map[string]ocispec.Descriptor)
-	for _, layer := range manifestA.Layers {
-		layerMapA[layer.Digest.String()] = layer
+	for _, layer := range layersA {
+		layerMapA[layer.key] = layer.desc
 	}
 
-	for _, layer := range manifestB.Layers {
-		if _, ok := layerMapA[layer.Digest.String()]; ok {
-			result.SharedLayers = append(result.SharedLayers, layer)
-			delete(layerMapA, layer.Digest.String())
+	for _, layer := range layersB {
+		if _, ok := layerMapA[layer.key]; ok {
+			result.SharedLayers = append(result.SharedLayers, layer.desc)
+			delete(layerMapA, layer.key)
 		} else {
-			result.UniqueLayersB = append(result.UniqueLayersB, layer)
+			result.UniqueLayersB = append(result.UniqueLayersB, layer.desc)
 		}
 	}
 
@@ -104,6 +113,246 @@ func CompareManifests(manifestA *ocispec.Manifest, manifestB *ocispec.Manifest)
 	return result
 }
 
+// CompareDatasets compares the metadata of datasets in two Kitfiles, returning a description of each
+// difference. Datasets are matched by name, or by path for datasets without a name. Datasets without
+// metadata (see 'kit pack --dataset-metadata') in either Kitfile are not compared.
+func CompareDatasets(kitfileA, kitfileB *artifact.KitFile) []string {
+	datasetKey := func(dataset artifact.DataSet) string {
+		if dataset.Name != "" {
+			return dataset.Name
+		}
+		return dataset.Path
+	}
+	datasetsB := map[string]artifact.DataSet{}
+	for _, dataset := range kitfileB.DataSets {
+		datasetsB[datasetKey(dataset)] = dataset
+	}
+
+	var differences []string
+	seen := map[string]bool{}
+	for _, datasetA := range kitfileA.DataSets {
+		key := datasetKey(datasetA)
+		seen[key] = true
+		datasetB, ok := datasetsB[key]
+		if !ok {
+			if datasetA.Metadata != nil {
+				differences = append(differences, fmt.Sprintf("%s: only in ModelKit1", key))
+			}
+			continue
+		}
+		differences = append(differences, compareDatasetMetadata(key, datasetA.Metadata, datasetB.Metadata)...)
+	}
+	for _, datasetB := range kitfileB.DataSets {
+		if key := datasetKey(datasetB); !seen[key] && datasetB.Metadata != nil {
+			differences = append(differences, fmt.Sprintf("%s: only in ModelKit2", key))
+		}
+	}
+	return differences
+}
+
+func compareDatasetMetadata(name string, metadataA, metadataB *artifact.DatasetMetadata) []string {
+	switch {
+	case metadataA == nil && metadataB == nil:
+		return nil
+	case metadataA == nil:
+		return []string{fmt.Sprintf("%s: metadata only in ModelKit2", name)}
+	case metadataB == nil:
+		return []string{fmt.Sprintf("%s: metadata only in ModelKit1", name)}
+	}
+
+	var differences []string
+	if metadataA.Files != metadataB.Files {
+		differences = append(differences, fmt.Sprintf("%s: files %d -> %d", name, metadataA.Files, metadataB.Files))
+	}
+	if metadataA.Size != metadataB.Size {
+		differences = append(differences, fmt.Sprintf("%s: size %s -> %s", name, output.FormatBytes(metadataA.Size), output.FormatBytes(metadataB.Size)))
+	}
+	formatsA, formatsB := summarizeTables(metadataA.Tables), summarizeTables(metadataB.Tables)
+	var formats []string
+	for format := range formatsA {
+		formats = append(formats, format)
+	}
+	for format := range formatsB {
+		if _, ok := formatsA[format]; !ok {
+			formats = append(formats, format)
+		}
+	}
+	sort.Strings(formats)
+	for _, format := range formats {
+		// Formats that are only in one dataset are compared against an empty summary
+		emptySummary := &tableSummary{columnTypes: map[string]string{}}
+		summaryA, summaryB := formatsA[format], formatsB[format]
+		if summaryA == nil {
+			summaryA = emptySummary
+		}
+		if summaryB == nil {
+			summaryB = emptySummary
+		}
+		if summaryA.rows != summaryB.rows {
+			differences = append(differences, fmt.Sprintf("%s: %s rows %d -> %d", name, format, summaryA.rows, summaryB.rows))
+		}
+		for _, column := range summaryA.columns {
+			typeB, ok := summaryB.columnTypes[column]
+			if !ok {
+				differences = append(differences, fmt.Sprintf("%s: %s column %s removed", name, format, column))
+			} else if typeA := summaryA.columnTypes[column]; typeA != typeB {
+				differences = append(differences, fmt.Sprintf("%s: %s column %s changed from %s to %s", name, format, column, typeA, typeB))
+			}
+		}
+		for _, column := range summaryB.columns {
+			if _, ok := summaryA.columnTypes[column]; !ok {
+				differences = append(differences, fmt.Sprintf("%s: %s column %s added (%s)", name, format, column, summaryB.columnTypes[column]))
+			}
+		}
+	}
+	return differences
+}
+
+// tableSummary combines the tables of one format in a dataset's metadata
+type tableSummary struct {
+	rows        int64
+	columns     []string
+	columnTypes map[string]string
+}
+
+func summarizeTables(tables []artifact.DatasetTable) map[string]*tableSummary {
+	summaries := map[string]*tableSummary{}
+	for _, table := range tables {
+		summary, ok := summaries[table.Format]
+		if !ok {
+			summary = &tableSummary{columnTypes: map[string]string{}}
+			summaries[table.Format] = summary
+		}
+		summary.rows += table.Rows
+		for _, column := range table.Columns {
+			existing, ok := summary.columnTypes[column.Name]
+			if !ok {
+				summary.columns = append(summary.columns, column.Name)
+				summary.columnTypes[column.Name] = column.Type
+			} else if existing != column.Type {
+				summary.columnTypes[column.Name] = "mixed"
+			}
+		}
+	}
+	return summaries
+}
+
+// CompareModelCards compares the model cards and metrics in two Kitfiles, returning a description of each
+// difference. Metrics are matched by name, dataset, and split.
+func CompareModelCards(kitfileA, kitfileB *artifact.KitFile) []string {
+	cardA, cardB := kitfileA.ModelCard, kitfileB.ModelCard
+	if cardA == nil {
+		cardA = &artifact.ModelCard{}
+	}
+	if cardB == nil {
+		cardB = &artifact.ModelCard{}
+	}
+
+	var differences []string
+	if cardA.IntendedUse != cardB.IntendedUse {
+		differences = append(differences, "intended use differs")
+	}
+	if cardA.Limitations != cardB.Limitations {
+		differences = append(differences, "limitations differ")
+	}
+	for _, data := range cardA.TrainingData {
+		if !slices.Contains(cardB.TrainingData, data) {
+			differences = append(differences, fmt.Sprintf("training data %s: only in ModelKit1", data))
+		}
+	}
+	for _, data := range cardB.TrainingData {
+		if !slices.Contains(cardA.TrainingData, data) {
+			differences = append(differences, fmt.Sprintf("training data %s: only in ModelKit2", data))
+		}
+	}
+
+	metricName := func(metric artifact.Metric) string {
+		var on []string
+		for _, s := range []string{metric.Dataset, metric.Split} {
+			if s != "" {
+				on = append(on, s)
+			}
+		}
+		if len(on) == 0 {
+			return metric.Name
+		}
+		return fmt.Sprintf("%s (%s)", metric.Name, strings.Join(on, "/"))
+	}
+	metricsB := map[string]artifact.Metric{}
+	for _, metric := range kitfileB.Metrics {
+		metricsB[metricName(metric)] = metric
+	}
+	seen := map[string]bool{}
+	for _, metricA := range kitfileA.Metrics {
+		name := metricName(metricA)
+		seen[name] = true
+		metricB, ok := metricsB[name]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("metric %s: only in ModelKit1", name))
+		} else if metricA.Value != metricB.Value {
+			differences = append(differences, fmt.Sprintf("metric %s: %s -> %s", name, formatMetricValue(metricA.Value), formatMetricValue(metricB.Value)))
+		}
+	}
+	for _, metricB := range kitfileB.Metrics {
+		if name := metricName(metricB); !seen[name] {
+			differences = append(differences, fmt.Sprintf("metric %s: only in ModelKit2", name))
+		}
+	}
+	return differences
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// logicalLayer is a layer, or a group of layers that store a single path split into multiple layers
+type logicalLayer struct {
+	// key identifies the layer (or group of layers) for comparison
+	key string
+	// desc describes the layer. For groups of layers, it uses the first layer's digest and
+	// the total size of all layers in the group
+	desc ocispec.Descriptor
+}
+
+// groupShards combines layers that are shards of a single path (as indicated by the
+// constants.LayerShardAnnotation annotation) into one logical layer, so that they are
+// compared as a single entry.
+func groupShards(layers []ocispec.Descriptor) []logicalLayer {
+	var result []logicalLayer
+	for idx := 0; idx < len(layers); idx++ {
+		layer := layers[idx]
+		numShards := shardCount(layer)
+		if numShards <= 1 || idx+numShards > len(layers) {
+			result = append(result, logicalLayer{key: layer.Digest.String(), desc: layer})
+			continue
+		}
+		group := logicalLayer{desc: layer}
+		digests := []string{layer.Digest.String()}
+		for _, shard := range layers[idx+1 : idx+numShards] {
+			digests = append(digests, shard.Digest.String())
+			group.desc.Size += shard.Size
+		}
+		group.key = strings.Join(digests, ",")
+		result = append(result, group)
+		idx += numShards - 1
+	}
+	return result
+}
+
+// shardCount returns the total number of shards if layer is the first shard of a path that was split
+// into multiple layers, and zero otherwise.
+func shardCount(layer ocispec.Descriptor) int {
+	shard, ok := layer.Annotations[constants.LayerShardAnnotation]
+	if !ok {
+		return 0
+	}
+	var index, total int
+	if _, err := fmt.Sscanf(shard, "%d/%d", &index, &total); err != nil || index != 1 {
+		return 0
+	}
+	return total
+}
+
 func getManifest(ctx context.Context, arg string, ref *registry.Reference, opts *diffOptions) (*diffInfo, error) {
 	if strings.HasPrefix(arg, remotePrefix) {
 		return getManifestFromRemote(ctx, ref, opts)
@@ -123,13 +372,14 @@ func getManifestFromRemote(ctx context.Context, ref *registry.Reference, opts *d
 	if err != nil {
 		return nil, err
 	}
-	desc, manifest, err := util.ResolveManifest(ctx, repository, ref.Reference)
+	desc, manifest, kitfile, err := util.ResolveManifestAndConfig(ctx, repository, ref.Reference)
 	if err != nil {
 		return nil, err
 	}
 	return &diffInfo{
 		Manifest:   manifest,
 		Descriptor: desc,
+		Kitfile:    kitfile,
 	}, nil
 }
 
@@ -139,12 +389,13 @@ func getManifestFromLocal(ctx context.Context, ref *registry.Reference, opts *di
 	if err != nil {
 		return nil, fmt.Errorf("failed to read local storage: %w", err)
 	}
-	desc, manifest, err := util.ResolveManifest(ctx, localRepo, ref.Reference)
+	desc, manifest, kitfile, err := util.ResolveManifestAndConfig(ctx, localRepo, ref.Reference)
 	if err != nil {
 		return nil, err
 	}
 	return &diffInfo{
 		Manifest:   manifest,
 		Descriptor: desc,
+		Kitfile:    kitfile,
 	}, nil
 }
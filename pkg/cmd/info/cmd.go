@@ -46,7 +46,18 @@ const (
 
 By default, kit will check local storage for the specified modelkit. To see
 the configuration for a modelkit stored on a remote registry, use the
---remote flag.`
+--remote flag.
+
+If the modelkit's model refers to another modelkit, use the --resolved flag to
+print the effective Kitfile, which combines the Kitfile with the Kitfiles of the
+referenced modelkits. Metadata in the modelkit takes precedence over metadata in
+referenced modelkits, model parameters are merged key by key, and lists such as
+authors, model parts, datasets, code, and docs are combined. Referenced modelkits
+are found in local storage or the remote registry, using the digests pinned when
+the modelkit was packed.
+
+Use the --modelcard flag to print the model card and evaluation metrics recorded
+in the Kitfile as a markdown document instead.`
 	example = `# See configuration for a local modelkit:
 kit info mymodel:mytag
 
@@ -54,7 +65,13 @@ kit info mymodel:mytag
 kit info mymodel@sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a
 
 # See configuration for a remote modelkit:
-kit info --remote registry.example.com/my-model:1.0.0`
+kit info --remote registry.example.com/my-model:1.0.0
+
+# See the effective configuration for a modelkit whose model refers to another modelkit:
+kit info --resolved mymodel:fine-tuned
+
+# See the model card and evaluation metrics for a modelkit:
+kit info --modelcard mymodel:mytag`
 )
 
 // Currently supported filter syntax: alphanumeric (plus dashes and underscores), dot-delimited fields
@@ -66,6 +83,8 @@ type infoOptions struct {
 	checkRemote bool
 	modelRef    *registry.Reference
 	filter      string
+	resolved    bool
+	modelCard   bool
 }
 
 func InfoCommand() *cobra.Command {
@@ -83,6 +102,8 @@ func InfoCommand() *cobra.Command {
 	opts.AddNetworkFlags(cmd)
 	cmd.Flags().BoolVarP(&opts.checkRemote, "remote", "r", false, "Check remote registry instead of local storage")
 	cmd.Flags().StringVarP(&opts.filter, "filter", "f", "", "filter with node selectors")
+	cmd.Flags().BoolVar(&opts.resolved, "resolved", false, "Include metadata and contents from modelkits referenced by the model")
+	cmd.Flags().BoolVar(&opts.modelCard, "modelcard", false, "Print the model card and metrics as markdown")
 	cmd.Flags().SortFlags = false
 
 	return cmd
@@ -101,7 +122,13 @@ func runCommand(opts *infoOptions) func(*cobra.Command, []string) error {
 			return output.Fatalf("Error resolving modelkit: %s", err)
 		}
 
-		if len(opts.filter) > 0 {
+		if opts.modelCard {
+			modelCard, err := formatModelCard(config)
+			if err != nil {
+				return output.Fatalln(err)
+			}
+			fmt.Println(modelCard)
+		} else if len(opts.filter) > 0 {
 			filteredOutput, err := filterKitfile(config, opts.filter)
 			if err != nil {
 				return output.Fatalln(err)
@@ -135,6 +162,10 @@ func (opts *infoOptions) complete(ctx context.Context, args []string) error {
 	}
 	opts.modelRef = ref
 
+	if opts.modelCard && opts.filter != "" {
+		return fmt.Errorf("--modelcard cannot be used with --filter")
+	}
+
 	if opts.modelRef.Registry == util.DefaultRegistry && opts.checkRemote {
 		return fmt.Errorf("can not check remote: %s does not contain registry", util.FormatRepositoryForDisplay(opts.modelRef.String()))
 	}
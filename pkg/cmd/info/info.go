@@ -22,40 +22,61 @@ import (
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func getInfo(ctx context.Context, opts *infoOptions) (*artifact.KitFile, error) {
+	var manifest *ocispec.Manifest
+	var config *artifact.KitFile
+	var err error
 	if opts.checkRemote {
-		return getRemoteConfig(ctx, opts)
+		manifest, config, err = getRemoteConfig(ctx, opts)
 	} else {
-		return getLocalConfig(ctx, opts)
+		manifest, config, err = getLocalConfig(ctx, opts)
+	}
+	if err != nil || !opts.resolved {
+		return config, err
+	}
+
+	lock, err := kfutils.LockFromManifest(manifest)
+	if err != nil {
+		return nil, err
 	}
+	baseRef := util.FormatRepositoryForDisplay(opts.modelRef.String())
+	resolved, err := kfutils.ResolveModelReferences(ctx, opts.configHome, config, baseRef, lock)
+	if err != nil {
+		// Not wrapped, as errors for referenced modelkits should not be reported as the modelkit not being found
+		return nil, fmt.Errorf("failed to resolve referenced modelkits: %s", err)
+	}
+	return resolved, nil
 }
 
-func getLocalConfig(ctx context.Context, opts *infoOptions) (*artifact.KitFile, error) {
+func getLocalConfig(ctx context.Context, opts *infoOptions) (*ocispec.Manifest, *artifact.KitFile, error) {
 	storageRoot := constants.StoragePath(opts.configHome)
 	localRepo, err := local.NewLocalRepo(storageRoot, opts.modelRef)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read local storage: %w", err)
+		return nil, nil, fmt.Errorf("failed to read local storage: %w", err)
 	}
-	_, _, config, err := util.ResolveManifestAndConfig(ctx, localRepo, opts.modelRef.Reference)
+	_, manifest, config, err := util.ResolveManifestAndConfig(ctx, localRepo, opts.modelRef.Reference)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return config, nil
+	return manifest, config, nil
 }
 
-func getRemoteConfig(ctx context.Context, opts *infoOptions) (*artifact.KitFile, error) {
+func getRemoteConfig(ctx context.Context, opts *infoOptions) (*ocispec.Manifest, *artifact.KitFile, error) {
 	repository, err := remote.NewRepository(ctx, opts.modelRef.Registry, opts.modelRef.Repository, &opts.NetworkOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	_, _, config, err := util.ResolveManifestAndConfig(ctx, repository, opts.modelRef.Reference)
+	_, manifest, config, err := util.ResolveManifestAndConfig(ctx, repository, opts.modelRef.Reference)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return config, nil
+	return manifest, config, nil
 }
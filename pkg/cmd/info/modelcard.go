@@ -0,0 +1,84 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package info
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// formatModelCard renders the model card and metrics in a Kitfile as a markdown document. An error is
+// returned if the Kitfile has neither.
+func formatModelCard(kitfile *artifact.KitFile) (string, error) {
+	if kitfile.ModelCard == nil && len(kitfile.Metrics) == 0 {
+		return "", fmt.Errorf("modelkit does not include a model card or metrics")
+	}
+	card := kitfile.ModelCard
+	if card == nil {
+		card = &artifact.ModelCard{}
+	}
+
+	sb := &strings.Builder{}
+	title := kitfile.Package.Name
+	if kitfile.Model != nil && kitfile.Model.Name != "" {
+		title = kitfile.Model.Name
+	}
+	if title != "" {
+		fmt.Fprintf(sb, "# %s\n\n", title)
+	}
+	if kitfile.Package.Description != "" {
+		fmt.Fprintf(sb, "%s\n\n", kitfile.Package.Description)
+	}
+	if card.IntendedUse != "" {
+		fmt.Fprintf(sb, "## Intended use\n\n%s\n\n", strings.TrimSpace(card.IntendedUse))
+	}
+	if card.Limitations != "" {
+		fmt.Fprintf(sb, "## Limitations\n\n%s\n\n", strings.TrimSpace(card.Limitations))
+	}
+	if len(card.TrainingData) > 0 {
+		fmt.Fprintf(sb, "## Training data\n\n")
+		for _, data := range card.TrainingData {
+			fmt.Fprintf(sb, "- %s%s\n", data, describeDataset(kitfile, data))
+		}
+		fmt.Fprintf(sb, "\n")
+	}
+	if len(kitfile.Metrics) > 0 {
+		fmt.Fprintf(sb, "## Evaluation results\n\n")
+		fmt.Fprintf(sb, "| Metric | Value | Dataset | Split |\n")
+		fmt.Fprintf(sb, "|--------|-------|---------|-------|\n")
+		for _, metric := range kitfile.Metrics {
+			value := strconv.FormatFloat(metric.Value, 'g', -1, 64)
+			fmt.Fprintf(sb, "| %s | %s | %s | %s |\n", metric.Name, value, metric.Dataset, metric.Split)
+		}
+		fmt.Fprintf(sb, "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// describeDataset returns a note describing where to find the named dataset if it is included in the
+// modelkit, or an empty string otherwise.
+func describeDataset(kitfile *artifact.KitFile, name string) string {
+	idx := slices.IndexFunc(kitfile.DataSets, func(d artifact.DataSet) bool { return d.Name == name })
+	if idx == -1 {
+		return ""
+	}
+	return fmt.Sprintf(" (included in this modelkit at %s)", kitfile.DataSets[idx].Path)
+}
@@ -37,6 +37,9 @@ func CacheCommand() *cobra.Command {
 Normally, this directory is empty, but may contain leftover files from resumable
 downloads or files that were not cleaned up due to the command being cancelled.
 
+Layers extracted by 'kit unpack --link-from-cache' are stored separately in
+$KITOPS_HOME/unpacked so that they can be shared between unpacked modelkits.
+
 The $KITOPS_HOME location is system dependent:
 	- Linux: $XDG_DATA_HOME/kitops with a fall back to $HOME/.local/share/kitops
 	- MacOS: ~/Library/Caches/kitops
@@ -46,7 +49,10 @@ The $KITOPS_HOME location is system dependent:
 kit cache info
 
 # Clear files in cache
-kit cache clear`,
+kit cache clear
+
+# Clear files in cache, including layers shared by 'kit unpack --link-from-cache'
+kit cache clear --unpacked`,
 	}
 	cmd.AddCommand(cacheInfoCommand())
 	cmd.AddCommand(cacheClearCommand())
@@ -58,17 +64,26 @@ func cacheInfoCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "info",
 		Short: `Get information about cache disk usage`,
-		Long:  `Print the total size of temporary files in the cache directory.`,
+		Long:  `Print the total size of temporary files in the cache directory and of layers in the unpacked layer cache.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			totalSize, stats, err := fscache.StatCache()
 			if err != nil {
 				return output.Fatalln(err)
 			}
-			if totalSize == 0 {
+			numLayers, unpackedSize, err := fscache.StatUnpackedCache()
+			if err != nil {
+				return output.Fatalln(err)
+			}
+			if totalSize == 0 && numLayers == 0 {
 				output.Infof("Cache is currently empty")
 				return nil
 			}
-			printCacheInfo(cmd.OutOrStdout(), totalSize, stats)
+			if totalSize > 0 {
+				printCacheInfo(cmd.OutOrStdout(), totalSize, stats)
+			}
+			if numLayers > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Unpacked layer cache: %d layers, %s\n", numLayers, output.FormatBytes(unpackedSize))
+			}
 			return nil
 		},
 	}
@@ -76,17 +91,28 @@ func cacheInfoCommand() *cobra.Command {
 }
 
 func cacheClearCommand() *cobra.Command {
+	var clearUnpacked bool
 	cmd := &cobra.Command{
 		Use:   "clear",
 		Short: `Clear temporary cache storage`,
-		Long:  `Clear temporary files from cache storage.`,
+		Long: `Clear temporary files from cache storage.
+
+If --unpacked is specified, layers shared by 'kit unpack --link-from-cache' are
+removed as well. Files that were unpacked as hard links or copies are not affected,
+but files that were unpacked as symlinks to the cache will no longer resolve.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := fscache.ClearCache(); err != nil {
 				return output.Fatalln(err)
 			}
+			if clearUnpacked {
+				if err := fscache.ClearUnpackedCache(); err != nil {
+					return output.Fatalln(err)
+				}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&clearUnpacked, "unpacked", false, "Also clear layers shared by unpacked modelkits")
 	return cmd
 }
 
@@ -23,8 +23,10 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/git"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	repoutils "github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
@@ -39,6 +41,8 @@ const (
 The repository can be specified either via a repository (e.g. myorg/myrepo) or
 with a full URL (https://huggingface.co/myorg/myrepo). The repository will be
 downloaded to a temporary directory and be packaged using a generated Kitfile.
+If the repository has a README.md model card, the model card and evaluation
+results in it are added to the generated Kitfile.
 
 In interactive settings, this command will read the EDITOR environment variable
 to determine which editor should be used for editing the Kitfile.
@@ -54,7 +58,10 @@ options below:
 	             repository but requires that Git and Git LFS are installed.
 
 By default, Kit will automatically select the tool based on the provided
-REPOSITORY.`
+REPOSITORY.
+
+When an existing Kitfile is used, variables in it are expanded as in 'kit pack',
+using the --set and --values flags and the environment.`
 
 	example = `# Download repository myorg/myrepo and package it, using the default tag (myorg/myrepo:latest)
 kit import myorg/myrepo
@@ -70,6 +77,7 @@ kit import myorg/myrepo --file ./path/to/Kitfile`
 )
 
 type importOptions struct {
+	options.KitfileTemplateOptions
 	configHome   string
 	repo         string
 	repoRef      string
@@ -79,6 +87,7 @@ type importOptions struct {
 	downloadTool string
 	concurrency  int
 	modelKitRef  *registry.Reference
+	variables    map[string]string
 }
 
 func ImportCommand() *cobra.Command {
@@ -99,6 +108,7 @@ func ImportCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&opts.kitfilePath, "file", "f", "", "Path to Kitfile to use for packing (use '-' to read from standard input)")
 	cmd.Flags().StringVar(&opts.downloadTool, "tool", "", "Tool to use for downloading files: options are 'git' and 'hf' (default: detect based on repository)")
 	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 5, "Maximum number of simultaneous downloads (for huggingface)")
+	opts.AddTemplateFlags(cmd)
 	cmd.Flags().SortFlags = false
 	return cmd
 }
@@ -154,6 +164,12 @@ func (opts *importOptions) complete(ctx context.Context, args []string) error {
 	if opts.concurrency < 1 {
 		return fmt.Errorf("invalid argument for concurrency (%d): must be at least 1", opts.concurrency)
 	}
+
+	variables, err := kfutils.ParseVariables(opts.ValuesFile, opts.SetVariables)
+	if err != nil {
+		return err
+	}
+	opts.variables = variables
 	return nil
 }
 
@@ -20,7 +20,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -29,7 +28,6 @@ import (
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem/cache"
 	"github.com/kitops-ml/kitops/pkg/lib/git"
-	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	kfgen "github.com/kitops-ml/kitops/pkg/lib/kitfile/generate"
 	"github.com/kitops-ml/kitops/pkg/lib/util"
 	"github.com/kitops-ml/kitops/pkg/output"
@@ -53,21 +51,19 @@ func importUsingGit(ctx context.Context, opts *importOptions) error {
 
 	var kitfile *artifact.KitFile
 	if opts.kitfilePath == "-" {
-		kitfile = &artifact.KitFile{}
-		if err := kitfile.LoadModel(os.Stdin); err != nil {
-			return fmt.Errorf("failed to read Kitfile from input: %w", err)
-		}
-		if err := kfutils.ValidateKitfile(kitfile); err != nil {
+		kf, err := readKitfileFromInput(tmpDir, opts)
+		if err != nil {
 			return err
 		}
+		kitfile = kf
 	} else if opts.kitfilePath != "" {
-		kf, err := readExistingKitfile(opts.kitfilePath)
+		kf, err := readExistingKitfile(opts.kitfilePath, tmpDir, opts)
 		if err != nil {
 			return err
 		}
 		kitfile = kf
 	} else if kfpath, err := filesystem.FindKitfileInPath(tmpDir); err == nil {
-		kf, err := readExistingKitfile(kfpath)
+		kf, err := readExistingKitfile(kfpath, tmpDir, opts)
 		if err != nil {
 			return err
 		}
@@ -86,7 +82,7 @@ func importUsingGit(ctx context.Context, opts *importOptions) error {
 		if util.IsInteractiveSession() {
 			// If we hit an error here, we don't want to clean up files so that user
 			// can manually edit them.
-			newKitfile, err := promptToEditKitfile(tmpDir, kf)
+			newKitfile, err := promptToEditKitfile(tmpDir, kf, opts)
 			if err != nil {
 				if errors.Is(err, ErrNoEditorFound) {
 					doCleanup = false
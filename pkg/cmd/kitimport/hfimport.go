@@ -20,15 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem/cache"
 	"github.com/kitops-ml/kitops/pkg/lib/hf"
-	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	kfgen "github.com/kitops-ml/kitops/pkg/lib/kitfile/generate"
 	repoutil "github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/lib/util"
@@ -59,21 +58,28 @@ func importUsingHF(ctx context.Context, opts *importOptions) error {
 	}
 
 	var kitfile *artifact.KitFile
+	var downloaded []string
 	if opts.kitfilePath == "-" {
-		kitfile = &artifact.KitFile{}
-		if err := kitfile.LoadModel(os.Stdin); err != nil {
-			return fmt.Errorf("failed to read Kitfile from input: %w", err)
-		}
-		if err := kfutils.ValidateKitfile(kitfile); err != nil {
+		kf, err := readKitfileFromInput(tmpDir, opts)
+		if err != nil {
 			return err
 		}
+		kitfile = kf
 	} else if opts.kitfilePath != "" {
-		kf, err := readExistingKitfile(opts.kitfilePath)
+		kf, err := readExistingKitfile(opts.kitfilePath, tmpDir, opts)
 		if err != nil {
 			return err
 		}
 		kitfile = kf
 	} else {
+		// The model card is downloaded first so that it can be used to populate the generated Kitfile
+		readme := slices.IndexFunc(dirListing.Files, func(f kfgen.FileListing) bool { return f.Path == hf.ModelCardFile })
+		if readme != -1 {
+			if err := hf.DownloadFiles(ctx, repo, opts.repoRef, tmpDir, dirListing.Files[readme:readme+1], opts.token, 1); err != nil {
+				return fmt.Errorf("error downloading model card: %w", err)
+			}
+			downloaded = append(downloaded, hf.ModelCardFile)
+		}
 		kf, err := generateKitfile(dirListing, repo, tmpDir)
 		if err != nil {
 			return err
@@ -83,7 +89,7 @@ func importUsingHF(ctx context.Context, opts *importOptions) error {
 		if util.IsInteractiveSession() {
 			// If we hit an error here, we don't want to clean up files so that user
 			// can manually edit them.
-			newKitfile, err := promptToEditKitfile(tmpDir, kf)
+			newKitfile, err := promptToEditKitfile(tmpDir, kf, opts)
 			if err != nil {
 				if errors.Is(err, ErrNoEditorFound) {
 					doCleanup = false
@@ -106,6 +112,9 @@ func importUsingHF(ctx context.Context, opts *importOptions) error {
 	if err != nil {
 		return err
 	}
+	toDownload = slices.DeleteFunc(toDownload, func(f kfgen.FileListing) bool {
+		return slices.Contains(downloaded, f.Path)
+	})
 	if err := hf.DownloadFiles(ctx, repo, opts.repoRef, tmpDir, toDownload, opts.token, opts.concurrency); err != nil {
 		return fmt.Errorf("error downloading repository: %w", err)
 	}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"os/exec"
@@ -30,6 +32,7 @@ import (
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/lib/hf"
 	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	kfgen "github.com/kitops-ml/kitops/pkg/lib/kitfile/generate"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
@@ -55,6 +58,7 @@ func generateKitfile(dirContents *kfgen.DirectoryListing, repo string, outDir st
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate Kitfile: %w", err)
 	}
+	addModelCard(kitfile, outDir)
 	kitfileBytes, err := kitfile.MarshalToYAML()
 	if err != nil {
 		return nil, fmt.Errorf("failed to write Kitfile: %w", err)
@@ -67,22 +71,59 @@ func generateKitfile(dirContents *kfgen.DirectoryListing, repo string, outDir st
 	return kitfile, nil
 }
 
-func readExistingKitfile(kfPath string) (*artifact.KitFile, error) {
-	kfFile, err := os.Open(kfPath)
+// addModelCard populates the model card and metrics in kitfile from the README in outDir, if it exists.
+// Problems reading the README are logged, as the Kitfile is still usable without a model card.
+func addModelCard(kitfile *artifact.KitFile, outDir string) {
+	readme, err := os.ReadFile(filepath.Join(outDir, hf.ModelCardFile))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			output.Logf(output.LogLevelWarn, "Failed to read model card: %s", err)
+		}
+		return
+	}
+	modelCard, metrics, err := hf.ParseModelCard(readme)
+	if err != nil {
+		output.Logf(output.LogLevelWarn, "Failed to read model card from %s: %s", hf.ModelCardFile, err)
+		return
+	}
+	kitfile.ModelCard = modelCard
+	kitfile.Metrics = metrics
+}
+
+func readExistingKitfile(kfPath, contextDir string, opts *importOptions) (*artifact.KitFile, error) {
+	kfBytes, err := os.ReadFile(kfPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Kitfile: %w", err)
 	}
-	defer kfFile.Close()
-	kitfile := &artifact.KitFile{}
-	if err := kitfile.LoadModel(kfFile); err != nil {
+	kitfile, err := loadKitfile(kfBytes, contextDir, opts)
+	if err != nil {
 		return nil, fmt.Errorf("failed to load Kitfile from %s: %w", kfPath, err)
 	}
-	if err := kfutils.ValidateKitfile(kitfile); err != nil {
-		return nil, fmt.Errorf("kitfile (%s) is invalid: %w", kfPath, err)
+	return kitfile, nil
+}
+
+func readKitfileFromInput(contextDir string, opts *importOptions) (*artifact.KitFile, error) {
+	kfBytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Kitfile from input: %w", err)
+	}
+	kitfile, err := loadKitfile(kfBytes, contextDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Kitfile from input: %w", err)
 	}
 	return kitfile, nil
 }
 
+// loadKitfile loads a Kitfile for the repository downloaded to contextDir, using the variables set in opts.
+func loadKitfile(kfBytes []byte, contextDir string, opts *importOptions) (*artifact.KitFile, error) {
+	kitfile, _, err := kfutils.LoadKitfile(kfBytes, kfutils.LoadOptions{
+		ContextDir:       contextDir,
+		Variables:        opts.variables,
+		ExpandParameters: opts.ExpandParameters,
+	})
+	return kitfile, err
+}
+
 func packDirectory(ctx context.Context, configHome, contextDir string, kitfile *artifact.KitFile, ref *registry.Reference) error {
 	// Packing requires the working dir to be the context dir so that relative paths are correct in the tarball
 	// On Windows, we need to switch back to the current directory or removing the temporary directory will fail
@@ -107,7 +148,7 @@ func packDirectory(ctx context.Context, configHome, contextDir string, kitfile *
 	if err != nil {
 		return err
 	}
-	manifestDesc, err := kfutils.SaveModel(ctx, localRepo, kitfile, ignore, constants.NoneCompression)
+	manifestDesc, err := kfutils.SaveModel(ctx, localRepo, kitfile, ignore, kfutils.SaveModelOptions{Compression: constants.NoneCompression})
 	if err != nil {
 		return err
 	}
@@ -117,7 +158,7 @@ func packDirectory(ctx context.Context, configHome, contextDir string, kitfile *
 	return nil
 }
 
-func promptToEditKitfile(contextDir string, currentKitfile *artifact.KitFile) (*artifact.KitFile, error) {
+func promptToEditKitfile(contextDir string, currentKitfile *artifact.KitFile, opts *importOptions) (*artifact.KitFile, error) {
 	kitfilePath := filepath.Join(contextDir, constants.DefaultKitfileName)
 	ans, err := util.PromptForInput("Would you like to edit Kitfile before packing? (y/N): ", false)
 	if err != nil {
@@ -138,7 +179,7 @@ func promptToEditKitfile(contextDir string, currentKitfile *artifact.KitFile) (*
 	if err := editCmd.Run(); err != nil {
 		return nil, fmt.Errorf("error running external editor: %w", err)
 	}
-	return readExistingKitfile(kitfilePath)
+	return readExistingKitfile(kitfilePath, contextDir, opts)
 }
 
 func getEditorName() (string, error) {
@@ -18,6 +18,9 @@ package kitimport
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -58,3 +61,38 @@ func TestExtractRepoFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestReadExistingKitfileLocalPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File names cannot contain ':' on Windows")
+	}
+	contextDir := t.TempDir()
+	for _, dir := range []string{"model", "data/eval:v2"} {
+		if err := os.MkdirAll(filepath.Join(contextDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kitfilePath := filepath.Join(contextDir, "Kitfile")
+	kitfile := `
+manifestVersion: 1.0.0
+model:
+  path: model
+datasets:
+  - name: eval
+    path: data/eval:v2
+  - name: reference
+    path: data/other:v1
+`
+	if err := os.WriteFile(kitfilePath, []byte(kitfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kf, err := readExistingKitfile(kitfilePath, contextDir, &importOptions{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, kf.DataSets, 2) {
+		assert.Equal(t, "./data/eval:v2", kf.DataSets[0].Path, "Paths in the context directory should be local paths")
+		assert.Equal(t, "data/other:v1", kf.DataSets[1].Path, "Other paths should be left as references")
+	}
+}
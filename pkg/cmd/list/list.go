@@ -19,11 +19,15 @@ package list
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func listLocalKits(ctx context.Context, opts *listOptions) ([]modelInfo, error) {
@@ -48,16 +52,59 @@ func listLocalKits(ctx context.Context, opts *listOptions) ([]modelInfo, error)
 func readInfoFromRepo(ctx context.Context, repo local.LocalRepo) ([]modelInfo, error) {
 	var infos []modelInfo
 	manifestDescs := repo.GetAllModels()
-	for _, manifestDesc := range manifestDescs {
-		manifest, config, err := util.GetManifestAndConfig(ctx, repo, manifestDesc)
-		if err != nil && !errors.Is(err, util.ErrNotAModelKit) {
+	// Variants are listed under the index that groups them rather than as separate modelkits
+	variantDigests := map[digest.Digest]bool{}
+	indexes := map[digest.Digest]*ocispec.Index{}
+	for _, desc := range manifestDescs {
+		if desc.MediaType != ocispec.MediaTypeImageIndex {
+			continue
+		}
+		index, err := util.GetIndex(ctx, repo, desc)
+		if err != nil {
+			if errors.Is(err, util.ErrNotAModelKit) {
+				continue
+			}
 			return nil, err
 		}
+		indexes[desc.Digest] = index
+		for _, variantDesc := range index.Manifests {
+			variantDigests[variantDesc.Digest] = true
+		}
+	}
+
+	// Strip localhost from repo if present, since we added it
+	repository := util.FormatRepositoryForDisplay(repo.GetRepoName())
+	if repository == "" {
+		repository = "<none>"
+	}
+	for _, manifestDesc := range manifestDescs {
 		tags := repo.GetTags(manifestDesc)
-		// Strip localhost from repo if present, since we added it
-		repository := util.FormatRepositoryForDisplay(repo.GetRepoName())
-		if repository == "" {
-			repository = "<none>"
+		if index, ok := indexes[manifestDesc.Digest]; ok {
+			for _, variantDesc := range index.Manifests {
+				manifest, config, err := util.GetManifestAndConfig(ctx, repo, variantDesc)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read variant %s: %w", util.VariantName(variantDesc), err)
+				}
+				info := modelInfo{
+					Repo:    repository,
+					Digest:  string(variantDesc.Digest),
+					Tags:    tags,
+					Variant: util.VariantName(variantDesc),
+				}
+				info.fill(manifest, config)
+				infos = append(infos, info)
+			}
+			continue
+		}
+		if len(tags) == 0 && variantDigests[manifestDesc.Digest] {
+			continue
+		}
+		manifest, config, err := util.GetManifestAndConfig(ctx, repo, manifestDesc)
+		if err != nil {
+			if errors.Is(err, util.ErrNotAModelKit) || errors.Is(err, util.ErrVariantIndex) {
+				continue
+			}
+			return nil, err
 		}
 		info := modelInfo{
 			Repo:   repository,
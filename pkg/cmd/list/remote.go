@@ -21,10 +21,10 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry"
 )
 
@@ -39,11 +39,7 @@ func listRemoteKits(ctx context.Context, opts *listOptions) ([]modelInfo, error)
 		return nil, fmt.Errorf("failed to read repository: %w", err)
 	}
 	if opts.remoteRef.Reference != "" {
-		info, err := listImageTag(ctx, repo, opts.remoteRef)
-		if info == nil || err != nil {
-			return nil, err
-		}
-		return []modelInfo{*info}, nil
+		return listImageTag(ctx, repo, opts.remoteRef)
 	}
 	return listTags(ctx, repo, opts.remoteRef)
 }
@@ -65,36 +61,46 @@ func listTags(ctx context.Context, repo registry.Repository, ref *registry.Refer
 			Repository: ref.Repository,
 			Reference:  tag,
 		}
-		info, err := listImageTag(ctx, repo, tagRef)
+		infos, err := listImageTag(ctx, repo, tagRef)
 		if err != nil && !errors.Is(err, util.ErrNotAModelKit) {
 			return nil, err
 		}
-		if info != nil {
-			allInfos = append(allInfos, *info)
-		}
+		allInfos = append(allInfos, infos...)
 	}
 
 	return allInfos, nil
 }
 
-func listImageTag(ctx context.Context, repo registry.Repository, ref *registry.Reference) (*modelInfo, error) {
-	manifestDesc, err := repo.Resolve(ctx, ref.Reference)
+// listImageTag returns info for the modelkit tagged ref. If ref refers to an index of modelkit variants,
+// info is returned for each variant.
+func listImageTag(ctx context.Context, repo registry.Repository, ref *registry.Reference) ([]modelInfo, error) {
+	desc, err := repo.Resolve(ctx, ref.Reference)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve reference %s: %w", ref.Reference, err)
 	}
-	manifest, config, err := util.GetManifestAndConfig(ctx, repo, manifestDesc)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read modelkit: %w", err)
-	}
-	if manifest.Config.MediaType != constants.ModelConfigMediaType.String() {
-		return nil, nil
-	}
-	info := &modelInfo{
-		Repo:   ref.Repository,
-		Digest: string(manifestDesc.Digest),
-		Tags:   []string{ref.Reference},
+	manifestDescs := []ocispec.Descriptor{desc}
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		index, err := util.GetIndex(ctx, repo, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index: %w", err)
+		}
+		manifestDescs = index.Manifests
 	}
-	info.fill(manifest, config)
 
-	return info, nil
+	var infos []modelInfo
+	for _, manifestDesc := range manifestDescs {
+		manifest, config, err := util.GetManifestAndConfig(ctx, repo, manifestDesc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read modelkit: %w", err)
+		}
+		info := modelInfo{
+			Repo:    ref.Repository,
+			Digest:  string(manifestDesc.Digest),
+			Tags:    []string{ref.Reference},
+			Variant: util.VariantName(manifestDesc),
+		}
+		info.fill(manifest, config)
+		infos = append(infos, info)
+	}
+	return infos, nil
 }
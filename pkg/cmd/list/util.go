@@ -18,6 +18,7 @@ package list
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/output"
@@ -26,8 +27,8 @@ import (
 )
 
 const (
-	listTableHeader = "REPOSITORY\tTAG\tMAINTAINER\tNAME\tSIZE\tDIGEST"
-	listTableFmt    = "%s\t%s\t%s\t%s\t%s\t%s"
+	listTableHeader = "REPOSITORY\tTAG\tMAINTAINER\tNAME\tCREATED\tSIZE\tDIGEST"
+	listTableFmt    = "%s\t%s\t%s\t%s\t%s\t%s\t%s"
 )
 
 type modelInfo struct {
@@ -37,16 +38,19 @@ type modelInfo struct {
 	ModelName string   `json:"modelName"`
 	Size      string   `json:"size"`
 	Author    string   `json:"author"`
+	Created   string   `json:"created,omitempty"`
+	// Variant is the name of the variant if the modelkit is part of an index of variants
+	Variant string `json:"variant,omitempty"`
 }
 
 func (m *modelInfo) format() []string {
 	if len(m.Tags) == 0 {
-		line := fmt.Sprintf(listTableFmt, m.Repo, "<none>", m.Author, m.ModelName, m.Size, m.Digest)
+		line := fmt.Sprintf(listTableFmt, m.Repo, m.formatTag("<none>"), m.Author, m.ModelName, m.formatCreated(), m.Size, m.Digest)
 		return []string{line}
 	}
 	var lines []string
 	for _, tag := range m.Tags {
-		line := fmt.Sprintf(listTableFmt, m.Repo, tag, m.Author, m.ModelName, m.Size, m.Digest)
+		line := fmt.Sprintf(listTableFmt, m.Repo, m.formatTag(tag), m.Author, m.ModelName, m.formatCreated(), m.Size, m.Digest)
 		lines = append(lines, line)
 	}
 	return lines
@@ -56,6 +60,27 @@ func (m *modelInfo) fill(manifest *ocispec.Manifest, kitfile *artifact.KitFile)
 	m.Size = getModelSize(manifest)
 	m.Author = getModelAuthor(kitfile)
 	m.ModelName = getModelName(kitfile)
+	m.Created = manifest.Annotations[ocispec.AnnotationCreated]
+}
+
+// formatTag returns the tag for display in a table, including the variant name for variants.
+func (m *modelInfo) formatTag(tag string) string {
+	if m.Variant == "" {
+		return tag
+	}
+	return fmt.Sprintf("%s (%s)", tag, m.Variant)
+}
+
+// formatCreated returns the creation time for display in a table, in the local timezone.
+func (m *modelInfo) formatCreated() string {
+	if m.Created == "" {
+		return "<none>"
+	}
+	created, err := time.Parse(time.RFC3339, m.Created)
+	if err != nil {
+		return m.Created
+	}
+	return created.Local().Format("2006-01-02 15:04")
 }
 
 func getModelSize(manifest *ocispec.Manifest) string {
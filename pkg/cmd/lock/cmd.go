@@ -0,0 +1,121 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	shortDesc = "Pin modelkits referenced by a Kitfile to digests"
+	longDesc  = `Resolve the modelkits referenced by a Kitfile and pin them to digests in a
+Kitfile.lock file next to the Kitfile.
+
+When a Kitfile refers to another modelkit (e.g. 'model.path: org/base:latest'),
+the reference and any references in the referenced modelkit are resolved to the
+digests they currently refer to, checking local storage before the remote
+registry. 'kit pack' and 'kit dev' use the pinned digests instead of the tags,
+so that the Kitfile gives the same result even if the tags are later updated.
+
+'kit pack' creates Kitfile.lock automatically if it does not exist. Run this
+command to pin references to their current digests after they are updated; this
+is equivalent to 'kit pack --update-lock'.`
+
+	examples = `# Pin references in the Kitfile in the current directory
+kit lock
+
+# Pin references in a specific Kitfile
+kit lock ./my-model -f ./my-model/prod.kitfile`
+)
+
+type lockOptions struct {
+	options.KitfileTemplateOptions
+	configHome string
+	contextDir string
+	modelFile  string
+	variables  map[string]string
+}
+
+func (opts *lockOptions) complete(ctx context.Context, args []string) error {
+	configHome, ok := ctx.Value(constants.ConfigKey{}).(string)
+	if !ok {
+		return fmt.Errorf("default config path not set on command context")
+	}
+	opts.configHome = configHome
+
+	contextDir := "."
+	if len(args) > 0 {
+		contextDir = args[0]
+	}
+	absContextDir, err := filepath.Abs(contextDir)
+	if err != nil {
+		return fmt.Errorf("failed to get context dir %s: %w", contextDir, err)
+	}
+	opts.contextDir = absContextDir
+	if opts.modelFile == "" {
+		modelFile, err := filesystem.FindKitfileInPath(opts.contextDir)
+		if err != nil {
+			return err
+		}
+		opts.modelFile = modelFile
+	}
+
+	variables, err := kfutils.ParseVariables(opts.ValuesFile, opts.SetVariables)
+	if err != nil {
+		return err
+	}
+	opts.variables = variables
+	return nil
+}
+
+func LockCommand() *cobra.Command {
+	opts := &lockOptions{}
+	cmd := &cobra.Command{
+		Use:     "lock [flags] [DIRECTORY]",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		RunE:    runCommand(opts),
+		Args:    cobra.MaximumNArgs(1),
+	}
+	cmd.Flags().StringVarP(&opts.modelFile, "file", "f", "", "Specifies the path to the Kitfile explicitly")
+	opts.AddTemplateFlags(cmd)
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+func runCommand(opts *lockOptions) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := opts.complete(cmd.Context(), args); err != nil {
+			return output.Fatalf("Invalid arguments: %s", err)
+		}
+		if err := runLock(cmd.Context(), opts); err != nil {
+			return output.Fatalf("Failed to lock Kitfile: %s", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+// runLock resolves all modelkit references in the Kitfile in opts and writes them to Kitfile.lock.
+func runLock(ctx context.Context, opts *lockOptions) error {
+	kitfile, err := readKitfile(opts)
+	if err != nil {
+		return err
+	}
+	lock, err := kfutils.LockKitfile(ctx, opts.configHome, kitfile, "", nil)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		output.Infof("Kitfile %s does not reference any modelkits", opts.modelFile)
+		return nil
+	}
+	lockPath := kfutils.LockFilePath(opts.modelFile, opts.contextDir)
+	if err := lock.WriteFile(lockPath); err != nil {
+		return err
+	}
+	for _, locked := range lock.ModelKits {
+		output.Infof("Pinned %s to %s", locked.Reference, locked.Digest)
+	}
+	output.Infof("Wrote %s", lockPath)
+	return nil
+}
+
+func readKitfile(opts *lockOptions) (*artifact.KitFile, error) {
+	kitfileContent, err := os.ReadFile(opts.modelFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Kitfile: %w", err)
+	}
+	kitfile, _, err := kfutils.LoadKitfile(kitfileContent, kfutils.LoadOptions{
+		ContextDir:       opts.contextDir,
+		Variables:        opts.variables,
+		ExpandParameters: opts.ExpandParameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kitfile, nil
+}
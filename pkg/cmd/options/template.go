@@ -0,0 +1,35 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// KitfileTemplateOptions represent flags for setting variables that are expanded in a Kitfile (see
+// kitfile.ExpandVariables). The flags should be added to the command via AddTemplateFlags.
+type KitfileTemplateOptions struct {
+	SetVariables     []string
+	ValuesFile       string
+	ExpandParameters bool
+}
+
+func (o *KitfileTemplateOptions) AddTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&o.SetVariables, "set", []string{}, "Set a variable used in the Kitfile, in the format key=value. Can be specified multiple times")
+	cmd.Flags().StringVar(&o.ValuesFile, "values", "", "Path to a YAML file containing variables used in the Kitfile")
+	cmd.Flags().BoolVar(&o.ExpandParameters, "expand-parameters", false, "Also expand variables in the parameters sections of the Kitfile")
+}
@@ -21,12 +21,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
-
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
 	"github.com/spf13/cobra"
@@ -45,24 +48,90 @@ as pushing to a remote registry for collaboration.
 Unless a different location is specified, this command looks for the kitfile
 at the root of the provided context directory. Any relative paths defined
 within the kitfile are interpreted as being relative to this context
-directory.`
+directory.
+
+Values in the kitfile may contain variables such as ${VERSION}, which are set
+with --set or --values, and a top-level 'include' field merges in other kitfile
+fragments. References to other modelkits are pinned to digests in a Kitfile.lock
+file next to the kitfile.
+
+Links are stored as links unless --dereference is used. Layers can be compressed
+(--compression), split by size (--max-layer-size), split into chunks shared between
+modelkits (--chunked), or uploaded as they are packed (--push). Use --dry-run to see
+what would be packed, --reproducible to get identical modelkits on any system, and
+--variant to group several variants of a model under one tag.`
 
 	examples = `# Pack a modelkit using the kitfile in the current directory
 kit pack .
 
 # Pack a modelkit with a specific kitfile and tag
-kit pack . -f /path/to/your/Kitfile -t registry/repository:modelv1`
+kit pack . -f /path/to/your/Kitfile -t registry/repository:modelv1
+
+# Pack a modelkit, setting variables used in the Kitfile
+kit pack . --values prod-values.yaml --set VERSION=1.2.0 -t mymodel:1.2.0
+
+# Pack a modelkit and upload it to a remote registry without saving it locally
+kit pack . -t registry.example.com/my-org/my-model:latest --push --skip-local
+
+# Pack a modelkit, storing the contents of symlinked files instead of the symlinks
+kit pack . --dereference
+
+# Pack a modelkit, splitting large files into chunks that can be shared between versions
+kit pack . -t my-model:v2 --chunked
+
+# Pack a modelkit, compressing only layers that benefit from compression
+kit pack . --compression auto
+
+# Pack a modelkit, storing the schema and size of datasets in the Kitfile
+kit pack . -t my-model:v1 --dataset-metadata
+
+# Show which files would be included in each layer without packing
+kit pack . --dry-run
+
+# Pack a modelkit reproducibly, using the time of the last git commit for all files
+SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) kit pack . --reproducible
+
+# Check that packing is reproducible without saving the modelkit
+kit pack . --reproducible --check-reproducible
+
+# Pack a modelkit, splitting paths larger than 5 GiB into multiple layers
+kit pack . --max-layer-size 5GiB
+
+# Pack two variants of a model under the same tag
+kit pack ./q4 -t my-model:v1 --variant q4 --variant-annotation quantization=q4
+kit pack ./f16 -t my-model:v1 --variant f16 --variant-annotation quantization=f16`
 )
 
 type packOptions struct {
-	modelFile   string
-	contextDir  string
-	configHome  string
-	storageHome string
-	fullTagRef  string
-	compression string
-	modelRef    *registry.Reference
-	extraRefs   []string
+	options.NetworkOptions
+	options.KitfileTemplateOptions
+	modelFile       string
+	contextDir      string
+	configHome      string
+	storageHome     string
+	fullTagRef      string
+	compression     string
+	dereference     bool
+	chunked         bool
+	maxLayerSizeStr string
+	maxLayerSize    int64
+	reproducible    bool
+	checkRepro      bool
+	dryRun          bool
+	ociArtifact     bool
+	output          string
+	sourceDateEpoch *time.Time
+	variant         string
+	variantAnnots   []string
+	variantAnnotMap map[string]string
+	push            bool
+	skipLocal       bool
+	modelRef        *registry.Reference
+	extraRefs       []string
+	variables       map[string]string
+	updateLock      bool
+	lock            *kfutils.Lock
+	datasetMetadata bool
 }
 
 func PackCommand() *cobra.Command {
@@ -77,7 +146,23 @@ func PackCommand() *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&opts.modelFile, "file", "f", "", "Specifies the path to the Kitfile explicitly (use \"-\" to read from standard input)")
 	cmd.Flags().StringVarP(&opts.fullTagRef, "tag", "t", "", "Assigns one or more tags to the built modelkit. Example: -t registry/repository:tag1,tag2")
-	cmd.Flags().StringVar(&opts.compression, "compression", "none", "Compression format to use for layers. Valid options: 'none' (default), 'gzip', 'gzip-fastest'")
+	cmd.Flags().StringVar(&opts.compression, "compression", "none", "Compression format to use for layers. Valid options: 'none' (default), 'gzip', 'gzip-fastest', 'auto'")
+	cmd.Flags().BoolVar(&opts.dereference, "dereference", false, "Store the contents of symlink and hard link targets instead of the links themselves")
+	cmd.Flags().BoolVar(&opts.chunked, "chunked", false, "Split large files into content-defined chunks to deduplicate content between modelkits")
+	cmd.Flags().StringVar(&opts.maxLayerSizeStr, "max-layer-size", "", "Split paths larger than the given size (e.g. 10GB) into multiple layers")
+	cmd.Flags().BoolVar(&opts.reproducible, "reproducible", false, "Normalize file permissions and timestamps so that the modelkit is identical when packed on any system")
+	cmd.Flags().BoolVar(&opts.checkRepro, "check-reproducible", false, "Pack twice without saving the modelkit and report any layers that differ")
+	cmd.Flags().BoolVar(&opts.ociArtifact, "oci-artifact", false, "Set the artifactType of the manifest, so that registries that support OCI 1.1 display the modelkit as an artifact")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "List the files that would be included in each layer without packing the modelkit")
+	cmd.Flags().StringVar(&opts.output, "output", outputText, "Output format for --dry-run: 'text' or 'json'")
+	cmd.Flags().StringVar(&opts.variant, "variant", "", "Add the modelkit as a named variant to the image index stored under --tag")
+	cmd.Flags().StringArrayVar(&opts.variantAnnots, "variant-annotation", []string{}, "Annotation describing the variant, in the format key=value (e.g. quantization=q4). Can be specified multiple times")
+	cmd.Flags().BoolVar(&opts.push, "push", false, "Upload layers to the remote registry specified by --tag as they are packed")
+	cmd.Flags().BoolVar(&opts.skipLocal, "skip-local", false, "When used with --push, do not save the modelkit to local storage")
+	cmd.Flags().BoolVar(&opts.datasetMetadata, "dataset-metadata", false, "Read the number of files, size, and schema of csv, jsonl, and parquet files in datasets and store them in the Kitfile")
+	cmd.Flags().BoolVar(&opts.updateLock, "update-lock", false, "Resolve referenced modelkits again instead of using the digests pinned in Kitfile.lock, and update the file")
+	opts.AddTemplateFlags(cmd)
+	opts.AddNetworkFlags(cmd)
 	cmd.Flags().SortFlags = false
 	cmd.Args = cobra.ExactArgs(1)
 	return cmd
@@ -141,10 +226,88 @@ func (opts *packOptions) complete(ctx context.Context, args []string) error {
 		return err
 	}
 
+	variables, err := kfutils.ParseVariables(opts.ValuesFile, opts.SetVariables)
+	if err != nil {
+		return err
+	}
+	opts.variables = variables
+
+	if opts.maxLayerSizeStr != "" {
+		maxLayerSize, err := output.ParseBytes(opts.maxLayerSizeStr)
+		if err != nil {
+			return fmt.Errorf("invalid value for --max-layer-size: %w", err)
+		}
+		opts.maxLayerSize = maxLayerSize
+	}
+
+	if epoch := os.Getenv(constants.SourceDateEpochEnvVar); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", constants.SourceDateEpochEnvVar, err)
+		}
+		sourceDateEpoch := time.Unix(seconds, 0)
+		opts.sourceDateEpoch = &sourceDateEpoch
+		opts.reproducible = true
+	}
+
+	switch opts.output {
+	case outputText, outputJson:
+	default:
+		return fmt.Errorf("invalid output format %q: must be one of 'text' or 'json'", opts.output)
+	}
+	if opts.output != outputText && !opts.dryRun {
+		return fmt.Errorf("--output can only be used with --dry-run")
+	}
+	if opts.dryRun && (opts.push || opts.skipLocal || opts.checkRepro) {
+		return fmt.Errorf("--dry-run cannot be used with --push, --skip-local, or --check-reproducible")
+	}
+
+	if opts.checkRepro && (opts.push || opts.skipLocal) {
+		return fmt.Errorf("--check-reproducible cannot be used with --push or --skip-local")
+	}
+
+	if opts.variant != "" {
+		if opts.fullTagRef == "" {
+			return fmt.Errorf("--variant requires a tag specified with --tag")
+		}
+		if strings.ContainsAny(opts.variant, "=,") {
+			return fmt.Errorf("invalid variant name %q: must not contain '=' or ','", opts.variant)
+		}
+		if opts.push || opts.checkRepro || opts.dryRun {
+			return fmt.Errorf("--variant cannot be used with --push, --check-reproducible, or --dry-run")
+		}
+		variantAnnotations, err := util.ParseVariantAnnotations(opts.variantAnnots)
+		if err != nil {
+			return err
+		}
+		opts.variantAnnotMap = variantAnnotations
+	} else if len(opts.variantAnnots) > 0 {
+		return fmt.Errorf("--variant-annotation can only be used with --variant")
+	}
+
+	if opts.push {
+		if opts.fullTagRef == "" || opts.modelRef.Registry == util.DefaultRegistry {
+			return fmt.Errorf("--push requires a tag that includes a registry")
+		}
+		if err := opts.NetworkOptions.Complete(ctx, args); err != nil {
+			return err
+		}
+	} else if opts.skipLocal {
+		return fmt.Errorf("--skip-local can only be used with --push")
+	}
+
 	printConfig(opts)
 	return nil
 }
 
+// artifactType returns the artifactType to set on the modelkit's manifest, if any.
+func (opts *packOptions) artifactType() string {
+	if opts.ociArtifact {
+		return constants.ModelKitArtifactType
+	}
+	return ""
+}
+
 func printConfig(opts *packOptions) {
 	output.Debugf("Using storage path: %s", opts.storageHome)
 	output.Debugf("Context dir: %s", opts.contextDir)
@@ -0,0 +1,122 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+const (
+	outputText = "text"
+	outputJson = "json"
+)
+
+// dryRunResult is the output of --dry-run in JSON format.
+type dryRunResult struct {
+	// Kitfile is the Kitfile that would be stored in the modelkit, with includes merged
+	Kitfile *artifact.KitFile `json:"kitfile"`
+	// Includes lists the Kitfile fragments merged into the Kitfile
+	Includes []string            `json:"includes,omitempty"`
+	Layers   []kfutils.LayerPlan `json:"layers"`
+}
+
+// dryRun prints the files that would be included in each layer of the modelkit, along with files
+// that are excluded from each layer and why, without packing anything. JSON output always includes the
+// Kitfile that would be packed; text output includes it only if the Kitfile includes other files.
+func dryRun(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile, includes []string) error {
+	ignore, err := newIgnore(ctx, opts, kitfile)
+	if err != nil {
+		return err
+	}
+	plans, err := kfutils.PlanLayers(kitfile, ignore, kfutils.SaveModelOptions{
+		Compression:  opts.compression,
+		Dereference:  opts.dereference,
+		MaxLayerSize: opts.maxLayerSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.output == outputJson {
+		result := dryRunResult{Kitfile: kitfile, Includes: includes, Layers: plans}
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format output: %w", err)
+		}
+		output.Infoln(string(jsonBytes))
+		return nil
+	}
+	if len(includes) > 0 {
+		kitfileBytes, err := kitfile.MarshalToYAML()
+		if err != nil {
+			return fmt.Errorf("failed to format Kitfile: %w", err)
+		}
+		output.Infof("Merged Kitfile (includes %s):\n\n%s", strings.Join(includes, ", "), string(kitfileBytes))
+	}
+	output.Infoln(formatPlans(plans))
+	return nil
+}
+
+func formatPlans(plans []kfutils.LayerPlan) string {
+	var totalSize int64
+	sb := &strings.Builder{}
+	for _, plan := range plans {
+		totalSize += plan.Size
+		title := plan.Type
+		if plan.Name != "" {
+			title = fmt.Sprintf("%s %s", plan.Type, plan.Name)
+		}
+		fmt.Fprintf(sb, "%s (path: %s)\n", title, plan.Path)
+		filesNoun := "files"
+		if len(plan.Files) == 1 {
+			filesNoun = "file"
+		}
+		fmt.Fprintf(sb, "  Size: %s in %d %s", output.FormatBytes(plan.Size), len(plan.Files), filesNoun)
+		if plan.Layers > 1 {
+			fmt.Fprintf(sb, ", split into %d layers", plan.Layers)
+		}
+		fmt.Fprintf(sb, " (compression: %s)\n", plan.Compression)
+		if len(plan.Files) > 0 {
+			fmt.Fprintln(sb, "  Included:")
+			for _, file := range plan.Files {
+				fmt.Fprintf(sb, "    %s\n", file)
+			}
+		}
+		if len(plan.Ignored) > 0 {
+			fmt.Fprintln(sb, "  Excluded by .kitignore:")
+			for _, file := range plan.Ignored {
+				fmt.Fprintf(sb, "    %s\n", file)
+			}
+		}
+		if len(plan.Claimed) > 0 {
+			fmt.Fprintln(sb, "  Included in another layer:")
+			for _, claimed := range plan.Claimed {
+				fmt.Fprintf(sb, "    %s (%s)\n", claimed.Path, claimed.Layer)
+			}
+		}
+		fmt.Fprintln(sb)
+	}
+	fmt.Fprintf(sb, "Total size: %s", output.FormatBytes(totalSize))
+	return sb.String()
+}
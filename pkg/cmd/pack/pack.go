@@ -21,16 +21,21 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/lib/git"
 	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
 )
 
 // runPack compresses and stores a modelkit based on a Kitfile. Returns an error if packing
@@ -41,10 +46,19 @@ import (
 // registry/repository reference at a time, individual blobs may be duplicated on disk if stored
 // under different references.
 func runPack(ctx context.Context, options *packOptions) error {
-	kitfile, err := readKitfile(options.modelFile)
+	kitfile, includes, err := readKitfile(options)
 	if err != nil {
 		return err
 	}
+	if err := lockReferences(ctx, options, kitfile); err != nil {
+		return err
+	}
+	if options.checkRepro {
+		return checkReproducible(ctx, options, kitfile)
+	}
+	if options.dryRun {
+		return dryRun(ctx, options, kitfile, includes)
+	}
 
 	storageHome := constants.StoragePath(options.configHome)
 	localRepo, err := local.NewLocalRepo(storageHome, options.modelRef)
@@ -52,11 +66,38 @@ func runPack(ctx context.Context, options *packOptions) error {
 		return fmt.Errorf("failed to open local storage: %w", err)
 	}
 
-	manifestDesc, err := pack(ctx, options, kitfile, localRepo)
+	var remoteRepo oras.Target
+	var uploadLogger *output.ProgressLogger
+	if options.push {
+		repo, err := remote.NewRepository(ctx, options.modelRef.Registry, options.modelRef.Repository, &options.NetworkOptions)
+		if err != nil {
+			return err
+		}
+		remoteRepo, uploadLogger = output.WrapTarget(repo)
+	}
+
+	manifestDesc, err := pack(ctx, options, kitfile, localRepo, remoteRepo)
 	if err != nil {
 		return err
 	}
 
+	if remoteRepo != nil {
+		uploadLogger.Wait()
+		for _, tag := range append([]string{options.modelRef.Reference}, options.extraRefs...) {
+			if err := remoteRepo.Tag(ctx, *manifestDesc, tag); err != nil {
+				return fmt.Errorf("failed to tag manifest in remote: %w", err)
+			}
+		}
+		output.Infof("Pushed %s", util.FormatRepositoryForDisplay(options.modelRef.String()))
+	}
+	if options.skipLocal {
+		output.Infof("Model pushed: %s", manifestDesc.Digest)
+		return nil
+	}
+	if options.variant != "" {
+		return addVariant(ctx, options, localRepo, *manifestDesc)
+	}
+
 	if options.modelRef != nil && options.modelRef.Reference != "" {
 		if err := localRepo.Tag(ctx, *manifestDesc, options.modelRef.Reference); err != nil {
 			return fmt.Errorf("failed to tag manifest: %w", err)
@@ -75,44 +116,154 @@ func runPack(ctx context.Context, options *packOptions) error {
 	return nil
 }
 
-func pack(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile, localRepo local.LocalRepo) (*ocispec.Descriptor, error) {
-	var extraLayerPaths []string
-	if kitfile.Model != nil && util.IsModelKitReference(kitfile.Model.Path) {
-		baseRef := util.FormatRepositoryForDisplay(opts.modelRef.String())
-		parentKitfile, err := kfutils.ResolveKitfile(ctx, opts.configHome, kitfile.Model.Path, baseRef)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to resolve referenced modelkit %s: %w", kitfile.Model.Path, err)
+func pack(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile, localRepo local.LocalRepo, remoteRepo oras.Target) (*ocispec.Descriptor, error) {
+	ignore, err := newIgnore(ctx, opts, kitfile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.datasetMetadata {
+		if err := kfutils.AddDatasetMetadata(kitfile, ignore, kfutils.SaveModelOptions{Dereference: opts.dereference}); err != nil {
+			return nil, fmt.Errorf("failed to compute dataset metadata: %w", err)
 		}
-		extraLayerPaths = util.LayerPathsFromKitfile(parentKitfile)
 	}
 
-	ignore, err := filesystem.NewIgnoreFromContext(opts.contextDir, kitfile, extraLayerPaths...)
+	annotations, err := manifestAnnotations(opts)
 	if err != nil {
 		return nil, err
 	}
-
-	manifestDesc, err := kfutils.SaveModel(ctx, localRepo, kitfile, ignore, opts.compression)
+	manifestDesc, err := kfutils.SaveModel(ctx, localRepo, kitfile, ignore, kfutils.SaveModelOptions{
+		Compression:     opts.compression,
+		Dereference:     opts.dereference,
+		Chunked:         opts.chunked,
+		MaxLayerSize:    opts.maxLayerSize,
+		Reproducible:    opts.reproducible,
+		SourceDateEpoch: opts.sourceDateEpoch,
+		Annotations:     annotations,
+		ArtifactType:    opts.artifactType(),
+		Remote:          remoteRepo,
+		SkipLocal:       opts.skipLocal,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return manifestDesc, nil
 }
 
-func readKitfile(modelFile string) (*artifact.KitFile, error) {
-	// 1. Read the model file
-	kitfile := &artifact.KitFile{}
-	kitfileContentReader, err := readerForKitfile(modelFile)
+// manifestAnnotations returns annotations describing the source the modelkit was packed from. The
+// creation time is taken from SOURCE_DATE_EPOCH or, if the context directory has no uncommitted changes,
+// the time of the git commit checked out in it, so that repacking the same content produces the same
+// modelkit. Otherwise, the creation time is left unset, as the current time would change the modelkit's
+// digest every time it is packed.
+// Digests that referenced modelkits were pinned to are stored so that they can be used when unpacking.
+func manifestAnnotations(opts *packOptions) (map[string]string, error) {
+	annotations := map[string]string{}
+	if repoInfo := git.GetRepoInfo(opts.contextDir); repoInfo != nil {
+		annotations[ocispec.AnnotationSource] = repoInfo.Source
+		annotations[ocispec.AnnotationRevision] = repoInfo.Revision
+		if !repoInfo.Dirty {
+			annotations[ocispec.AnnotationCreated] = repoInfo.CommitTime.Format(time.RFC3339)
+		}
+	}
+	if opts.sourceDateEpoch != nil {
+		annotations[ocispec.AnnotationCreated] = opts.sourceDateEpoch.UTC().Format(time.RFC3339)
+	}
+	if opts.lock != nil {
+		lockAnnotation, err := opts.lock.Annotation()
+		if err != nil {
+			return nil, err
+		}
+		annotations[constants.LockAnnotation] = lockAnnotation
+	}
+	return annotations, nil
+}
+
+// lockReferences pins modelkits referenced by the Kitfile to digests, using the digests in Kitfile.lock
+// unless --update-lock is set. The lock file is created or updated if it does not match the result,
+// except for dry runs.
+func lockReferences(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile) error {
+	lockPath := kfutils.LockFilePath(opts.modelFile, opts.contextDir)
+	existing, err := kfutils.ReadLockFile(lockPath)
+	if err != nil {
+		return err
+	}
+	pins := existing
+	if opts.updateLock {
+		pins = nil
+	}
+	baseRef := util.FormatRepositoryForDisplay(opts.modelRef.String())
+	lock, err := kfutils.LockKitfile(ctx, opts.configHome, kitfile, baseRef, pins)
+	if err != nil {
+		return err
+	}
+	opts.lock = lock
+	if lock == nil || lock.Equal(existing) || opts.dryRun || opts.checkRepro {
+		return nil
+	}
+	if err := lock.WriteFile(lockPath); err != nil {
+		return err
+	}
+	output.Infof("Pinned referenced modelkits in %s", lockPath)
+	return nil
+}
+
+// newIgnore returns the IgnorePaths used to select files for each layer in kitfile. If the Kitfile refers to
+// other modelkits, paths of layers included from those modelkits are also excluded.
+func newIgnore(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile) (filesystem.IgnorePaths, error) {
+	var extraLayerPaths []string
+	baseRef := util.FormatRepositoryForDisplay(opts.modelRef.String())
+	if kitfile.Model != nil && util.IsModelKitReference(kitfile.Model.Path) {
+		parentKitfile, err := kfutils.ResolveKitfile(ctx, opts.configHome, kitfile.Model.Path, baseRef, opts.lock)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to resolve referenced modelkit %s: %w", kitfile.Model.Path, err)
+		}
+		extraLayerPaths = util.LayerPathsFromKitfile(parentKitfile)
+	}
+	err := kfutils.WalkReferences(ctx, opts.configHome, kitfile, baseRef, opts.lock, func(ref util.KitfileReference, _ ocispec.Descriptor, refKitfile *artifact.KitFile) error {
+		switch ref.BaseType {
+		case constants.DatasetType:
+			for _, dataset := range refKitfile.DataSets {
+				if !util.IsModelKitReference(dataset.Path) {
+					extraLayerPaths = append(extraLayerPaths, filepath.Clean(dataset.Path))
+				}
+			}
+		case constants.CodeType:
+			for _, code := range refKitfile.Code {
+				if !util.IsModelKitReference(code.Path) {
+					extraLayerPaths = append(extraLayerPaths, filepath.Clean(code.Path))
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return filesystem.NewIgnoreFromContext(opts.contextDir, kitfile, extraLayerPaths...)
+}
+
+// readKitfile reads, merges and validates the Kitfile for opts, returning the Kitfile along with the paths
+// of any files it includes.
+func readKitfile(opts *packOptions) (*artifact.KitFile, []string, error) {
+	// 1. Read the model file
+	kitfileContentReader, err := readerForKitfile(opts.modelFile)
+	if err != nil {
+		return nil, nil, err
+	}
 	defer kitfileContentReader.Close()
-	if err := kitfile.LoadModel(kitfileContentReader); err != nil {
-		return nil, err
+	kitfileContent, err := io.ReadAll(kitfileContentReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read Kitfile: %w", err)
 	}
-	if err := kfutils.ValidateKitfile(kitfile); err != nil {
-		return nil, err
+	// 2. Merge included files, expand variables, and validate the result
+	kitfile, includes, err := kfutils.LoadKitfile(kitfileContent, kfutils.LoadOptions{
+		ContextDir:       opts.contextDir,
+		Variables:        opts.variables,
+		ExpandParameters: opts.ExpandParameters,
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return kitfile, nil
+	return kitfile, includes, nil
 }
 
 // readerForKitfile returns a reader for the Kitfile specified by the modelFile argument.
@@ -0,0 +1,93 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem/cache"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// checkReproducible packs the modelkit twice into temporary storage and returns an error describing
+// which parts of the modelkit differ if the resulting manifests are not identical.
+func checkReproducible(ctx context.Context, opts *packOptions, kitfile *artifact.KitFile) error {
+	storageDir, cleanup, err := cache.MkCacheDir(cache.CacheCheckSubdir, "")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	checkRepo, err := local.NewLocalRepo(storageDir, opts.modelRef)
+	if err != nil {
+		return fmt.Errorf("failed to open temporary storage: %w", err)
+	}
+
+	var manifests [2]*ocispec.Manifest
+	var manifestDescs [2]*ocispec.Descriptor
+	for idx := range manifests {
+		output.Infof("Packing modelkit (%d of %d)", idx+1, len(manifests))
+		desc, err := pack(ctx, opts, kitfile, checkRepo, nil)
+		if err != nil {
+			return err
+		}
+		manifest, err := util.GetManifest(ctx, checkRepo, *desc)
+		if err != nil {
+			return err
+		}
+		manifests[idx], manifestDescs[idx] = manifest, desc
+	}
+
+	if manifestDescs[0].Digest == manifestDescs[1].Digest {
+		output.Infof("Modelkit is reproducible: %s", manifestDescs[0].Digest)
+		return nil
+	}
+	differences := compareManifests(manifests[0], manifests[1])
+	return fmt.Errorf("modelkit is not reproducible (got %s and %s): %s",
+		manifestDescs[0].Digest, manifestDescs[1].Digest, strings.Join(differences, "; "))
+}
+
+// compareManifests returns a description of each layer or config that differs between two
+// manifests packed from the same Kitfile.
+func compareManifests(first, second *ocispec.Manifest) []string {
+	var differences []string
+	if first.Config.Digest != second.Config.Digest {
+		differences = append(differences, "config differs")
+	}
+	if len(first.Layers) != len(second.Layers) {
+		differences = append(differences, fmt.Sprintf("number of layers differs (%d and %d)", len(first.Layers), len(second.Layers)))
+		return differences
+	}
+	for idx := range first.Layers {
+		a, b := first.Layers[idx], second.Layers[idx]
+		if a.Digest != b.Digest {
+			differences = append(differences, fmt.Sprintf("%s layer %d differs (%s and %s)",
+				constants.FormatMediaTypeForUser(a.MediaType), idx, a.Digest, b.Digest))
+		}
+	}
+	if len(differences) == 0 {
+		differences = append(differences, "manifest annotations differ")
+	}
+	return differences
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// addVariant adds a packed modelkit to the index of variants stored under the tag(s) in opts, creating
+// the index if the tag does not exist yet. The modelkit itself is not tagged. Since all tags are moved to
+// the new index, additional tags must either not exist or refer to the same index as the first tag.
+func addVariant(ctx context.Context, opts *packOptions, localRepo local.LocalRepo, manifestDesc ocispec.Descriptor) error {
+	index, prevIndexDesc, err := loadVariantIndex(ctx, localRepo, opts.modelRef.Reference)
+	if err != nil {
+		return err
+	}
+	for _, tag := range opts.extraRefs {
+		_, extraIndexDesc, err := loadVariantIndex(ctx, localRepo, tag)
+		if err != nil {
+			return err
+		}
+		if extraIndexDesc != nil && (prevIndexDesc == nil || extraIndexDesc.Digest != prevIndexDesc.Digest) {
+			return fmt.Errorf("tag %s refers to different variants than tag %s; use a different tag or remove it first", tag, opts.modelRef.Reference)
+		}
+	}
+	util.SetVariant(index, manifestDesc, opts.variant, opts.variantAnnotMap)
+
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexBytes)
+	if err := localRepo.Push(ctx, indexDesc, bytes.NewReader(indexBytes)); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+	for _, tag := range append([]string{opts.modelRef.Reference}, opts.extraRefs...) {
+		if err := localRepo.Tag(ctx, indexDesc, tag); err != nil {
+			return fmt.Errorf("failed to tag index: %w", err)
+		}
+	}
+	// The previous index only groups variants, so it is removed once it is no longer tagged. Variants it
+	// contained that are not part of the new index are removed along with it.
+	if prevIndexDesc != nil && prevIndexDesc.Digest != indexDesc.Digest && len(localRepo.GetTags(*prevIndexDesc)) == 0 {
+		if err := localRepo.Delete(ctx, *prevIndexDesc); err != nil {
+			output.Logf(output.LogLevelWarn, "Failed to remove previous index %s: %s", prevIndexDesc.Digest, err)
+		}
+	}
+
+	output.Infof("Model saved: %s (variant %s)", manifestDesc.Digest, opts.variant)
+	output.Infof("Variants in %s: %s", util.FormatRepositoryForDisplay(opts.modelRef.String()), strings.Join(util.VariantNames(index), ", "))
+	return nil
+}
+
+// loadVariantIndex returns the index of variants currently stored under tag along with its descriptor, or an
+// empty index if the tag does not exist. Tags that refer to a regular modelkit are not replaced by an index.
+func loadVariantIndex(ctx context.Context, localRepo local.LocalRepo, tag string) (*ocispec.Index, *ocispec.Descriptor, error) {
+	desc, err := localRepo.Resolve(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return &ocispec.Index{
+				Versioned: specs.Versioned{SchemaVersion: 2},
+				MediaType: ocispec.MediaTypeImageIndex,
+			}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		return nil, nil, fmt.Errorf("tag %s refers to a modelkit without variants; use a different tag or remove it first", tag)
+	}
+	index, err := util.GetIndex(ctx, localRepo, desc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return index, &desc, nil
+}
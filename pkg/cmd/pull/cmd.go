@@ -22,6 +22,7 @@ import (
 
 	"github.com/kitops-ml/kitops/pkg/cmd/options"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
@@ -32,16 +33,37 @@ import (
 const (
 	shortDesc = `Retrieve modelkits from a remote registry to your local environment.`
 	longDesc  = `Downloads modelkits from a specified registry. The downloaded modelkits
-are stored in the local registry.`
+are stored in the local registry.
+
+If the reference is an index of modelkit variants (see 'kit pack --variant'),
+all variants are pulled by default. Use --variant to pull only one variant,
+selected either by name or by a comma-separated list of key=value variant
+annotations that must all match. When a single variant is pulled, the tag
+refers to that variant in local storage.
+
+Modelkits referenced by the pulled modelkit (in model.path, or in the path of a
+dataset or code entry) are pulled as well. If the modelkit was packed with a
+Kitfile.lock, referenced modelkits are pulled using the digests pinned when it
+was packed.`
 
 	example = `# Pull the latest version of a modelkit from a remote registry
-kit pull registry.example.com/my-model:latest`
+kit pull registry.example.com/my-model:latest
+
+# Pull only the q4 variant of a modelkit
+kit pull registry.example.com/my-model:latest --variant q4
+
+# Pull the variant built for CUDA with 4-bit quantization
+kit pull registry.example.com/my-model:latest --variant quantization=q4,hardware=cuda`
 )
 
 type pullOptions struct {
 	options.NetworkOptions
 	configHome string
 	modelRef   *registry.Reference
+	variant    string
+	// lock pins referenced modelkits to digests, and is read from the first modelkit in a chain of
+	// references that was packed with one
+	lock *kfutils.Lock
 }
 
 func (opts *pullOptions) complete(ctx context.Context, args []string) error {
@@ -81,6 +103,7 @@ func PullCommand() *cobra.Command {
 	}
 
 	cmd.Args = cobra.ExactArgs(1)
+	cmd.Flags().StringVar(&opts.variant, "variant", "", "Pull only the variant matching a name or key=value annotations")
 	opts.AddNetworkFlags(cmd)
 	cmd.Flags().SortFlags = false
 
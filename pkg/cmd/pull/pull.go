@@ -23,6 +23,7 @@ import (
 	"io"
 	"strings"
 
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
@@ -67,22 +68,49 @@ func runPullRecursive(ctx context.Context, localRepo local.LocalRepo, opts *pull
 }
 
 func pullParents(ctx context.Context, localRepo local.LocalRepo, desc ocispec.Descriptor, optsIn *pullOptions, pulledRefs []string) error {
-	_, config, err := util.GetManifestAndConfig(ctx, localRepo, desc)
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		index, err := util.GetIndex(ctx, localRepo, desc)
+		if err != nil {
+			return err
+		}
+		for _, variantDesc := range index.Manifests {
+			if err := pullParents(ctx, localRepo, variantDesc, optsIn, pulledRefs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	manifest, config, err := util.GetManifestAndConfig(ctx, localRepo, desc)
 	if err != nil {
 		return err
 	}
-	if config.Model == nil || !util.IsModelKitReference(config.Model.Path) {
+	refs := util.ReferencesFromKitfile(config)
+	if len(refs) == 0 {
 		return nil
 	}
-	output.Infof("Pulling referenced image %s", config.Model.Path)
-	parentRef, _, err := util.ParseReference(config.Model.Path)
-	if err != nil {
-		return err
+	lock := optsIn.lock
+	if lock == nil {
+		lock, err = kfutils.LockFromManifest(manifest)
+		if err != nil {
+			return err
+		}
 	}
-	opts := *optsIn
-	opts.modelRef = parentRef
-	_, err = runPullRecursive(ctx, localRepo, &opts, pulledRefs)
-	return err
+	for _, ref := range refs {
+		parentRefStr := lock.Pin(ref.Ref)
+		output.Infof("Pulling referenced image %s", parentRefStr)
+		parentRef, _, err := util.ParseReference(parentRefStr)
+		if err != nil {
+			return err
+		}
+		opts := *optsIn
+		opts.modelRef = parentRef
+		opts.variant = ""
+		opts.lock = lock
+		if _, err := runPullRecursive(ctx, localRepo, &opts, pulledRefs); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func pullModel(ctx context.Context, localRepo local.LocalRepo, opts *pullOptions) (ocispec.Descriptor, error) {
@@ -94,40 +122,68 @@ func pullModel(ctx context.Context, localRepo local.LocalRepo, opts *pullOptions
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to read repository: %w", err)
 	}
-	if err := referenceIsModel(ctx, opts.modelRef, repo); err != nil {
+	desc, err := referenceIsModel(ctx, opts.modelRef, repo)
+	if err != nil {
 		return ocispec.DescriptorEmptyJSON, err
 	}
 
-	desc, err := localRepo.PullModel(ctx, repo, *opts.modelRef, &opts.NetworkOptions)
+	// When a variant is selected, only that variant is pulled, and the tag refers to it in local storage
+	pullRef := *opts.modelRef
+	if opts.variant != "" {
+		variantDesc, err := util.ResolveVariant(ctx, repo, desc, opts.variant)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+		output.Infof("Selected variant %s (digest %s)", util.VariantName(variantDesc), variantDesc.Digest)
+		pullRef.Reference = variantDesc.Digest.String()
+	}
+
+	desc, err = localRepo.PullModel(ctx, repo, pullRef, &opts.NetworkOptions)
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to pull: %w", err)
 	}
+	if opts.variant != "" && !util.ReferenceIsDigest(opts.modelRef.Reference) {
+		if err := localRepo.Tag(ctx, desc, opts.modelRef.Reference); err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to save tag: %w", err)
+		}
+	}
 
 	return desc, nil
 }
 
-func referenceIsModel(ctx context.Context, ref *registry.Reference, repo registry.Repository) error {
+// referenceIsModel checks that ref refers to a modelkit or to an index of modelkit variants, returning its descriptor.
+func referenceIsModel(ctx context.Context, ref *registry.Reference, repo registry.Repository) (ocispec.Descriptor, error) {
 	desc, rc, err := repo.FetchReference(ctx, ref.Reference)
 	if err != nil {
-		return fmt.Errorf("failed to fetch %s: %w", ref.String(), err)
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to fetch %s: %w", ref.String(), err)
 	}
 	defer rc.Close()
 
-	if desc.MediaType != ocispec.MediaTypeImageManifest {
-		return fmt.Errorf("reference %s is not an image manifest", ref.String())
+	if desc.MediaType != ocispec.MediaTypeImageManifest && desc.MediaType != ocispec.MediaTypeImageIndex {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("reference %s is not an image manifest or index", ref.String())
 	}
 	manifestBytes, err := io.ReadAll(rc)
 	if err != nil {
-		return fmt.Errorf("failed to read manifest: %w", err)
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		index := &ocispec.Index{}
+		if err := json.Unmarshal(manifestBytes, index); err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to parse index: %w", err)
+		}
+		if !util.IsVariantIndex(index) {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("reference %s does not refer to a model", ref.String())
+		}
+		return desc, nil
 	}
 	manifest := &ocispec.Manifest{}
 	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
-		return fmt.Errorf("failed to parse manifest: %w", err)
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to parse manifest: %w", err)
 	}
-	if manifest.Config.MediaType != constants.ModelConfigMediaType.String() {
-		return fmt.Errorf("reference %s does not refer to a model", ref.String())
+	if !util.IsModelKitManifest(manifest) {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("reference %s does not refer to a model", ref.String())
 	}
-	return nil
+	return desc, nil
 }
 
 func getIndex(list []string, s string) int {
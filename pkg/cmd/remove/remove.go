@@ -17,10 +17,12 @@
 package remove
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
@@ -46,7 +48,11 @@ func removeAllModels(ctx context.Context, opts *removeOptions) error {
 	for _, localRepo := range localRepos {
 		repository := util.FormatRepositoryForDisplay(localRepo.GetRepoName())
 
-		models := localRepo.GetAllModels()
+		// Remove indexes first, as variants cannot be removed while they are part of an index
+		models := slices.Clone(localRepo.GetAllModels())
+		slices.SortStableFunc(models, func(a, b ocispec.Descriptor) int {
+			return cmp.Compare(indexFirst(a), indexFirst(b))
+		})
 
 		// Store a list of removed manifests for this LocalStore. This is necessary
 		// as index.Manifests may have multiple manifest descriptors with the same
@@ -66,6 +72,10 @@ func removeAllModels(ctx context.Context, opts *removeOptions) error {
 				output.Infof("Untagged %s:%s", repository, tag)
 			}
 
+			// Variants are removed along with their index, unless they are also tagged
+			if exists, err := localRepo.Exists(ctx, manifestDesc); err == nil && !exists {
+				continue
+			}
 			if err := localRepo.Delete(ctx, manifestDesc); err != nil {
 				output.Errorf("Failed to remove %s@%s: %s", repository, manifestDesc.Digest, err)
 				continue
@@ -87,12 +97,20 @@ func removeUntaggedModels(ctx context.Context, opts *removeOptions) error {
 	for _, localRepo := range localRepos {
 		manifests := localRepo.GetAllModels()
 		repo := util.FormatRepositoryForDisplay(localRepo.GetRepoName())
+		variants, err := variantDigests(ctx, localRepo)
+		if err != nil {
+			return err
+		}
 		for _, manifestDesc := range manifests {
 			tags := localRepo.GetTags(manifestDesc)
 			if len(tags) > 0 {
 				output.Debugf("Skipping %s (tags: %s)", manifestDesc.Digest, strings.Join(tags, ", "))
 				continue
 			}
+			if variants[manifestDesc.Digest] {
+				output.Debugf("Skipping %s (variant in index)", manifestDesc.Digest)
+				continue
+			}
 			if err := localRepo.Delete(ctx, manifestDesc); err != nil {
 				output.Errorf("Failed to remove %s@%s: %s", repo, manifestDesc.Digest, err)
 				continue
@@ -103,6 +121,32 @@ func removeUntaggedModels(ctx context.Context, opts *removeOptions) error {
 	return nil
 }
 
+func indexFirst(desc ocispec.Descriptor) int {
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		return 0
+	}
+	return 1
+}
+
+// variantDigests returns the digests of all manifests that are variants in an index in localRepo. Variants
+// are usually untagged, but are still in use as long as their index exists.
+func variantDigests(ctx context.Context, localRepo local.LocalRepo) (map[digest.Digest]bool, error) {
+	digests := map[digest.Digest]bool{}
+	for _, desc := range localRepo.GetAllModels() {
+		if desc.MediaType != ocispec.MediaTypeImageIndex {
+			continue
+		}
+		index, err := util.GetIndex(ctx, localRepo, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index %s: %w", desc.Digest, err)
+		}
+		for _, variantDesc := range index.Manifests {
+			digests[variantDesc.Digest] = true
+		}
+	}
+	return digests, nil
+}
+
 func removeModel(ctx context.Context, opts *removeOptions) error {
 	storageRoot := constants.StoragePath(opts.configHome)
 	localRepo, err := local.NewLocalRepo(storageRoot, opts.modelRef)
@@ -0,0 +1,123 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kitops-ml/kitops/pkg/lib/chunk"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// reassembleChunkedLayer returns a tar reader for a chunked layer in which entries for chunked
+// files are replaced with the files' contents, reassembled from chunks fetched from store. This
+// allows chunked layers to be handled the same way as regular layers. The returned function must
+// be called to release resources once the reader is no longer needed.
+func reassembleChunkedLayer(ctx context.Context, store content.Fetcher, tr *tar.Reader, logger *output.ProgressLogger) (*tar.Reader, func()) {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tw := tar.NewWriter(pw)
+		err := copyReassembled(ctx, store, tr, tw, logger)
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	closeFunc := func() {
+		pr.Close()
+		<-done
+	}
+	return tar.NewReader(pr), closeFunc
+}
+
+func copyReassembled(ctx context.Context, store content.Fetcher, tr *tar.Reader, tw *tar.Writer, logger *output.ProgressLogger) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		version, isChunked := header.PAXRecords[chunk.PAXRecordKey]
+		if !isChunked {
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if version != chunk.ManifestVersion {
+			return fmt.Errorf("unsupported chunk manifest version %s for %s", version, header.Name)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("invalid chunked file %s: not a regular file", header.Name)
+		}
+		manifest, err := chunk.ReadManifest(tr)
+		if err != nil {
+			return fmt.Errorf("invalid chunk manifest for %s: %w", header.Name, err)
+		}
+		delete(header.PAXRecords, chunk.PAXRecordKey)
+		header.Size = manifest.Size
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		logger.Debugf("Reassembling %s from %d chunks", header.Name, len(manifest.Chunks))
+		fileVerifier := manifest.Digest.Verifier()
+		for _, c := range manifest.Chunks {
+			if err := copyChunk(ctx, store, c, io.MultiWriter(tw, fileVerifier)); err != nil {
+				return fmt.Errorf("failed to reassemble %s: %w", header.Name, err)
+			}
+		}
+		if !fileVerifier.Verified() {
+			return fmt.Errorf("failed to reassemble %s: content does not match digest", header.Name)
+		}
+	}
+}
+
+func copyChunk(ctx context.Context, store content.Fetcher, c chunk.Chunk, w io.Writer) error {
+	desc := ocispec.Descriptor{
+		MediaType: constants.ChunkMediaType.String(),
+		Digest:    c.Digest,
+		Size:      c.Size,
+	}
+	rc, err := store.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("failed to get chunk %s: %w", c.Digest, err)
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, desc)
+	if _, err := io.Copy(w, vr); err != nil {
+		return fmt.Errorf("failed to read chunk %s: %w", c.Digest, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("failed to verify chunk %s: %w", c.Digest, err)
+	}
+	return nil
+}
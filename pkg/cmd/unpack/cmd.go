@@ -17,17 +17,22 @@
 package unpack
 
 import (
+	"archive/tar"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/kitops-ml/kitops/pkg/cmd/options"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"oras.land/oras-go/v2/registry"
 )
 
@@ -43,6 +48,9 @@ ensures that the necessary components are always available for unpacking,
 optimizing for efficiency by fetching only specified components from the
 remote registry when necessary.
 
+Use --cache to pull the modelkit into local storage before unpacking it, or
+--no-cache to always stream it from the remote registry.
+
 The content that is unpacked can be limited via the --filter (-f) flag. For example,
 use
     --filter=model
@@ -56,10 +64,15 @@ where [types] is a comma-separated list of Kitfile fields (kitfile, model, datas
 code, or docs) and [filters] is an optional comma-separated list of additional filters
 to apply, which are matched against the Kitfile to further restrict what is extracted.
 Additional filters match elements of the Kitfile on either the name (if present) or
-the path used.
+the path used; if empty, all elements of the given types match. For datasets with
+splits, a list of splits can be appended, e.g. --filter=datasets:my-dataset:train.
 
 The filter field can be specified multiple times. A layer will be unpacked if it matches
-any of the specified filters`
+any of the specified filters
+
+Use --sync (and --prune) to update a previously unpacked directory, --output to
+write a tar archive instead, --link-from-cache to share unpacked files between
+directories, and --variant to select a variant of a modelkit.`
 
 	example = `# Unpack all components of a modelkit to the current directory
 kit unpack myrepo/my-model:latest -d /path/to/unpacked
@@ -76,8 +89,29 @@ kit unpack myrepo/my-model:latest --filter=docs:./README.md
 # Unpack the model and the dataset named "validation"
 kit unpack myrepo/my-model:latest --filter=model --filter=datasets:validation
 
+# Unpack only the "train" split of the dataset named "my-dataset"
+kit unpack myrepo/my-model:latest --filter=datasets:my-dataset:train
+
 # Unpack a modelkit from a remote registry with overwrite enabled
-kit unpack registry.example.com/myrepo/my-model:latest -o -d /path/to/unpacked`
+kit unpack registry.example.com/myrepo/my-model:latest -o -d /path/to/unpacked
+
+# Unpack a modelkit straight from a remote registry without saving it in local storage
+kit unpack registry.example.com/myrepo/my-model:latest --no-cache -d /path/to/unpacked
+
+# Pull a modelkit into local storage and unpack it
+kit unpack registry.example.com/myrepo/my-model:latest --cache -d /path/to/unpacked
+
+# Update a previously unpacked modelkit, removing files that are no longer present
+kit unpack myrepo/my-model:latest --sync --prune -d /path/to/unpacked
+
+# Stream the model from a modelkit as a tar archive into a running container
+kit unpack myrepo/my-model:latest --output - --filter=model | docker cp - mycontainer:/models
+
+# Unpack a modelkit, sharing extracted files with other directories it is unpacked to
+kit unpack myrepo/my-model:latest --link-from-cache -d /path/to/unpacked
+
+# Unpack the variant of a modelkit quantized to 4 bits
+kit unpack myrepo/my-model:latest --variant quantization=q4 -d /path/to/unpacked`
 )
 
 type unpackOptions struct {
@@ -88,8 +122,21 @@ type unpackOptions struct {
 	filterConfs    []filterConf
 	unpackConf     unpackConf
 	modelRef       *registry.Reference
+	variant        string
 	overwrite      bool
 	ignoreExisting bool
+	tarOutput      string
+	tarWriter      *tar.Writer
+	tarLinks       archiveLinks
+	linkFromCache  bool
+	cache          bool
+	noCache        bool
+	sync           bool
+	prune          bool
+	syncState      *syncState
+	// lock pins referenced modelkits to digests, and is read from the first modelkit in a chain of
+	// references that was packed with one
+	lock *kfutils.Lock
 }
 
 // unpackConf configures which elements of the modelkit should be unpacked.
@@ -133,6 +180,29 @@ func (opts *unpackOptions) complete(ctx context.Context, args []string) error {
 		}
 	}
 
+	if opts.tarOutput != "" && opts.unpackDir != "" {
+		return fmt.Errorf("--dir cannot be used with --output")
+	}
+	if opts.tarOutput != "" && opts.linkFromCache {
+		return fmt.Errorf("--link-from-cache cannot be used with --output")
+	}
+	if opts.cache && opts.noCache {
+		return fmt.Errorf("--cache cannot be used with --no-cache")
+	}
+	if opts.noCache && opts.modelRef.Registry == util.DefaultRegistry {
+		return fmt.Errorf("--no-cache requires a reference to a modelkit in a remote registry")
+	}
+	if opts.sync {
+		if opts.overwrite || opts.ignoreExisting {
+			return fmt.Errorf("--sync cannot be used with --overwrite or --ignore-existing")
+		}
+		if opts.tarOutput != "" || opts.linkFromCache {
+			return fmt.Errorf("--sync cannot be used with --output or --link-from-cache")
+		}
+	} else if opts.prune {
+		return fmt.Errorf("--prune can only be used with --sync")
+	}
+
 	absDir, err := filepath.Abs(opts.unpackDir)
 	if err != nil {
 		return fmt.Errorf("failed to resolve absolute path %s: %w", opts.unpackDir, err)
@@ -162,6 +232,13 @@ func UnpackCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&opts.unpackDir, "dir", "d", "", "The target directory to unpack components into. This directory will be created if it does not exist")
 	cmd.Flags().BoolVarP(&opts.overwrite, "overwrite", "o", false, "Overwrites existing files and directories in the target unpack directory without prompting")
 	cmd.Flags().BoolVarP(&opts.ignoreExisting, "ignore-existing", "i", false, "Skip unpacking files if a file with that name already exists")
+	cmd.Flags().BoolVar(&opts.sync, "sync", false, "Compare existing files with the modelkit and only rewrite files that differ")
+	cmd.Flags().BoolVar(&opts.prune, "prune", false, "When used with --sync, remove files within unpacked paths that are not in the modelkit")
+	cmd.Flags().StringVar(&opts.tarOutput, "output", "", "Write unpacked components to a tar archive at the given path instead of a directory (use \"-\" for standard output)")
+	cmd.Flags().BoolVar(&opts.linkFromCache, "link-from-cache", false, "Extract layers once into a shared cache and link files from it instead of copying them")
+	cmd.Flags().BoolVar(&opts.cache, "cache", false, "Pull the modelkit into local storage before unpacking if it is not already present")
+	cmd.Flags().BoolVar(&opts.noCache, "no-cache", false, "Stream components directly from the remote registry without using or updating local storage")
+	cmd.Flags().StringVar(&opts.variant, "variant", "", "Select the variant to unpack by name or key=value annotations when the reference contains variants")
 	cmd.Flags().StringArrayVarP(&opts.filters, "filter", "f", []string{}, "Filter what is unpacked from the modelkit based on type and name. Can be specified multiple times")
 	cmd.Flags().BoolVar(&opts.unpackConf.unpackKitfile, "kitfile", false, "Unpack only Kitfile (deprecated: use --filter=kitfile)")
 	cmd.Flags().BoolVar(&opts.unpackConf.unpackModels, "model", false, "Unpack only model (deprecated: use --filter=model)")
@@ -184,6 +261,13 @@ func runCommand(opts *unpackOptions) func(*cobra.Command, []string) error {
 			return output.Fatalf("Invalid reference: unpacking requires a tag or digest")
 		}
 
+		if opts.tarOutput != "" {
+			if err := runUnpackToTar(cmd, opts); err != nil {
+				return output.Fatalln(err)
+			}
+			return nil
+		}
+
 		unpackTo := opts.unpackDir
 		if unpackTo == "" {
 			unpackTo = "current directory"
@@ -207,6 +291,41 @@ func runCommand(opts *unpackOptions) func(*cobra.Command, []string) error {
 	}
 }
 
+// runUnpackToTar unpacks the modelkit as a single tar archive written to the path specified by
+// opts.tarOutput, or standard output if the path is "-".
+func runUnpackToTar(cmd *cobra.Command, opts *unpackOptions) (err error) {
+	var w io.Writer
+	if opts.tarOutput == "-" {
+		if f, ok := cmd.OutOrStdout().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			return fmt.Errorf("refusing to write tar archive to a terminal")
+		}
+		w = cmd.OutOrStdout()
+		// Keep standard output clean for the archive
+		output.SetOut(cmd.ErrOrStderr())
+		output.SetProgressBars("none")
+	} else {
+		f, err := os.Create(opts.tarOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		w = f
+		output.Infof("Unpacking to %s", opts.tarOutput)
+	}
+
+	opts.tarWriter = tar.NewWriter(w)
+	opts.tarLinks = archiveLinks{}
+	if err := runUnpack(cmd.Context(), opts); err != nil {
+		return err
+	}
+	if err := opts.tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write tar archive: %w", err)
+	}
+	return nil
+}
+
 func printConfig(opts *unpackOptions) {
 	output.Debugf("Overwrite: %t", opts.overwrite)
 	output.Debugf("Unpacking %s", opts.modelRef.String())
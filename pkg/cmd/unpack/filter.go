@@ -18,6 +18,8 @@ package unpack
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
@@ -29,6 +31,9 @@ import (
 type filterConf struct {
 	baseTypes []string
 	filters   []string
+	// splits restricts datasets to the splits with these names. Datasets that do not define any
+	// of the splits are not matched.
+	splits []string
 }
 
 func (fc *filterConf) matches(baseType, field string) bool {
@@ -56,11 +61,25 @@ func (fc *filterConf) matchesField(field string) bool {
 	return slices.Contains(fc.filters, field)
 }
 
+// matchesDataset returns true if the dataset matches the filter by name or path and, if the filter
+// selects splits, the dataset defines at least one of them.
+func (fc *filterConf) matchesDataset(dataset artifact.DataSet) bool {
+	if !fc.matches(constants.DatasetType, dataset.Name) && !fc.matches(constants.DatasetType, dataset.Path) {
+		return false
+	}
+	if len(fc.splits) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(dataset.Splits, func(split artifact.DatasetSplit) bool {
+		return slices.Contains(fc.splits, split.Name)
+	})
+}
+
 func parseFilter(filter string) (*filterConf, error) {
 	typesAndIds := strings.Split(filter, ":")
 
-	if len(typesAndIds) > 2 {
-		return nil, fmt.Errorf("invalid filter: should be in format <type1>,<type2>[:<filter1>,<filter2>]")
+	if len(typesAndIds) > 3 {
+		return nil, fmt.Errorf("invalid filter: should be in format <type1>,<type2>[:<filter1>,<filter2>[:<split1>,<split2>]]")
 	}
 
 	conf := &filterConf{}
@@ -78,8 +97,21 @@ func parseFilter(filter string) (*filterConf, error) {
 		return conf, nil
 	}
 
-	filters := strings.Split(typesAndIds[1], ",")
-	conf.filters = filters
+	// An empty list of filters (e.g. 'datasets::train') matches all elements
+	if typesAndIds[1] != "" {
+		conf.filters = strings.Split(typesAndIds[1], ",")
+	}
+	if len(typesAndIds) == 2 {
+		return conf, nil
+	}
+
+	if len(conf.baseTypes) != 1 || conf.baseTypes[0] != constants.DatasetType {
+		return nil, fmt.Errorf("invalid filter %s: splits can only be selected for datasets", filter)
+	}
+	if typesAndIds[2] == "" {
+		return nil, fmt.Errorf("invalid filter %s: no splits specified", filter)
+	}
+	conf.splits = strings.Split(typesAndIds[2], ",")
 	return conf, nil
 }
 
@@ -112,7 +144,9 @@ func shouldUnpackLayer(layer any, filters []filterConf) bool {
 		// Docs does not have an ID/name field so we can only match on path
 		return matchesFilters(l.Path, constants.DocsType, filters)
 	case artifact.DataSet:
-		return matchesFilters(l.Name, constants.DatasetType, filters) || matchesFilters(l.Path, constants.DatasetType, filters)
+		return slices.ContainsFunc(filters, func(filter filterConf) bool {
+			return filter.matchesDataset(l)
+		})
 	case artifact.Code:
 		// Code does not have a ID/name field so we can only match on path
 		return matchesFilters(l.Path, constants.CodeType, filters)
@@ -121,6 +155,54 @@ func shouldUnpackLayer(layer any, filters []filterConf) bool {
 	}
 }
 
+// splitFilter selects the files within a dataset layer that belong to specific splits of the dataset. A nil
+// *splitFilter selects all files.
+type splitFilter struct {
+	names []string
+	// patterns are the glob patterns for the paths of the selected splits, using forward slashes
+	patterns []string
+}
+
+// splitFilterForDataset returns a splitFilter for the splits of dataset selected by filters. If the whole
+// dataset is selected, nil is returned.
+func splitFilterForDataset(dataset artifact.DataSet, filters []filterConf) *splitFilter {
+	var names, patterns []string
+	for _, filter := range filters {
+		if !filter.matchesDataset(dataset) {
+			continue
+		}
+		if len(filter.splits) == 0 {
+			return nil
+		}
+		for _, split := range dataset.Splits {
+			if slices.Contains(filter.splits, split.Name) && !slices.Contains(names, split.Name) {
+				names = append(names, split.Name)
+				patterns = append(patterns, path.Clean(filepath.ToSlash(split.Path)))
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &splitFilter{names: names, patterns: patterns}
+}
+
+// includes returns true if file, relative to the unpack directory, matches the path of a selected split
+// or is within a directory that does.
+func (sf *splitFilter) includes(file string) bool {
+	if sf == nil {
+		return true
+	}
+	for p := path.Clean(filepath.ToSlash(file)); p != "." && p != "/"; p = path.Dir(p) {
+		for _, pattern := range sf.patterns {
+			if matched, _ := path.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func matchesFilters(field string, baseType string, filterConfs []filterConf) bool {
 	for _, filterConf := range filterConfs {
 		if filterConf.matches(baseType, field) {
@@ -0,0 +1,199 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem/cache"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// unpackLayerFromCache unpacks a layer by extracting it to the shared unpacked layer cache, if it is not
+// already present, and then linking its files into the current directory. Files are linked using reflinks
+// where supported, falling back to hard links and finally symlinks. The whole layer is always cached, but if
+// split is not nil, only files within the selected dataset splits are linked.
+func unpackLayerFromCache(ctx context.Context, store content.Storage, desc ocispec.Descriptor, diffId digest.Digest, unpackPath string, overwrite, ignoreExisting bool, split *splitFilter, compression string) error {
+	layerDir, err := ensureLayerInCache(ctx, store, desc, diffId, compression)
+	if err != nil {
+		return err
+	}
+
+	targetDir := "."
+	if unpackPath != "" {
+		targetDir = filepath.Dir(unpackPath)
+		if err := os.MkdirAll(targetDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", targetDir, err)
+		}
+	}
+	return linkFromCache(layerDir, targetDir, overwrite, ignoreExisting, split)
+}
+
+// ensureLayerInCache returns the directory containing the extracted contents of a layer, extracting
+// the layer into the unpacked layer cache first if necessary.
+func ensureLayerInCache(ctx context.Context, store content.Storage, desc ocispec.Descriptor, diffId digest.Digest, compression string) (string, error) {
+	layerDir := cache.UnpackedLayerDir(diffId)
+	if fi, err := os.Stat(layerDir); err == nil && fi.IsDir() {
+		output.Infof("Found layer %s in unpacked layer cache", diffId)
+		return layerDir, nil
+	}
+
+	// Extract to a temporary directory and move it into place once done, so that partially
+	// extracted layers are never visible in the cache
+	tempDir, cleanup, err := cache.MkUnpackedTempDir()
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	tr, logger, closeLayer, err := openLayer(ctx, store, desc, compression)
+	if err != nil {
+		return "", err
+	}
+	defer closeLayer()
+	if err := extractTar(tr, tempDir, "", false, false, nil, nil, logger); err != nil {
+		return "", err
+	}
+	logger.Wait()
+
+	if err := os.MkdirAll(filepath.Dir(layerDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.Rename(tempDir, layerDir); err != nil {
+		// Another process may have extracted the same layer concurrently
+		if fi, statErr := os.Stat(layerDir); statErr == nil && fi.IsDir() {
+			return layerDir, nil
+		}
+		return "", fmt.Errorf("failed to move layer into unpacked layer cache: %w", err)
+	}
+	output.Debugf("Saved layer %s to unpacked layer cache", diffId)
+	return layerDir, nil
+}
+
+// linkFromCache recreates the file tree in layerDir within targetDir, linking regular files to their
+// counterparts in layerDir. If split is not nil, only paths within the selected dataset splits are linked.
+func linkFromCache(layerDir, targetDir string, overwrite, ignoreExisting bool, split *splitFilter) error {
+	links := diskPaths(".")
+	return filepath.WalkDir(layerDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(layerDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		outPath := filepath.Join(targetDir, rel)
+		if !split.includes(outPath) {
+			// Directories are still walked, as they may contain paths in the selected splits
+			return nil
+		}
+		if err := checkParentDirs(outPath, links); err != nil {
+			return fmt.Errorf("illegal file path: %s: %w", outPath, err)
+		}
+		if split != nil && !d.IsDir() {
+			if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(outPath), err)
+			}
+		}
+
+		switch {
+		case d.IsDir():
+			if fi, exists := filesystem.PathExists(outPath); exists {
+				if !fi.IsDir() {
+					return fmt.Errorf("path '%s' already exists and is not a directory", outPath)
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			output.Debugf("Creating directory %s", outPath)
+			if err := os.MkdirAll(outPath, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", outPath, err)
+			}
+
+		case d.Type()&fs.ModeSymlink != 0:
+			// Symlinks in the cache were validated when extracted and are relative to the layer, so
+			// they can be copied as-is.
+			linkTarget, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			if skip, err := prepareLinkPath(outPath, overwrite, ignoreExisting, nil); err != nil {
+				return err
+			} else if skip {
+				return nil
+			}
+			output.Debugf("Creating symlink %s -> %s", outPath, linkTarget)
+			if err := os.Symlink(linkTarget, outPath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", outPath, err)
+			}
+
+		case d.Type().IsRegular():
+			if skip, err := prepareLinkPath(outPath, overwrite, ignoreExisting, nil); err != nil {
+				return err
+			} else if skip {
+				return nil
+			}
+			if err := linkCachedFile(path, outPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// linkCachedFile links src to dst using the cheapest method available: a reflink if the filesystem
+// supports it, then a hard link, then a symlink to the absolute path of src. Hard links and symlinks share
+// data with the cache, so unpacking and syncing replace existing files instead of writing to them.
+func linkCachedFile(src, dst string) error {
+	if err := filesystem.Reflink(src, dst); err == nil {
+		output.Debugf("Reflinked %s from cache", dst)
+		return nil
+	} else if !errors.Is(err, filesystem.ErrReflinkUnsupported) {
+		output.Debugf("Failed to reflink %s from cache: %s", dst, err)
+	}
+	if err := os.Link(src, dst); err == nil {
+		output.Debugf("Hard linked %s from cache", dst)
+		return nil
+	} else {
+		output.Debugf("Failed to hard link %s from cache: %s", dst, err)
+	}
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %s: %w", src, err)
+	}
+	if err := os.Symlink(absSrc, dst); err != nil {
+		return fmt.Errorf("failed to link %s from cache: %w", dst, err)
+	}
+	output.Debugf("Symlinked %s from cache", dst)
+	return nil
+}
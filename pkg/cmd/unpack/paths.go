@@ -0,0 +1,210 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymlinks limits the number of symlinks followed when resolving a path, to avoid symlink loops
+const maxSymlinks = 255
+
+// pathKind describes what exists at a path within the unpack directory.
+type pathKind int
+
+const (
+	pathMissing pathKind = iota
+	pathDir
+	pathFile
+	pathSymlink
+)
+
+// pathReader reports what exists at a path relative to the unpack directory and, if it is a symlink,
+// the symlink's target.
+type pathReader func(relPath string) (kind pathKind, target string, err error)
+
+// diskPaths returns a pathReader for paths that exist on disk within rootDir.
+func diskPaths(rootDir string) pathReader {
+	return func(relPath string) (pathKind, string, error) {
+		fullPath := filepath.Join(rootDir, relPath)
+		fi, err := os.Lstat(fullPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return pathMissing, "", nil
+		} else if err != nil {
+			return pathMissing, "", fmt.Errorf("failed to check path %s: %w", fullPath, err)
+		}
+		switch {
+		case fi.IsDir():
+			return pathDir, "", nil
+		case fi.Mode()&fs.ModeSymlink == 0:
+			return pathFile, "", nil
+		}
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return pathMissing, "", fmt.Errorf("failed to read symlink %s: %w", fullPath, err)
+		}
+		return pathSymlink, target, nil
+	}
+}
+
+// resolveInRoot resolves relPath one component at a time, following symlinks reported by readPath, and
+// returns the resolved path relative to the unpack directory. An error is returned if the path, or any
+// symlink along it, leaves the unpack directory. relPath is not cleaned first so that '..' following a
+// symlink is applied to the symlink's target, as it would be by the operating system.
+//
+// A '..' is only accepted after an existing directory: if it followed a path that does not exist yet, a
+// symlink created at that path later could change where the '..' leads.
+func resolveInRoot(relPath string, readPath pathReader) (string, error) {
+	var resolved string
+	// Whether resolved is an existing directory; the root of the unpack directory always is
+	inDir := true
+	remaining := filepath.ToSlash(relPath)
+	followed := 0
+	for remaining != "" {
+		var part string
+		part, remaining, _ = strings.Cut(remaining, "/")
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "" {
+				return "", fmt.Errorf("path %s leaves the unpack directory", relPath)
+			}
+			if !inDir {
+				return "", fmt.Errorf("path %s uses '..' after %s, which is not an existing directory", relPath, filepath.FromSlash(resolved))
+			}
+			if resolved = path.Dir(resolved); resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+		next := path.Join(resolved, part)
+		kind, target, err := readPath(filepath.FromSlash(next))
+		if err != nil {
+			return "", err
+		}
+		if kind != pathSymlink {
+			resolved = next
+			inDir = kind == pathDir
+			continue
+		}
+		if followed += 1; followed > maxSymlinks {
+			return "", fmt.Errorf("too many levels of symlinks in path %s", relPath)
+		}
+		if filepath.IsAbs(target) || path.IsAbs(filepath.ToSlash(target)) {
+			return "", fmt.Errorf("path %s leaves the unpack directory through symlink %s", relPath, next)
+		}
+		// Relative symlink targets are resolved from the directory containing the symlink
+		remaining = filepath.ToSlash(target) + "/" + remaining
+	}
+	return filepath.FromSlash(resolved), nil
+}
+
+// checkParentDirs returns an error if any parent directory of relPath is a symlink. Writing through a
+// symlinked directory could place files outside the unpack directory even though relPath is within it.
+func checkParentDirs(relPath string, readPath pathReader) error {
+	dir := filepath.Dir(filepath.Clean(relPath))
+	if dir == "." {
+		return nil
+	}
+	var current string
+	for _, part := range strings.Split(filepath.ToSlash(dir), "/") {
+		current = filepath.Join(current, part)
+		if kind, _, err := readPath(current); err != nil {
+			return err
+		} else if kind == pathSymlink {
+			return fmt.Errorf("parent directory %s is a symlink", current)
+		}
+	}
+	return nil
+}
+
+// archiveLinks records the paths written to an archive, keyed by cleaned path, so that entries can be
+// checked as extractTar would check them without extracting the archive.
+type archiveLinks map[string]archivePath
+
+// archivePath is a path already written to an archive.
+type archivePath struct {
+	kind   pathKind
+	target string
+}
+
+func (l archiveLinks) read(relPath string) (pathKind, string, error) {
+	entry, ok := l[relPath]
+	if !ok {
+		return pathMissing, "", nil
+	}
+	return entry.kind, entry.target, nil
+}
+
+// verifyEntry checks that a tar entry would stay within the directory it is extracted to, applying the
+// same checks to paths and link targets as extractTar, and records it. Directories are recorded for
+// parent paths that have no entry of their own, as extracting the entry creates them.
+func (l archiveLinks) verifyEntry(header *tar.Header) error {
+	relPath := filepath.FromSlash(header.Name)
+	if filepath.IsAbs(relPath) || !filepath.IsLocal(relPath) {
+		return fmt.Errorf("illegal file path: %s: paths must stay within the unpack directory", header.Name)
+	}
+	relPath = filepath.Clean(relPath)
+	if err := checkParentDirs(relPath, l.read); err != nil {
+		return fmt.Errorf("illegal file path: %s: %w", header.Name, err)
+	}
+	for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+		if existing, ok := l[dir]; !ok {
+			l[dir] = archivePath{kind: pathDir}
+		} else if existing.kind != pathDir {
+			return fmt.Errorf("illegal file path: %s: parent %s is not a directory", header.Name, dir)
+		}
+	}
+	// Directories are never replaced when extracting, so paths already resolved through them stay valid
+	if existing, ok := l[relPath]; ok && existing.kind == pathDir && header.Typeflag != tar.TypeDir {
+		return fmt.Errorf("path %s already exists and is a directory", header.Name)
+	}
+	switch header.Typeflag {
+	case tar.TypeDir:
+		l[relPath] = archivePath{kind: pathDir}
+	case tar.TypeSymlink:
+		if filepath.IsAbs(header.Linkname) {
+			return fmt.Errorf("illegal symlink %s: absolute target %s is not supported", header.Name, header.Linkname)
+		}
+		delete(l, relPath)
+		linkTarget := filepath.Dir(relPath) + string(filepath.Separator) + filepath.FromSlash(header.Linkname)
+		if _, err := resolveInRoot(linkTarget, l.read); err != nil {
+			return fmt.Errorf("illegal symlink %s -> %s: %w", header.Name, header.Linkname, err)
+		}
+		l[relPath] = archivePath{kind: pathSymlink, target: header.Linkname}
+	case tar.TypeLink:
+		linkRelPath := filepath.FromSlash(header.Linkname)
+		if filepath.IsAbs(linkRelPath) || !filepath.IsLocal(linkRelPath) {
+			return fmt.Errorf("illegal hard link %s -> %s: target must stay within the unpack directory", header.Name, header.Linkname)
+		}
+		if err := checkParentDirs(linkRelPath, l.read); err != nil {
+			return fmt.Errorf("illegal hard link %s -> %s: %w", header.Name, header.Linkname, err)
+		}
+		l[relPath] = archivePath{kind: pathFile}
+	default:
+		l[relPath] = archivePath{kind: pathFile}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// streamConfig writes the Kitfile for a modelkit to tw as a regular file.
+func streamConfig(config *artifact.KitFile, tw *tar.Writer) error {
+	configBytes, err := config.MarshalToYAML()
+	if err != nil {
+		return fmt.Errorf("failed to unpack config: %w", err)
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     constants.DefaultKitfileName,
+		Mode:     0644,
+		Size:     int64(len(configBytes)),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if _, err := tw.Write(configBytes); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// streamLayer copies all entries in a layer into tw, decompressing the layer if necessary. If
+// unpackPath is not empty, entries are placed relative to its parent directory, matching the
+// behavior of unpackLayer for older layer formats. If split is not nil, only entries within the
+// selected dataset splits are copied. Entries are checked as they would be by extractTar, using
+// links to track symlinks already written to tw.
+func streamLayer(ctx context.Context, store content.Storage, desc ocispec.Descriptor, unpackPath string, tw *tar.Writer, links archiveLinks, split *splitFilter, compression string) error {
+	tr, logger, closeLayer, err := openLayer(ctx, store, desc, compression)
+	if err != nil {
+		return err
+	}
+	defer closeLayer()
+
+	var prefix string
+	if unpackPath != "" {
+		prefix = filepath.ToSlash(filepath.Dir(unpackPath))
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if prefix != "" {
+			header.Name = path.Join(prefix, header.Name)
+			if header.Typeflag == tar.TypeLink {
+				header.Linkname = path.Join(prefix, header.Linkname)
+			}
+		}
+		if !split.includes(header.Name) {
+			continue
+		}
+		if header.Typeflag == tar.TypeLink && !split.includes(header.Linkname) {
+			output.Logf(output.LogLevelWarn, "Skipping hard link %s: target %s is not in the selected splits", header.Name, header.Linkname)
+			continue
+		}
+		if err := links.verifyEntry(header); err != nil {
+			return err
+		}
+		logger.Debugf("Writing %s to output", header.Name)
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write header for %s: %w", header.Name, err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return fmt.Errorf("failed to write %s: %w", header.Name, err)
+		}
+	}
+
+	logger.Wait()
+	return nil
+}
@@ -0,0 +1,289 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+// syncState tracks the result of unpacking in sync mode, where existing files are compared against
+// the modelkit's contents and only rewritten if they differ.
+type syncState struct {
+	// paths contains every path (relative to the unpack directory) that is part of the modelkit
+	paths map[string]bool
+	// layerPaths contains the Kitfile paths for all unpacked layers, used for pruning
+	layerPaths []string
+	// splits contains the selected splits for dataset layers where only some splits were unpacked,
+	// keyed by layer path. Files in other splits are never pruned.
+	splits map[string]*splitFilter
+	// kitfileLayerPaths contains the paths for all layers in the Kitfile, including those that
+	// are not unpacked due to filters. Paths that belong to layers that were not unpacked are
+	// never pruned.
+	kitfileLayerPaths []string
+	created           int
+	updated           int
+	unchanged         int
+	removed           int
+}
+
+func newSyncState() *syncState {
+	return &syncState{paths: map[string]bool{}, splits: map[string]*splitFilter{}}
+}
+
+func (s *syncState) addPath(path string) {
+	s.paths[filepath.Clean(path)] = true
+}
+
+func (s *syncState) addLayerPath(path string, split *splitFilter) {
+	path = filepath.Clean(path)
+	if !slices.Contains(s.layerPaths, path) {
+		s.layerPaths = append(s.layerPaths, path)
+	}
+	if split != nil {
+		s.splits[path] = split
+	}
+}
+
+func (s *syncState) addKitfile(config *artifact.KitFile) {
+	s.kitfileLayerPaths = append(s.kitfileLayerPaths, util.LayerPathsFromKitfile(config)...)
+}
+
+// isSkipped returns true if path belongs to a layer that was not unpacked
+func (s *syncState) isSkipped(path, layerPath string) bool {
+	for _, skipped := range s.kitfileLayerPaths {
+		if slices.Contains(s.layerPaths, skipped) || isWithin(layerPath, skipped) {
+			// The skipped layer contains the current layer; files within layerPath are
+			// still part of the current layer
+			continue
+		}
+		if isWithin(path, skipped) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *syncState) printSummary() {
+	output.Infof("Sync complete: %d created, %d updated, %d unchanged, %d removed", s.created, s.updated, s.unchanged, s.removed)
+}
+
+// syncExistingFile handles a regular file entry in sync mode. If a regular file already exists at
+// outPath, it is synced with the entry and done is true. Otherwise, any conflicting file is removed so
+// that the entry can be unpacked as a new file.
+func syncExistingFile(outPath string, header *tar.Header, r io.Reader, sync *syncState) (done bool, err error) {
+	fi, err := os.Lstat(outPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			sync.created += 1
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check path %s: %w", outPath, err)
+	}
+	if fi.IsDir() {
+		return false, fmt.Errorf("path '%s' already exists and is a directory", outPath)
+	}
+	if !fi.Mode().IsRegular() {
+		if err := os.Remove(outPath); err != nil {
+			return false, fmt.Errorf("failed to remove existing file %s: %w", outPath, err)
+		}
+		sync.updated += 1
+		return false, nil
+	}
+
+	updated, err := syncFile(outPath, fi, header, r)
+	if err != nil {
+		return false, err
+	}
+	if updated {
+		output.Debugf("Updated file %s", outPath)
+		sync.updated += 1
+	} else {
+		output.Debugf("File %s is unchanged", outPath)
+		sync.unchanged += 1
+	}
+	return true, nil
+}
+
+// syncFile compares the regular file at path with the content of a tar entry, replacing the file if its
+// content or permissions differ. The new content is written to a temporary file that is then renamed over
+// path, as the existing file may be read-only or linked to the unpacked layer cache. Returns true if the
+// file was replaced.
+func syncFile(path string, fi fs.FileInfo, header *tar.Header, r io.Reader) (updated bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && !errors.Is(closeErr, fs.ErrClosed) {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	// If sizes or permissions differ, there's no point in comparing contents
+	mode := header.FileInfo().Mode().Perm()
+	updated = fi.Size() != header.Size || fi.Mode().Perm() != mode
+	entryBuf := make([]byte, 32*1024)
+	fileBuf := make([]byte, len(entryBuf))
+	// matched is the length of the prefix of the file that is identical to the entry, and pending holds
+	// the part of the entry that was read when the first difference was found
+	var matched int64
+	var pending []byte
+	for !updated {
+		n, readErr := io.ReadFull(r, entryBuf)
+		if n > 0 {
+			m, _ := io.ReadFull(file, fileBuf[:n])
+			if m == n && bytes.Equal(entryBuf[:n], fileBuf[:n]) {
+				matched += int64(n)
+			} else {
+				updated = true
+				pending = entryBuf[:n]
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return false, fmt.Errorf("failed to read archive: %w", readErr)
+		}
+	}
+	if !updated {
+		if matched != header.Size {
+			return false, fmt.Errorf("could not unpack file %s", path)
+		}
+		return false, nil
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".sync-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+	// The part of the file that matched the entry is copied from the existing file, as it has already
+	// been read from the archive
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	if _, err := io.CopyN(tmpFile, file, matched); err != nil {
+		return false, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	if _, err := tmpFile.Write(pending); err != nil {
+		return false, fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	written, err := io.Copy(tmpFile, r)
+	if err != nil {
+		return false, fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if matched+int64(len(pending))+written != header.Size {
+		return false, fmt.Errorf("could not unpack file %s", path)
+	}
+	if err := tmpFile.Chmod(mode); err != nil {
+		return false, fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return false, fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	// The existing file must be closed before it can be replaced on Windows
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("failed to close file %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return false, fmt.Errorf("failed to replace file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// pruneLayerPaths removes files and directories within the unpacked layer paths that are not part of
+// the modelkit. Directories are only removed if they are empty.
+func pruneLayerPaths(state *syncState) error {
+	for _, layerPath := range state.layerPaths {
+		fi, err := os.Lstat(layerPath)
+		if err != nil || !fi.IsDir() {
+			// Single-file layers cannot contain extra files
+			continue
+		}
+		var extraDirs []string
+		err = filepath.WalkDir(layerPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			path = filepath.Clean(path)
+			if state.isSkipped(path, layerPath) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !state.splits[layerPath].includes(path) {
+				return nil
+			}
+			if state.paths[path] {
+				return nil
+			}
+			if d.IsDir() {
+				extraDirs = append(extraDirs, path)
+				return nil
+			}
+			output.Debugf("Removing %s: not present in modelkit", path)
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			state.removed += 1
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Remove directories deepest-first so that parents are empty when reached
+		slices.Reverse(extraDirs)
+		for _, dir := range extraDirs {
+			if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+				output.Debugf("Removing empty directory %s: not present in modelkit", dir)
+				if err := os.Remove(dir); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", dir, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isWithin returns true if path is equal to or a subpath of parent
+func isWithin(path, parent string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || filepath.IsLocal(rel)
+}
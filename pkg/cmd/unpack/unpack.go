@@ -31,9 +31,11 @@ import (
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
 	"github.com/kitops-ml/kitops/pkg/output"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 )
@@ -42,7 +44,21 @@ import (
 // unpacking fails, or if any path specified in the modelkit is not a subdirectory of the current
 // unpack target directory.
 func runUnpack(ctx context.Context, opts *unpackOptions) error {
-	return runUnpackRecursive(ctx, opts, []string{})
+	if opts.sync {
+		opts.syncState = newSyncState()
+	}
+	if err := runUnpackRecursive(ctx, opts, []string{}); err != nil {
+		return err
+	}
+	if opts.syncState != nil {
+		if opts.prune {
+			if err := pruneLayerPaths(opts.syncState); err != nil {
+				return fmt.Errorf("failed to prune files: %w", err)
+			}
+		}
+		opts.syncState.printSummary()
+	}
+	return nil
 }
 
 func runUnpackRecursive(ctx context.Context, opts *unpackOptions, visitedRefs []string) error {
@@ -50,99 +66,144 @@ func runUnpackRecursive(ctx context.Context, opts *unpackOptions, visitedRefs []
 		return fmt.Errorf("reached maximum number of model references: [%s]", strings.Join(visitedRefs, "=>"))
 	}
 
-	ref := opts.modelRef
-	store, err := getStoreForRef(ctx, opts)
+	store, manifestDesc, err := getStoreForRef(ctx, opts)
 	if err != nil {
 		ref := util.FormatRepositoryForDisplay(opts.modelRef.String())
 		return fmt.Errorf("failed to find reference %s: %s", ref, err)
 	}
-	manifestDesc, err := store.Resolve(ctx, ref.Reference)
-	if err != nil {
-		return fmt.Errorf("failed to resolve reference: %w", err)
+	if name := util.VariantName(manifestDesc); name != "" {
+		output.Infof("Selected variant %s (digest %s)", name, manifestDesc.Digest)
 	}
 	manifest, config, err := util.GetManifestAndConfig(ctx, store, manifestDesc)
 	if err != nil {
 		return fmt.Errorf("failed to read model: %s", err)
 	}
-	if config.Model != nil && util.IsModelKitReference(config.Model.Path) {
-		output.Infof("Unpacking referenced modelkit %s", config.Model.Path)
-		if err := unpackParent(ctx, config.Model.Path, opts, visitedRefs); err != nil {
-			return err
+	if refs := util.ReferencesFromKitfile(config); len(refs) > 0 {
+		lock := opts.lock
+		if lock == nil {
+			lock, err = kfutils.LockFromManifest(manifest)
+			if err != nil {
+				return err
+			}
+		}
+		for _, ref := range refs {
+			switch ref.BaseType {
+			case constants.ModelType:
+				output.Infof("Unpacking referenced modelkit %s", lock.Pin(ref.Ref))
+			case constants.DatasetType:
+				dataset := config.DataSets[ref.Index]
+				if !shouldUnpackLayer(dataset, opts.filterConfs) {
+					continue
+				}
+				output.Infof("Unpacking dataset %s from referenced modelkit %s", dataset.Name, lock.Pin(ref.Ref))
+			case constants.CodeType:
+				if !shouldUnpackLayer(config.Code[ref.Index], opts.filterConfs) {
+					continue
+				}
+				output.Infof("Unpacking code from referenced modelkit %s", lock.Pin(ref.Ref))
+			}
+			if err := unpackReference(ctx, ref, lock, opts, visitedRefs); err != nil {
+				return err
+			}
 		}
 	}
 
-	if shouldUnpackLayer(config, opts.filterConfs) {
-		if err := unpackConfig(config, opts.unpackDir, opts.overwrite); err != nil {
+	if opts.tarWriter != nil {
+		// When streaming, the Kitfile for the requested modelkit is always included
+		if len(visitedRefs) == 0 {
+			if err := streamConfig(config, opts.tarWriter); err != nil {
+				return err
+			}
+		}
+	} else if shouldUnpackLayer(config, opts.filterConfs) {
+		if err := unpackConfig(config, opts.unpackDir, opts.overwrite || opts.sync); err != nil {
 			return err
 		}
 	}
+	if opts.syncState != nil {
+		opts.syncState.addPath(constants.DefaultKitfileName)
+		opts.syncState.addKitfile(config)
+	}
 
 	// Since there might be multiple datasets, etc. we need to synchronously iterate
 	// through the config's relevant field to get the correct path for unpacking
 	// We need to support older ModelKits (that were packed without diffIDs and digest
 	// in the config) for now, so we need to continue using the old structure.
-	var modelPartIdx, codeIdx, datasetIdx, docsIdx int
+	// Entries with paths that were split into multiple layers (shards) correspond to multiple
+	// consecutive layers in the manifest.
+	cursor := kfutils.NewLayerCursor(config)
+	layerCounts := map[string]int{}
 	for _, layerDesc := range manifest.Layers {
 		// This variable supports older-format tar layers (that don't include the
 		// layer path). For current ModelKits, this will be empty
 		var relPath string
 
-		// Grab path + layer info from the config object corresponding to this layer
-		var layerPath string
-		var layerInfo *artifact.LayerInfo
 		mediaType := constants.ParseMediaType(layerDesc.MediaType)
+		if mediaType.BaseType == constants.ChunkType {
+			// Chunks are read while unpacking the chunked layers that reference them
+			continue
+		}
+		// Grab path + layer info from the config object corresponding to this layer
+		entry, err := cursor.Next(mediaType.BaseType)
+		if err != nil {
+			return err
+		}
+		layerCounts[mediaType.BaseType] += 1
+		layerPath, layerInfo, shard := entry.Path, entry.LayerInfo, entry.Shard
+		// Limits the files unpacked from dataset layers if specific splits were requested
+		var split *splitFilter
 		switch mediaType.BaseType {
 		case constants.ModelType:
 			if !shouldUnpackLayer(config.Model, opts.filterConfs) {
 				continue
 			}
-			layerInfo = config.Model.LayerInfo
-			layerPath = config.Model.Path
-			output.Infof("Unpacking model %s to %s", config.Model.Name, config.Model.Path)
+			if shard == 0 {
+				output.Infof("Unpacking model %s to %s", config.Model.Name, config.Model.Path)
+			}
 
 		case constants.ModelPartType:
-			part := config.Model.Parts[modelPartIdx]
+			part := config.Model.Parts[entry.Index]
 			if !shouldUnpackLayer(part, opts.filterConfs) {
-				modelPartIdx += 1
 				continue
 			}
-			layerInfo = part.LayerInfo
-			layerPath = part.Path
-			output.Infof("Unpacking model part %s to %s", part.Name, part.Path)
-			modelPartIdx += 1
+			if shard == 0 {
+				output.Infof("Unpacking model part %s to %s", part.Name, part.Path)
+			}
 
 		case constants.CodeType:
-			codeEntry := config.Code[codeIdx]
+			codeEntry := config.Code[entry.Index]
 			if !shouldUnpackLayer(codeEntry, opts.filterConfs) {
-				codeIdx += 1
 				continue
 			}
-			layerInfo = codeEntry.LayerInfo
-			layerPath = codeEntry.Path
-			output.Infof("Unpacking code to %s", codeEntry.Path)
-			codeIdx += 1
+			if shard == 0 {
+				output.Infof("Unpacking code to %s", codeEntry.Path)
+			}
 
 		case constants.DatasetType:
-			datasetEntry := config.DataSets[datasetIdx]
+			datasetEntry := config.DataSets[entry.Index]
 			if !shouldUnpackLayer(datasetEntry, opts.filterConfs) {
-				datasetIdx += 1
 				continue
 			}
-			layerInfo = datasetEntry.LayerInfo
-			layerPath = datasetEntry.Path
-			output.Infof("Unpacking dataset %s to %s", datasetEntry.Name, datasetEntry.Path)
-			datasetIdx += 1
+			split = splitFilterForDataset(datasetEntry, opts.filterConfs)
+			if shard == 0 {
+				if split != nil {
+					output.Infof("Unpacking splits %s of dataset %s to %s", strings.Join(split.names, ", "), datasetEntry.Name, datasetEntry.Path)
+				} else {
+					output.Infof("Unpacking dataset %s to %s", datasetEntry.Name, datasetEntry.Path)
+				}
+			}
 
 		case constants.DocsType:
-			docsEntry := config.Docs[docsIdx]
+			docsEntry := config.Docs[entry.Index]
 			if !shouldUnpackLayer(docsEntry, opts.filterConfs) {
-				docsIdx += 1
 				continue
 			}
-			layerInfo = docsEntry.LayerInfo
-			layerPath = docsEntry.Path
-			output.Infof("Unpacking docs to %s", docsEntry.Path)
-			docsIdx += 1
+			if shard == 0 {
+				output.Infof("Unpacking docs to %s", docsEntry.Path)
+			}
+		}
+		if shard > 0 {
+			output.Debugf("Unpacking layer %d for %s", shard+1, layerPath)
 		}
 
 		if layerInfo != nil {
@@ -157,30 +218,60 @@ func runUnpackRecursive(ctx context.Context, opts *unpackOptions, visitedRefs []
 			}
 		}
 
-		if err := unpackLayer(ctx, store, layerDesc, relPath, opts.overwrite, opts.ignoreExisting, mediaType.Compression); err != nil {
+		if opts.tarWriter != nil {
+			if err := streamLayer(ctx, store, layerDesc, relPath, opts.tarWriter, opts.tarLinks, split, mediaType.Compression); err != nil {
+				return fmt.Errorf("failed to unpack: %w", err)
+			}
+			continue
+		}
+		if opts.linkFromCache {
+			cacheKey := layerDesc.Digest
+			if layerInfo != nil && layerInfo.DiffId != "" {
+				cacheKey = digest.Digest(layerInfo.DiffId)
+			}
+			if err := unpackLayerFromCache(ctx, store, layerDesc, cacheKey, relPath, opts.overwrite, opts.ignoreExisting, split, mediaType.Compression); err != nil {
+				return fmt.Errorf("failed to unpack: %w", err)
+			}
+			continue
+		}
+		if opts.syncState != nil {
+			opts.syncState.addLayerPath(layerPath, split)
+		}
+		if err := unpackLayer(ctx, store, layerDesc, relPath, opts.overwrite, opts.ignoreExisting, opts.syncState, split, mediaType.Compression); err != nil {
 			return fmt.Errorf("failed to unpack: %w", err)
 		}
 	}
-	output.Debugf("Unpacked %d model part layers", modelPartIdx)
-	output.Debugf("Unpacked %d code layers", codeIdx)
-	output.Debugf("Unpacked %d dataset layers", datasetIdx)
-	output.Debugf("Unpacked %d docs layers", docsIdx)
+	output.Debugf("Unpacked %d model part layers", layerCounts[constants.ModelPartType])
+	output.Debugf("Unpacked %d code layers", layerCounts[constants.CodeType])
+	output.Debugf("Unpacked %d dataset layers", layerCounts[constants.DatasetType])
+	output.Debugf("Unpacked %d docs layers", layerCounts[constants.DocsType])
 
 	return nil
 }
 
-func unpackParent(ctx context.Context, ref string, optsIn *unpackOptions, visitedRefs []string) error {
-	if idx := getIndex(visitedRefs, ref); idx != -1 {
-		cycleStr := fmt.Sprintf("[%s=>%s]", strings.Join(visitedRefs[idx:], "=>"), ref)
+// unpackReference unpacks the modelkit ref refers to, fetching it by digest if it is pinned in lock. For
+// references in the model, only the model is unpacked from the referenced modelkit; for datasets and code,
+// all datasets or code from the referenced modelkit are unpacked.
+func unpackReference(ctx context.Context, ref util.KitfileReference, lock *kfutils.Lock, optsIn *unpackOptions, visitedRefs []string) error {
+	if idx := getIndex(visitedRefs, ref.Ref); idx != -1 {
+		cycleStr := fmt.Sprintf("[%s=>%s]", strings.Join(visitedRefs[idx:], "=>"), ref.Ref)
 		return fmt.Errorf("found cycle in modelkit references: %s", cycleStr)
 	}
 
-	parentRef, _, err := util.ParseReference(ref)
+	parentRef, _, err := util.ParseReference(lock.Pin(ref.Ref))
 	if err != nil {
 		return err
 	}
 	opts := *optsIn
 	opts.modelRef = parentRef
+	opts.variant = ""
+	opts.lock = lock
+	visitedRefs = append(slices.Clone(visitedRefs), ref.Ref)
+	if ref.BaseType != constants.ModelType {
+		// The entry in the referring modelkit was already matched against the filters
+		opts.filterConfs = []filterConf{{baseTypes: []string{ref.BaseType}}}
+		return runUnpackRecursive(ctx, &opts, visitedRefs)
+	}
 	// Unpack only model, ignore code/datasets
 	if len(opts.filterConfs) == 0 {
 		modelFilter, err := parseFilter("model")
@@ -206,7 +297,7 @@ func unpackParent(ctx context.Context, ref string, optsIn *unpackOptions, visite
 		opts.filterConfs = filterConfs
 	}
 
-	return runUnpackRecursive(ctx, &opts, append(visitedRefs, ref))
+	return runUnpackRecursive(ctx, &opts, visitedRefs)
 }
 
 func unpackConfig(config *artifact.KitFile, unpackDir string, overwrite bool) error {
@@ -243,14 +334,40 @@ func unpackConfig(config *artifact.KitFile, unpackDir string, overwrite bool) er
 	return nil
 }
 
-func unpackLayer(ctx context.Context, store content.Storage, desc ocispec.Descriptor, unpackPath string, overwrite, ignoreExisting bool, compression string) error {
+func unpackLayer(ctx context.Context, store content.Storage, desc ocispec.Descriptor, unpackPath string, overwrite, ignoreExisting bool, sync *syncState, split *splitFilter, compression string) error {
+	tr, logger, closeLayer, err := openLayer(ctx, store, desc, compression)
+	if err != nil {
+		return err
+	}
+	defer closeLayer()
+
+	if unpackPath != "" {
+		unpackPath = filepath.Dir(unpackPath)
+		if err := os.MkdirAll(unpackPath, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", unpackPath, err)
+		}
+	}
+
+	if err := extractTar(tr, ".", unpackPath, overwrite, ignoreExisting, sync, split, logger); err != nil {
+		return err
+	}
+
+	logger.Wait()
+	return nil
+}
+
+// openLayer fetches a layer from store and returns a tar reader for its uncompressed contents. The
+// returned function must be called to release resources once the reader is no longer needed.
+func openLayer(ctx context.Context, store content.Storage, desc ocispec.Descriptor, compression string) (*tar.Reader, *output.ProgressLogger, func(), error) {
+	if constants.ParseMediaType(desc.MediaType).Raw {
+		return nil, nil, nil, fmt.Errorf("unpacking raw ModelPack layers is not supported (layer %s)", desc.Digest)
+	}
 	rc, err := store.Fetch(ctx, desc)
 	if err != nil {
-		return fmt.Errorf("failed get layer %s: %w", desc.Digest, err)
+		return nil, nil, nil, fmt.Errorf("failed get layer %s: %w", desc.Digest, err)
 	}
 	var logger *output.ProgressLogger
 	rc, logger = output.WrapUnpackReadCloser(desc.Size, rc)
-	defer rc.Close()
 
 	var cr io.ReadCloser
 	var cErr error
@@ -259,29 +376,39 @@ func unpackLayer(ctx context.Context, store content.Storage, desc ocispec.Descri
 		cr, cErr = gzip.NewReader(rc)
 	case constants.NoneCompression:
 		cr = rc
+	default:
+		rc.Close()
+		return nil, nil, nil, fmt.Errorf("unsupported compression %s for layer %s", compression, desc.Digest)
 	}
 	if cErr != nil {
-		return fmt.Errorf("error setting up decompress: %w", cErr)
+		rc.Close()
+		return nil, nil, nil, fmt.Errorf("error setting up decompress: %w", cErr)
 	}
-	defer cr.Close()
 	tr := tar.NewReader(cr)
-
-	if unpackPath != "" {
-		unpackPath = filepath.Dir(unpackPath)
-		if err := os.MkdirAll(unpackPath, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", unpackPath, err)
-		}
+	closeLayer := func() {
+		cr.Close()
+		rc.Close()
 	}
-
-	if err := extractTar(tr, unpackPath, overwrite, ignoreExisting, logger); err != nil {
-		return err
+	if constants.ParseMediaType(desc.MediaType).Chunked {
+		var closeReassembled func()
+		tr, closeReassembled = reassembleChunkedLayer(ctx, store, tr, logger)
+		closeLayer = func() {
+			closeReassembled()
+			cr.Close()
+			rc.Close()
+		}
 	}
-
-	logger.Wait()
-	return nil
+	return tr, logger, closeLayer, nil
 }
 
-func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting bool, logger *output.ProgressLogger) (err error) {
+// extractTar extracts all entries in tr to extractDir, which is interpreted relative to rootDir. Paths
+// in the archive, including the targets of symlinks and hard links, must stay within rootDir once any
+// symlinks along them are resolved, and entries are never written through a symlinked parent directory.
+//
+// If sync is not nil, existing files are compared against the archive and only rewritten if they differ.
+// If split is not nil, only entries within the selected dataset splits are extracted.
+func extractTar(tr *tar.Reader, rootDir, extractDir string, overwrite, ignoreExisting bool, sync *syncState, split *splitFilter, logger *output.ProgressLogger) (err error) {
+	links := diskPaths(rootDir)
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
@@ -290,15 +417,28 @@ func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting boo
 		if err != nil {
 			return err
 		}
-		outPath := header.Name
-		if extractDir != "" {
-			outPath = filepath.Join(extractDir, header.Name)
-		}
+		relPath := filepath.Join(extractDir, header.Name)
+		outPath := filepath.Join(rootDir, relPath)
 		// Check if the outPath is within the target directory
-		_, _, err = filesystem.VerifySubpath(extractDir, outPath)
+		_, _, err = filesystem.VerifySubpath(rootDir, relPath)
 		if err != nil {
 			return fmt.Errorf("illegal file path: %s: %w", outPath, err)
 		}
+		if !split.includes(relPath) {
+			continue
+		}
+		if err := checkParentDirs(relPath, links); err != nil {
+			return fmt.Errorf("illegal file path: %s: %w", outPath, err)
+		}
+		if split != nil && header.Typeflag != tar.TypeDir {
+			// Parent directories are not extracted if they are not part of the selected splits
+			if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(outPath), err)
+			}
+		}
+		if sync != nil {
+			sync.addPath(relPath)
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
@@ -314,7 +454,13 @@ func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting boo
 			}
 
 		case tar.TypeReg:
-			if fi, exists := filesystem.PathExists(outPath); exists {
+			if sync != nil {
+				if done, err := syncExistingFile(outPath, header, tr, sync); err != nil {
+					return err
+				} else if done {
+					continue
+				}
+			} else if fi, err := os.Lstat(outPath); err == nil {
 				if ignoreExisting {
 					output.Debugf("File %s already exists; skipping", outPath)
 					continue
@@ -322,12 +468,17 @@ func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting boo
 				if !overwrite {
 					return fmt.Errorf("path '%s' already exists", outPath)
 				}
-				if !fi.Mode().IsRegular() {
-					return fmt.Errorf("path '%s' already exists and is not a regular file", outPath)
+				if fi.IsDir() {
+					return fmt.Errorf("path '%s' already exists and is a directory", outPath)
+				}
+				// Replace the existing file rather than writing through it, as it may be a symlink or a
+				// hard link to a file in the unpacked layer cache
+				if err := os.Remove(outPath); err != nil {
+					return fmt.Errorf("failed to remove existing file %s: %w", outPath, err)
 				}
 			}
 			logger.Debugf("Unpacking file %s", outPath)
-			file, err := os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, header.FileInfo().Mode())
+			file, err := os.OpenFile(outPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, header.FileInfo().Mode())
 			if err != nil {
 				return fmt.Errorf("failed to create file %s: %w", outPath, err)
 			}
@@ -342,6 +493,67 @@ func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting boo
 				return fmt.Errorf("could not unpack file %s", outPath)
 			}
 
+		case tar.TypeSymlink:
+			// Only create symlinks that resolve to a path inside the unpack directory
+			if filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("illegal symlink %s: absolute target %s is not supported", outPath, header.Linkname)
+			}
+			// The target is not cleaned before resolving it, as cleaning 'link/..' would ignore where link points
+			linkTarget := filepath.Dir(relPath) + string(filepath.Separator) + filepath.FromSlash(header.Linkname)
+			if _, err := resolveInRoot(linkTarget, links); err != nil {
+				return fmt.Errorf("illegal symlink %s -> %s: %w", outPath, header.Linkname, err)
+			}
+			if sync != nil {
+				if existing, err := os.Readlink(outPath); err == nil && existing == filepath.FromSlash(header.Linkname) {
+					sync.unchanged += 1
+					continue
+				}
+			}
+			if skip, err := prepareLinkPath(outPath, overwrite, ignoreExisting, sync); err != nil {
+				return err
+			} else if skip {
+				continue
+			}
+			logger.Debugf("Creating symlink %s -> %s", outPath, header.Linkname)
+			if err := os.Symlink(filepath.FromSlash(header.Linkname), outPath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", outPath, err)
+			}
+
+		case tar.TypeLink:
+			// Hard link targets are paths within the archive, relative to the same directory as header.Name
+			linkRelPath := filepath.Join(extractDir, filepath.FromSlash(header.Linkname))
+			if _, _, err := filesystem.VerifySubpath(rootDir, linkRelPath); err != nil {
+				return fmt.Errorf("illegal hard link %s -> %s: %w", outPath, header.Linkname, err)
+			}
+			if err := checkParentDirs(linkRelPath, links); err != nil {
+				return fmt.Errorf("illegal hard link %s -> %s: %w", outPath, header.Linkname, err)
+			}
+			if !split.includes(linkRelPath) {
+				output.Logf(output.LogLevelWarn, "Skipping hard link %s: target %s is not in the selected splits", outPath, header.Linkname)
+				continue
+			}
+			linkTarget := filepath.Join(rootDir, linkRelPath)
+			if fi, err := os.Lstat(linkTarget); err != nil || !fi.Mode().IsRegular() {
+				return fmt.Errorf("invalid hard link %s: target %s is not a regular file", outPath, header.Linkname)
+			}
+			if sync != nil {
+				if existing, err := os.Lstat(outPath); err == nil {
+					if targetFi, err := os.Lstat(linkTarget); err == nil && os.SameFile(existing, targetFi) {
+						sync.unchanged += 1
+						continue
+					}
+				}
+			}
+			if skip, err := prepareLinkPath(outPath, overwrite, ignoreExisting, sync); err != nil {
+				return err
+			} else if skip {
+				continue
+			}
+			logger.Debugf("Creating hard link %s -> %s", outPath, linkTarget)
+			if err := os.Link(linkTarget, outPath); err != nil {
+				return fmt.Errorf("failed to create hard link %s: %w", outPath, err)
+			}
+
 		default:
 			return fmt.Errorf("unrecognized type in archive: %s", header.Name)
 		}
@@ -349,6 +561,37 @@ func extractTar(tr *tar.Reader, extractDir string, overwrite, ignoreExisting boo
 	return nil
 }
 
+// prepareLinkPath checks whether a link can be created at path, removing any existing file if
+// overwrite is true or sync is not nil. Returns true if the link should be skipped as the path
+// already exists.
+func prepareLinkPath(path string, overwrite, ignoreExisting bool, sync *syncState) (skip bool, err error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if sync != nil {
+				sync.created += 1
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check path %s: %w", path, err)
+	}
+	if sync != nil {
+		sync.updated += 1
+	} else if ignoreExisting {
+		output.Debugf("File %s already exists; skipping", path)
+		return true, nil
+	} else if !overwrite {
+		return false, fmt.Errorf("path '%s' already exists", path)
+	}
+	if fi.IsDir() {
+		return false, fmt.Errorf("path '%s' already exists and is a directory", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("failed to remove existing file %s: %w", path, err)
+	}
+	return false, nil
+}
+
 func getIndex(list []string, s string) int {
 	for idx, item := range list {
 		if s == item {
@@ -0,0 +1,154 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var linkTests = []struct {
+	name    string
+	entries []*tar.Header
+	wantErr bool
+}{
+	{
+		name: "symlinks within directory",
+		entries: []*tar.Header{
+			{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "sub/file", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "sub/file"},
+			{Name: "sub/up", Typeflag: tar.TypeSymlink, Linkname: "../link"},
+		},
+	},
+	{
+		name: "symlink outside directory",
+		entries: []*tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+		},
+		wantErr: true,
+	},
+	{
+		name: "chain of symlinks outside directory",
+		entries: []*tar.Header{
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "a/.."},
+			{Name: "x/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+		wantErr: true,
+	},
+	{
+		name: "symlink through a path created later",
+		entries: []*tar.Header{
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "a/../evil"},
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+		},
+		wantErr: true,
+	},
+	{
+		name: "directory replaced after a symlink through it",
+		entries: []*tar.Header{
+			{Name: "a/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "a/../evil"},
+			{Name: "a", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+		},
+		wantErr: true,
+	},
+	{
+		name: "file in symlinked directory",
+		entries: []*tar.Header{
+			{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "sub"},
+			{Name: "link/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+		wantErr: true,
+	},
+	{
+		name: "hard link through symlinked directory",
+		entries: []*tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "file", Typeflag: tar.TypeReg, Mode: 0644},
+			{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "link/file"},
+		},
+		wantErr: true,
+	},
+}
+
+func TestExtractTarLinks(t *testing.T) {
+	for _, tt := range linkTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			rootDir := filepath.Join(tmpDir, "root")
+			if err := os.Mkdir(rootDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			buf := &bytes.Buffer{}
+			tw := tar.NewWriter(buf)
+			for _, header := range tt.entries {
+				var content []byte
+				if header.Typeflag == tar.TypeReg {
+					content = []byte(header.Name)
+					header.Size = int64(len(content))
+				}
+				if err := tw.WriteHeader(header); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write(content); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractTar(tar.NewReader(buf), rootDir, "", false, false, nil, nil, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			_, err = os.Lstat(filepath.Join(tmpDir, "evil"))
+			assert.ErrorIs(t, err, os.ErrNotExist, "No file should be written outside the unpack directory")
+		})
+	}
+}
+
+func TestStreamedLinks(t *testing.T) {
+	for _, tt := range linkTests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := archiveLinks{}
+			var err error
+			for _, header := range tt.entries {
+				if err = links.verifyEntry(header); err != nil {
+					break
+				}
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
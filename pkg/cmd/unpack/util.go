@@ -20,47 +20,96 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
 )
 
-func getStoreForRef(ctx context.Context, opts *unpackOptions) (oras.Target, error) {
+// resumableRepository wraps a remote repository so that blobs streamed from it resume
+// interrupted downloads using range requests instead of failing the unpack.
+type resumableRepository struct {
+	registry.Repository
+}
+
+func (r *resumableRepository) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := r.Repository.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	return remote.NewResumableReader(ctx, rc, desc), nil
+}
+
+// getStoreForRef returns the store to unpack from along with the descriptor of the modelkit manifest to unpack.
+// If the reference is an index of modelkit variants, the variant selected by opts.variant is returned.
+func getStoreForRef(ctx context.Context, opts *unpackOptions) (oras.Target, ocispec.Descriptor, error) {
 	storageHome := constants.StoragePath(opts.configHome)
 	localRepo, err := local.NewLocalRepo(storageHome, opts.modelRef)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read local storage: %s\n", err)
+		return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to read local storage: %s\n", err)
 	}
 
-	if _, err := localRepo.Resolve(ctx, opts.modelRef.Reference); err == nil {
-		// Reference is present in local storage
-		return localRepo, nil
+	if !opts.noCache {
+		if desc, err := localRepo.Resolve(ctx, opts.modelRef.Reference); err == nil {
+			// Reference is present in local storage. If only one variant was pulled, the tag refers to that
+			// variant rather than the index, so other variants have to be selected from the remote index.
+			if opts.variant == "" || desc.MediaType == ocispec.MediaTypeImageIndex || opts.modelRef.Registry == util.DefaultRegistry {
+				desc, err := util.ResolveVariant(ctx, localRepo, desc, opts.variant)
+				if err != nil {
+					return nil, ocispec.DescriptorEmptyJSON, err
+				}
+				return localRepo, desc, nil
+			}
+			output.Debugf("Local copy of %s does not contain variants, checking remote", opts.modelRef.String())
+		}
 	}
 
 	if opts.modelRef.Registry == util.DefaultRegistry {
-		return nil, fmt.Errorf("not found")
+		return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("not found")
 	}
 	// Not in local storage, check remote
 	remoteRegistry, err := remote.NewRegistry(opts.modelRef.Registry, &opts.NetworkOptions)
 	if err != nil {
-		return nil, fmt.Errorf("could not resolve registry %s: %w", opts.modelRef.Registry, err)
+		return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("could not resolve registry %s: %w", opts.modelRef.Registry, err)
 	}
 
 	repo, err := remoteRegistry.Repository(ctx, opts.modelRef.Repository)
 	if err != nil {
-		return nil, fmt.Errorf("could not resolve repository %s in registry %s", opts.modelRef.Repository, opts.modelRef.Registry)
+		return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("could not resolve repository %s in registry %s", opts.modelRef.Repository, opts.modelRef.Registry)
 	}
-	if _, err := repo.Resolve(ctx, opts.modelRef.Reference); err != nil {
+	desc, err := repo.Resolve(ctx, opts.modelRef.Reference)
+	if err != nil {
 		if errors.Is(err, errdef.ErrNotFound) {
-			return nil, fmt.Errorf("reference %s is not present in local storage and could not be found in remote", opts.modelRef.String())
+			return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("reference %s is not present in local storage and could not be found in remote", opts.modelRef.String())
+		}
+		return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("unexpected error retrieving reference from remote: %w", err)
+	}
+	variantDesc, err := util.ResolveVariant(ctx, repo, desc, opts.variant)
+	if err != nil {
+		return nil, ocispec.DescriptorEmptyJSON, err
+	}
+
+	if opts.cache {
+		// Pull the modelkit into local storage first, then unpack from there. If a variant was selected,
+		// only that variant is pulled.
+		pullRef := *opts.modelRef
+		if variantDesc.Digest != desc.Digest {
+			pullRef.Reference = variantDesc.Digest.String()
+		}
+		output.Infof("Pulling %s to local storage", util.FormatRepositoryForDisplay(pullRef.String()))
+		if _, err := localRepo.PullModel(ctx, repo, pullRef, &opts.NetworkOptions); err != nil {
+			return nil, ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to pull: %w", err)
 		}
-		return nil, fmt.Errorf("unexpected error retrieving reference from remote: %w", err)
+		return localRepo, variantDesc, nil
 	}
 
-	return repo, nil
+	return &resumableRepository{Repository: repo}, variantDesc, nil
 }
@@ -0,0 +1,161 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/cmd/options"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	kfutils "github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	shortDesc = "Check a Kitfile for errors"
+	longDesc  = `Check a Kitfile for errors without packing it.
+
+The Kitfile is checked against the Kitfile JSON schema (which can be printed
+using the --schema flag) and for the same issues that are checked when
+packing a modelkit, such as duplicate paths or invalid layer settings. In
+addition, this command reports
+
+  * paths in the Kitfile that do not exist, relative to the directory
+    containing the Kitfile
+  * licenses that are not valid SPDX license expressions
+  * references to other modelkits that cannot be found in local storage or
+    on the remote registry
+
+Each problem is reported with the line and column in the Kitfile it applies
+to. Invalid licenses and undefined variables are reported as warnings; all other
+problems are errors and cause the command to fail.
+
+Variables in the Kitfile are expanded before it is checked, as in 'kit pack',
+using the --set and --values flags and the environment.
+
+If PATH is a directory, the Kitfile in that directory is checked. If PATH is
+not specified, the current directory is used.`
+
+	examples = `# Check the Kitfile in the current directory
+kit validate
+
+# Check a specific Kitfile
+kit validate ./my-model/Kitfile
+
+# Print the JSON schema for Kitfiles
+kit validate --schema`
+)
+
+type validateOptions struct {
+	options.KitfileTemplateOptions
+	configHome  string
+	kitfilePath string
+	printSchema bool
+	variables   map[string]string
+}
+
+func (opts *validateOptions) complete(ctx context.Context, args []string) error {
+	configHome, ok := ctx.Value(constants.ConfigKey{}).(string)
+	if !ok {
+		return fmt.Errorf("default config path not set on command context")
+	}
+	opts.configHome = configHome
+	if opts.printSchema {
+		if len(args) > 0 {
+			return fmt.Errorf("a path cannot be specified when printing the schema")
+		}
+		return nil
+	}
+
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	variables, err := kfutils.ParseVariables(opts.ValuesFile, opts.SetVariables)
+	if err != nil {
+		return err
+	}
+	opts.variables = variables
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if stat.IsDir() {
+		kitfilePath, err := filesystem.FindKitfileInPath(path)
+		if err != nil {
+			return err
+		}
+		opts.kitfilePath = kitfilePath
+	} else {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+		opts.kitfilePath = absPath
+	}
+	return nil
+}
+
+func ValidateCommand() *cobra.Command {
+	opts := &validateOptions{}
+	cmd := &cobra.Command{
+		Use:     "validate [flags] [PATH]",
+		Short:   shortDesc,
+		Long:    longDesc,
+		Example: examples,
+		RunE:    runCommand(opts),
+		Args:    cobra.MaximumNArgs(1),
+	}
+	cmd.Flags().BoolVar(&opts.printSchema, "schema", false, "Print the JSON schema for Kitfiles and exit")
+	opts.AddTemplateFlags(cmd)
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+func runCommand(opts *validateOptions) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := opts.complete(cmd.Context(), args); err != nil {
+			return output.Fatalf("Invalid arguments: %s", err)
+		}
+		if opts.printSchema {
+			schemaBytes, err := artifact.MarshalKitfileJSONSchema()
+			if err != nil {
+				return output.Fatalf("Failed to generate schema: %s", err)
+			}
+			fmt.Print(string(schemaBytes))
+			return nil
+		}
+
+		numErrors, err := runValidate(cmd.Context(), opts)
+		if err != nil {
+			return output.Fatalf("Failed to validate Kitfile: %s", err)
+		}
+		if numErrors > 0 {
+			return output.Fatalf("Found %d errors in %s", numErrors, opts.kitfilePath)
+		}
+		output.Infof("Kitfile %s is valid", opts.kitfilePath)
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/lib/kitfile"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+// runValidate checks the Kitfile in opts, printing any problems found, and returns the number of errors
+// (problems that are not warnings).
+func runValidate(ctx context.Context, opts *validateOptions) (int, error) {
+	content, err := os.ReadFile(opts.kitfilePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Kitfile: %w", err)
+	}
+	// References pinned by pack are checked by digest
+	lock, err := kitfile.ReadLockFile(kitfile.LockFilePath(opts.kitfilePath, ""))
+	if err != nil {
+		return 0, err
+	}
+	validateOpts := kitfile.ValidateOptions{
+		Variables:        opts.variables,
+		ExpandParameters: opts.ExpandParameters,
+		ContextDir:       filepath.Dir(opts.kitfilePath),
+		CheckLicenses:    true,
+		ResolveReference: func(ref string) error {
+			_, err := kitfile.GetKitfileForRefString(ctx, opts.configHome, lock.Pin(ref))
+			return err
+		},
+	}
+	_, problems, err := kitfile.ValidateKitfileYAML(content, validateOpts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", opts.kitfilePath, err)
+	}
+
+	name := filepath.Base(opts.kitfilePath)
+	numErrors := 0
+	for _, problem := range problems {
+		location := name
+		if problem.File != "" {
+			location = problem.File
+		}
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", location, problem.Line, problem.Column)
+		}
+		if problem.Warning {
+			output.Logf(output.LogLevelWarn, "%s: %s", location, problem.Message)
+		} else {
+			numErrors++
+			output.Errorf("%s: %s", location, problem.Message)
+		}
+	}
+	return numErrors, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chunk implements content-defined chunking, which splits files into chunks at
+// positions determined by their content rather than at fixed offsets. Inserting or modifying
+// data in a file only changes the chunks around the modification, allowing unchanged chunks
+// to be shared between versions of the file.
+package chunk
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const (
+	// MinSize is the minimum size of a chunk, except for the last chunk in a file
+	MinSize = 1 << 20
+	// AvgSize is the target average size of chunks
+	AvgSize = 4 << 20
+	// MaxSize is the maximum size of a chunk
+	MaxSize = 16 << 20
+)
+
+// gearTable maps each byte value to a pseudo-random value used in the rolling hash. It is
+// generated from a fixed seed as chunk boundaries (and therefore deduplication between files
+// chunked by different versions of the CLI) depend on it; it must never change.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	// splitmix64
+	state := uint64(0x6b69746f70732121)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// Chunker splits a stream into content-defined chunks using a gear-based rolling hash
+// with normalized chunking (as in FastCDC).
+type Chunker struct {
+	r         io.Reader
+	buf       []byte
+	n         int
+	consumed  int
+	eof       bool
+	minSize   int
+	avgSize   int
+	maskSmall uint64
+	maskLarge uint64
+}
+
+// NewChunker returns a Chunker that reads from r using the default chunk sizes.
+func NewChunker(r io.Reader) *Chunker {
+	return newChunkerWithSizes(r, MinSize, AvgSize, MaxSize)
+}
+
+func newChunkerWithSizes(r io.Reader, minSize, avgSize, maxSize int) *Chunker {
+	avgBits := bits.Len(uint(avgSize)) - 1
+	return &Chunker{
+		r:       r,
+		buf:     make([]byte, maxSize),
+		minSize: minSize,
+		avgSize: avgSize,
+		// Before reaching the average size, cut points are harder to match; after it, they
+		// are easier. This narrows the distribution of chunk sizes around the average.
+		maskSmall: ^uint64(0) << (64 - (avgBits + 1)),
+		maskLarge: ^uint64(0) << (64 - (avgBits - 1)),
+	}
+}
+
+// Next returns the next chunk from the underlying reader, or io.EOF if there are no more chunks.
+// The returned slice is only valid until the next call to Next.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.consumed > 0 {
+		copy(c.buf, c.buf[c.consumed:c.n])
+		c.n -= c.consumed
+		c.consumed = 0
+	}
+	if !c.eof && c.n < len(c.buf) {
+		read, err := io.ReadFull(c.r, c.buf[c.n:])
+		c.n += read
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			c.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if c.n == 0 {
+		return nil, io.EOF
+	}
+	c.consumed = c.cutPoint(c.buf[:c.n])
+	return c.buf[:c.consumed], nil
+}
+
+// cutPoint returns the length of the next chunk at the start of data.
+func (c *Chunker) cutPoint(data []byte) int {
+	n := len(data)
+	if n <= c.minSize {
+		return n
+	}
+	normalSize := min(c.avgSize, n)
+	var hash uint64
+	i := c.minSize
+	for ; i < normalSize; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+		if hash&c.maskSmall == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+		if hash&c.maskLarge == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
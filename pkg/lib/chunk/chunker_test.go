@@ -0,0 +1,129 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package chunk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testMinSize = 1 << 10
+	testAvgSize = 4 << 10
+	testMaxSize = 16 << 10
+)
+
+func chunkAll(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	chunker := newChunkerWithSizes(bytes.NewReader(data), testMinSize, testAvgSize, testMaxSize)
+	var chunks [][]byte
+	for {
+		c, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		chunks = append(chunks, bytes.Clone(c))
+	}
+	return chunks
+}
+
+func randomData(seed int64, size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func TestChunkerReassembles(t *testing.T) {
+	for _, size := range []int{0, 1, testMinSize, testMaxSize, 1 << 20} {
+		data := randomData(int64(size), size)
+		chunks := chunkAll(t, data)
+		for idx, c := range chunks {
+			assert.LessOrEqual(t, len(c), testMaxSize)
+			if idx < len(chunks)-1 {
+				assert.GreaterOrEqual(t, len(c), testMinSize)
+			}
+		}
+		assert.Equal(t, data, bytes.Join(chunks, nil), "chunks should reassemble to input of size %d", size)
+	}
+}
+
+func TestChunkerSharesChunksAfterEdit(t *testing.T) {
+	data := randomData(1, 1<<20)
+	edited := bytes.Clone(data[:300000])
+	edited = append(edited, []byte("inserted bytes")...)
+	edited = append(edited, data[300000:]...)
+
+	original := map[digest.Digest]bool{}
+	for _, c := range chunkAll(t, data) {
+		original[digest.FromBytes(c)] = true
+	}
+	editedChunks := chunkAll(t, edited)
+	var shared int
+	for _, c := range editedChunks {
+		if original[digest.FromBytes(c)] {
+			shared++
+		}
+	}
+	// Only chunks around the edit should differ
+	assert.GreaterOrEqual(t, shared, len(editedChunks)-3)
+	assert.Greater(t, len(editedChunks), 10)
+}
+
+func TestParseManifest(t *testing.T) {
+	data := randomData(2, 100)
+	valid := `{"size":100,"digest":"` + digest.FromBytes(data).String() + `","chunks":[{"digest":"` + digest.FromBytes(data[:40]).String() + `","size":40},{"digest":"` + digest.FromBytes(data[40:]).String() + `","size":60}]}`
+	manifest, err := ParseManifest([]byte(valid))
+	if assert.NoError(t, err) {
+		assert.Len(t, manifest.Chunks, 2)
+	}
+
+	invalidSize := `{"size":99,"digest":"` + digest.FromBytes(data).String() + `","chunks":[{"digest":"` + digest.FromBytes(data).String() + `","size":100}]}`
+	_, err = ParseManifest([]byte(invalidSize))
+	assert.ErrorContains(t, err, "do not add up")
+
+	_, err = ParseManifest([]byte(`{"size":0,"digest":"invalid","chunks":[]}`))
+	assert.ErrorContains(t, err, "invalid digest")
+}
+
+// endlessReader returns an unlimited number of bytes, counting how many were read
+type endlessReader struct {
+	read int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestReadManifestLimitsSize(t *testing.T) {
+	r := &endlessReader{}
+	_, err := ReadManifest(r)
+	assert.ErrorContains(t, err, "too large")
+	assert.LessOrEqual(t, r.read, int64(2*maxManifestSize), "Manifest should not be read past the size limit")
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package chunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	// PAXRecordKey is the PAX record set on tar entries in chunked layers whose content is a
+	// FileManifest rather than the file itself. Its value is the manifest format version.
+	PAXRecordKey = "KITOPS.chunked"
+	// ManifestVersion is the current version of the FileManifest format
+	ManifestVersion = "1"
+	// MinFileSize is the size above which files are chunked when packing chunked layers. Smaller
+	// files are stored in the layer directly.
+	MinFileSize = MaxSize
+	// maxManifestSize limits the size of manifests read from layers
+	maxManifestSize = 16 << 20
+)
+
+// FileManifest describes how to reassemble a file from chunks stored as separate blobs.
+type FileManifest struct {
+	Size   int64         `json:"size"`
+	Digest digest.Digest `json:"digest"`
+	Chunks []Chunk       `json:"chunks"`
+}
+
+// Chunk is a single chunk of a file, stored as a blob identified by its digest.
+type Chunk struct {
+	Digest digest.Digest `json:"digest"`
+	Size   int64         `json:"size"`
+}
+
+// ReadManifest reads a FileManifest from r, returning an error without reading further if it is larger
+// than the maximum manifest size.
+func ReadManifest(r io.Reader) (*FileManifest, error) {
+	manifestBytes, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk manifest: %w", err)
+	}
+	return ParseManifest(manifestBytes)
+}
+
+// ParseManifest parses and validates a FileManifest.
+func ParseManifest(manifestBytes []byte) (*FileManifest, error) {
+	if len(manifestBytes) > maxManifestSize {
+		return nil, fmt.Errorf("chunk manifest is too large")
+	}
+	manifest := &FileManifest{}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk manifest: %w", err)
+	}
+	if err := manifest.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid digest in chunk manifest: %w", err)
+	}
+	var total int64
+	for _, c := range manifest.Chunks {
+		if err := c.Digest.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid chunk digest in chunk manifest: %w", err)
+		}
+		if c.Size <= 0 || c.Size > MaxSize {
+			return nil, fmt.Errorf("invalid chunk size %d in chunk manifest", c.Size)
+		}
+		total += c.Size
+	}
+	if total != manifest.Size {
+		return nil, fmt.Errorf("chunk sizes in chunk manifest do not add up to file size")
+	}
+	return manifest, nil
+}
@@ -32,6 +32,8 @@ const (
 	DefaultKitfileName = "Kitfile"
 	// IgnoreFileName is the name for the Kit ignore file
 	IgnoreFileName = ".kitignore"
+	// KitfileLockName is the name of the file pinning modelkits referenced by a Kitfile to digests
+	KitfileLockName = "Kitfile.lock"
 
 	// Constants for the directory structure of kit's cached images and credentials
 	// Modelkits are stored in $KITOPS_HOME/storage/ and
@@ -39,6 +41,7 @@ const (
 	DefaultConfigSubdir               = "kitops"
 	StorageSubpath                    = "storage"
 	CacheSubpath                      = "cache"
+	UnpackedSubpath                   = "unpacked"
 	CredentialsSubpath                = "credentials.json"
 	HarnessSubpath                    = "harness"
 	HarnessProcessFile                = "process.pid"
@@ -47,6 +50,25 @@ const (
 
 	// Kitops-specific annotations for modelkit artifacts
 	CliVersionAnnotation = "ml.kitops.modelkit.cli-version"
+	// LayerShardAnnotation is set on layers for paths split into multiple layers, in the format
+	// "<index>/<total>" (starting from 1)
+	LayerShardAnnotation = "ml.kitops.modelkit.layer.shard"
+	// VariantAnnotation is set on entries in an image index of modelkit variants, naming the variant
+	VariantAnnotation = "ml.kitops.modelkit.variant"
+	// VariantAnnotationPrefix is prepended to keys of variant annotations (e.g. quantization) that
+	// are not already namespaced
+	VariantAnnotationPrefix = "ml.kitops.modelkit.variant."
+	// KitfileAnnotation is set on modelkits converted to ModelPack artifacts and stores the original Kitfile,
+	// so that converting back to a modelkit restores it exactly
+	KitfileAnnotation = "ml.kitops.modelkit.kitfile"
+	// LockAnnotation is set on modelkits that reference other modelkits and stores the digests those
+	// references were pinned to when packing, in JSON format
+	LockAnnotation = "ml.kitops.modelkit.lock"
+	// ModelCardAnnotation stores the modelcard section of the Kitfile, in JSON format, so that it can be
+	// shown by registries without fetching the Kitfile
+	ModelCardAnnotation = "ml.kitops.modelkit.modelcard"
+	// MetricsAnnotation stores the metrics section of the Kitfile, in JSON format
+	MetricsAnnotation = "ml.kitops.modelkit.metrics"
 
 	// MaxModelRefChain is the maximum number of "parent" modelkits a modelkit may have
 	// by e.g. referring to another modelkit in its .model.path
@@ -130,6 +152,12 @@ func CachePath(configBase string) string {
 	return filepath.Join(configBase, CacheSubpath)
 }
 
+// UnpackedCachePath returns the path to the shared cache of extracted layers used when
+// unpacking with links to the cache.
+func UnpackedCachePath(configBase string) string {
+	return filepath.Join(configBase, UnpackedSubpath)
+}
+
 // IndexJsonPath is a wrapper for getting the index.json path for a local OCI index,
 // based off the base path of the index.
 func IndexJsonPath(storageBase string) string {
@@ -20,4 +20,7 @@ const (
 	KitopsHomeEnvVar    = "KITOPS_HOME"
 	ClientCertEnvVar    = "KITOPS_CLIENT_CERT"
 	ClientCertKeyEnvVar = "KITOPS_CLIENT_KEY"
+	// SourceDateEpochEnvVar is the standard environment variable for reproducible builds, see
+	// https://reproducible-builds.org/specs/source-date-epoch/
+	SourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
 )
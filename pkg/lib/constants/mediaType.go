@@ -30,19 +30,61 @@ const (
 	DatasetType   = "dataset"
 	CodeType      = "code"
 	DocsType      = "docs"
+	// ChunkType is the type of blobs holding chunks of large files in chunked layers
+	ChunkType = "chunk"
 )
 
 const (
 	NoneCompression        = "none"
 	GzipCompression        = "gzip"
 	GzipFastestCompression = "gzip-fastest"
+	// AutoCompression selects a compression for each layer based on its content
+	AutoCompression = "auto"
 )
 
-var mediaTypeRegexp = regexp.MustCompile(`^application/vnd.kitops.modelkit.(\w+).v1.tar(?:\+(\w+))?`)
+var mediaTypeRegexp = regexp.MustCompile(`^application/vnd.kitops.modelkit.(\w+).v1(\.chunked)?.tar(?:\+(\w+))?`)
+
+const chunkMediaType = "application/vnd.kitops.modelkit.chunk.v1"
+
+// ModelKitArtifactType is the artifactType set on modelkit manifests when they are stored as OCI 1.1
+// artifacts. Modelkit manifests without an artifactType are identified by their config media type.
+const ModelKitArtifactType = "application/vnd.kitops.modelkit.manifest.v1+json"
+
+// Media types and annotations defined by the CNCF ModelPack specification (https://github.com/modelpack/model-spec)
+const (
+	ModelPackArtifactType    = "application/vnd.cncf.model.manifest.v1+json"
+	ModelPackConfigMediaType = "application/vnd.cncf.model.config.v1+json"
+	// ModelPackFilepathAnnotation is set on ModelPack layers to the path of the file or directory they contain
+	ModelPackFilepathAnnotation = "org.cncf.model.filepath"
+)
+
+var modelPackMediaTypeRegexp = regexp.MustCompile(`^application/vnd.cncf.model.(weight|weight\.config|dataset|code|doc).v1.(tar|raw)(?:\+(\w+))?$`)
+
+// modelPackBaseTypes maps Kitfile base types to the layer types used by ModelPack. ModelPack has no equivalent
+// of model parts, so they are stored as weight configuration regardless of what they contain; this keeps them
+// separate from the model's weights so that they are restored as parts, but parts that are weights themselves
+// (e.g. LoRA adapters) are described incorrectly to other ModelPack tools.
+var modelPackBaseTypes = map[string]string{
+	ModelType:     "weight",
+	ModelPartType: "weight.config",
+	DatasetType:   "dataset",
+	CodeType:      "code",
+	DocsType:      "doc",
+}
 
 type MediaType struct {
 	BaseType    string
 	Compression string
+	// Chunked is true for layers where large files are stored as chunks in separate blobs
+	Chunked bool
+	// ModelPack is true for media types defined by the ModelPack specification rather than by KitOps
+	ModelPack bool
+	// Raw is true for ModelPack layers that contain a single file as-is rather than a tar archive
+	Raw bool
+}
+
+var ChunkMediaType = MediaType{
+	BaseType: ChunkType,
 }
 
 var ModelConfigMediaType = MediaType{
@@ -50,32 +92,93 @@ var ModelConfigMediaType = MediaType{
 }
 
 func (t MediaType) String() string {
+	if t.ModelPack {
+		return t.modelPackString()
+	}
 	if t.BaseType == ConfigType {
 		return "application/vnd.kitops.modelkit.config.v1+json"
 	}
+	if t.BaseType == ChunkType {
+		return chunkMediaType
+	}
+	format := "tar"
+	if t.Chunked {
+		format = "chunked.tar"
+	}
 	if t.Compression == NoneCompression {
-		return fmt.Sprintf("application/vnd.kitops.modelkit.%s.v1.tar", t.BaseType)
+		return fmt.Sprintf("application/vnd.kitops.modelkit.%s.v1.%s", t.BaseType, format)
 	}
 	comp := t.Compression
 	if comp == GzipFastestCompression {
 		comp = GzipCompression
 	}
-	return fmt.Sprintf("application/vnd.kitops.modelkit.%s.v1.tar+%s", t.BaseType, comp)
+	return fmt.Sprintf("application/vnd.kitops.modelkit.%s.v1.%s+%s", t.BaseType, format, comp)
 }
 
+func (t MediaType) modelPackString() string {
+	if t.BaseType == ConfigType {
+		return ModelPackConfigMediaType
+	}
+	format := "tar"
+	if t.Raw {
+		format = "raw"
+	}
+	mediaType := fmt.Sprintf("application/vnd.cncf.model.%s.v1.%s", modelPackBaseTypes[t.BaseType], format)
+	switch t.Compression {
+	case NoneCompression, "":
+		return mediaType
+	case GzipFastestCompression:
+		return mediaType + "+" + GzipCompression
+	default:
+		return mediaType + "+" + t.Compression
+	}
+}
+
+// ParseMediaType parses a layer or config media type, which may be either a KitOps or ModelPack media type.
+// An empty MediaType is returned if s is not recognized.
 func ParseMediaType(s string) MediaType {
+	if s == ModelPackConfigMediaType {
+		return MediaType{
+			BaseType:  ConfigType,
+			ModelPack: true,
+		}
+	}
+	if match := modelPackMediaTypeRegexp.FindStringSubmatch(s); match != nil {
+		mediaType := MediaType{
+			Compression: match[3],
+			ModelPack:   true,
+			Raw:         match[2] == "raw",
+		}
+		for baseType, modelPackType := range modelPackBaseTypes {
+			if modelPackType == match[1] {
+				mediaType.BaseType = baseType
+			}
+		}
+		if mediaType.Compression == "" {
+			mediaType.Compression = NoneCompression
+		}
+		return mediaType
+	}
+
 	if s == "application/vnd.kitops.modelkit.config.v1+json" {
 		return MediaType{
 			BaseType: ConfigType,
 		}
 	}
+	if s == chunkMediaType {
+		return MediaType{
+			BaseType:    ChunkType,
+			Compression: NoneCompression,
+		}
+	}
 	match := mediaTypeRegexp.FindStringSubmatch(s)
 	if match == nil {
 		return MediaType{}
 	}
 	mediaType := MediaType{
 		BaseType:    match[1],
-		Compression: match[2],
+		Compression: match[3],
+		Chunked:     match[2] != "",
 	}
 	if mediaType.Compression == "" {
 		mediaType.Compression = NoneCompression
@@ -85,10 +188,10 @@ func ParseMediaType(s string) MediaType {
 
 func IsValidCompression(compression string) error {
 	switch compression {
-	case NoneCompression, GzipCompression, GzipFastestCompression:
+	case NoneCompression, GzipCompression, GzipFastestCompression, AutoCompression:
 		return nil
 	default:
-		return fmt.Errorf("invalid compression type: must be one of 'none', 'gzip', or 'gzip-fastest'")
+		return fmt.Errorf("invalid compression type: must be one of 'none', 'gzip', 'gzip-fastest', or 'auto'")
 	}
 }
 
@@ -0,0 +1,93 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// inspectCSV reads a CSV file with a header row. Column types are inferred from the values in the first
+// sampleRows rows; columns with differing types are treated as strings, as any value in a CSV file is a
+// valid string.
+func inspectCSV(r io.Reader) (*artifact.DatasetTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &artifact.DatasetTable{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	names := make([]string, len(header))
+	copy(names, header)
+	types := newColumnTypes()
+	for _, name := range names {
+		types.add(name, "")
+	}
+
+	table := &artifact.DatasetTable{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", table.Rows+1, err)
+		}
+		if table.Rows < sampleRows {
+			for idx, value := range record {
+				if idx < len(names) {
+					types.add(names[idx], csvValueType(value))
+				}
+			}
+		}
+		table.Rows++
+	}
+	table.Columns = types.columns()
+	for idx, column := range table.Columns {
+		if column.Type == typeMixed {
+			table.Columns[idx].Type = typeString
+		}
+	}
+	return table, nil
+}
+
+func csvValueType(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return typeInteger
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return typeNumber
+	}
+	if _, err := strconv.ParseBool(strings.ToLower(value)); err == nil && len(value) > 1 {
+		return typeBoolean
+	}
+	return typeString
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// Supported tabular file formats
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// sampleRows is the number of rows read to infer column types for csv and jsonl files. All rows are
+// counted, but only the first sampleRows rows are used for types.
+const sampleRows = 1000
+
+// Types inferred for columns in csv and jsonl files
+const (
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeObject  = "object"
+	typeArray   = "array"
+	typeMixed   = "mixed"
+)
+
+// FormatForPath returns the tabular format of the file at path based on its extension, or an empty string
+// if the format is not supported.
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".parquet":
+		return FormatParquet
+	default:
+		return ""
+	}
+}
+
+// InspectFile reads the file at path and returns a table describing its rows and columns. The format of the
+// file is determined by FormatForPath, and an error is returned if it is not supported.
+func InspectFile(path string) (*artifact.DatasetTable, error) {
+	format := FormatForPath(path)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported file format for %s", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var table *artifact.DatasetTable
+	switch format {
+	case FormatCSV:
+		table, err = inspectCSV(f)
+	case FormatJSONL:
+		table, err = inspectJSONL(f)
+	case FormatParquet:
+		fi, statErr := f.Stat()
+		if statErr != nil {
+			return nil, statErr
+		}
+		table, err = inspectParquet(f, fi.Size())
+	}
+	if err != nil {
+		return nil, err
+	}
+	table.Format = format
+	table.Files = 1
+	return table, nil
+}
+
+// AddTable adds table to tables, combining it with an existing table if one has the same format and columns.
+func AddTable(tables []artifact.DatasetTable, table artifact.DatasetTable) []artifact.DatasetTable {
+	for idx, existing := range tables {
+		if existing.Format == table.Format && slices.Equal(existing.Columns, table.Columns) {
+			tables[idx].Files += table.Files
+			tables[idx].Rows += table.Rows
+			return tables
+		}
+	}
+	return append(tables, table)
+}
+
+// columnTypes tracks the names and inferred types of columns in the order they are first seen.
+type columnTypes struct {
+	names []string
+	types map[string]string
+}
+
+func newColumnTypes() *columnTypes {
+	return &columnTypes{types: map[string]string{}}
+}
+
+// add records that a value of type valueType was found in a column. Empty valueTypes only record the
+// column, for values such as nulls that do not indicate a type.
+func (c *columnTypes) add(name, valueType string) {
+	current, ok := c.types[name]
+	if !ok {
+		c.names = append(c.names, name)
+	}
+	c.types[name] = combineTypes(current, valueType)
+}
+
+// columns returns the columns, using typeString for columns where no type could be inferred.
+func (c *columnTypes) columns() []artifact.DatasetColumn {
+	var columns []artifact.DatasetColumn
+	for _, name := range c.names {
+		columnType := c.types[name]
+		if columnType == "" {
+			columnType = typeString
+		}
+		columns = append(columns, artifact.DatasetColumn{Name: name, Type: columnType})
+	}
+	return columns
+}
+
+// combineTypes returns the type for a column containing values of types a and b. Integers and numbers are
+// combined as numbers, and any other differing types as mixed.
+func combineTypes(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case (a == typeInteger && b == typeNumber) || (a == typeNumber && b == typeInteger):
+		return typeNumber
+	default:
+		return typeMixed
+	}
+}
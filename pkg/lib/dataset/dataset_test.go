@@ -0,0 +1,141 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		expected *artifact.DatasetTable
+	}{
+		{
+			name:     "csv",
+			file:     "data.csv",
+			contents: "id,score,label,passed\n1,0.5,cat,true\n2,1,\"dog, big\",false\n3,,bird,TRUE\n",
+			expected: &artifact.DatasetTable{
+				Format: FormatCSV,
+				Files:  1,
+				Rows:   3,
+				Columns: []artifact.DatasetColumn{
+					{Name: "id", Type: "integer"},
+					{Name: "score", Type: "number"},
+					{Name: "label", Type: "string"},
+					{Name: "passed", Type: "boolean"},
+				},
+			},
+		},
+		{
+			name:     "csv with mixed types",
+			file:     "data.csv",
+			contents: "value,empty\n1\nabc,\n",
+			expected: &artifact.DatasetTable{
+				Format: FormatCSV,
+				Files:  1,
+				Rows:   2,
+				Columns: []artifact.DatasetColumn{
+					{Name: "value", Type: "string"},
+					{Name: "empty", Type: "string"},
+				},
+			},
+		},
+		{
+			name:     "empty csv",
+			file:     "data.csv",
+			contents: "",
+			expected: &artifact.DatasetTable{Format: FormatCSV, Files: 1},
+		},
+		{
+			name:     "jsonl",
+			file:     "data.jsonl",
+			contents: "{\"text\": \"hello\", \"tokens\": 2, \"meta\": {\"a\": 1}}\n\n{\"tokens\": 2.5, \"text\": null, \"tags\": [\"x\"], \"ok\": true}\n{\"text\": 3}",
+			expected: &artifact.DatasetTable{
+				Format: FormatJSONL,
+				Files:  1,
+				Rows:   3,
+				Columns: []artifact.DatasetColumn{
+					{Name: "text", Type: "mixed"},
+					{Name: "tokens", Type: "number"},
+					{Name: "meta", Type: "object"},
+					{Name: "tags", Type: "array"},
+					{Name: "ok", Type: "boolean"},
+				},
+			},
+		},
+		{
+			name:     "jsonl without objects",
+			file:     "data.ndjson",
+			contents: "[1, 2]\n\"text\"\n",
+			expected: &artifact.DatasetTable{Format: FormatJSONL, Files: 1, Rows: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			table, err := InspectFile(path)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, table)
+			}
+		})
+	}
+}
+
+func TestInspectFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid.jsonl":   "{\"a\": 1}\n{\"a\": \n",
+		"trailing.jsonl":  "{\"a\": 1} {\"b\": 2}\n",
+		"invalid.parquet": "not a parquet file",
+		"data.txt":        "a,b\n1,2\n",
+	}
+	for file, contents := range tests {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), file)
+			if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := InspectFile(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAddTable(t *testing.T) {
+	columns := []artifact.DatasetColumn{{Name: "a", Type: "integer"}}
+	var tables []artifact.DatasetTable
+	tables = AddTable(tables, artifact.DatasetTable{Format: FormatCSV, Files: 1, Rows: 10, Columns: columns})
+	tables = AddTable(tables, artifact.DatasetTable{Format: FormatJSONL, Files: 1, Rows: 5, Columns: columns})
+	tables = AddTable(tables, artifact.DatasetTable{Format: FormatCSV, Files: 1, Rows: 20, Columns: columns})
+	tables = AddTable(tables, artifact.DatasetTable{Format: FormatCSV, Files: 1, Rows: 1})
+	assert.Equal(t, []artifact.DatasetTable{
+		{Format: FormatCSV, Files: 2, Rows: 30, Columns: columns},
+		{Format: FormatJSONL, Files: 1, Rows: 5, Columns: columns},
+		{Format: FormatCSV, Files: 1, Rows: 1},
+	}, tables)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// inspectJSONL reads a file containing one JSON value per line. Each non-empty line is counted as a row,
+// and columns are the keys of the objects in the first sampleRows rows, in the order they are first seen.
+func inspectJSONL(r io.Reader) (*artifact.DatasetTable, error) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	types := newColumnTypes()
+	table := &artifact.DatasetTable{}
+	var line []byte
+	nonEmpty := false
+	for {
+		// Lines may be longer than the reader's buffer, so they are read in chunks
+		chunk, err := reader.ReadSlice('\n')
+		if len(bytes.TrimSpace(chunk)) > 0 {
+			nonEmpty = true
+		}
+		if table.Rows < sampleRows {
+			line = append(line, chunk...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if nonEmpty {
+			if table.Rows < sampleRows {
+				if sampleErr := addJSONLine(types, line); sampleErr != nil {
+					return nil, fmt.Errorf("failed to parse line %d: %w", table.Rows+1, sampleErr)
+				}
+			}
+			table.Rows++
+		}
+		line = line[:0]
+		nonEmpty = false
+		if err != nil {
+			break
+		}
+	}
+	table.Columns = types.columns()
+	return table, nil
+}
+
+// addJSONLine adds the keys in line, if it contains a JSON object, to types. Keys are read in order, as
+// decoding to a map would lose the order in which they appear.
+func addJSONLine(types *columnTypes, line []byte) error {
+	line = bytes.TrimSpace(line)
+	if line[0] != '{' {
+		// Other values are valid JSON lines, but do not have columns
+		if !json.Valid(line) {
+			return fmt.Errorf("invalid JSON value")
+		}
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := keyToken.(string)
+		if !ok {
+			return fmt.Errorf("invalid object key %v", keyToken)
+		}
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		types.add(key, jsonValueType(value))
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after JSON object")
+	}
+	return nil
+}
+
+func jsonValueType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typeString
+	case bool:
+		return typeBoolean
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return typeNumber
+		}
+		return typeInteger
+	case map[string]any:
+		return typeObject
+	case []any:
+		return typeArray
+	default:
+		return typeMixed
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+const (
+	parquetMagic = "PAR1"
+	// maxParquetFooterSize limits the size of the footer that is read, to avoid allocating large buffers
+	// for corrupted files
+	maxParquetFooterSize = 64 << 20
+)
+
+// Names of parquet physical types, indexed by the type's value in the file metadata
+var parquetPhysicalTypes = []string{"boolean", "int32", "int64", "int96", "float", "double", "binary", "fixed_len_byte_array"}
+
+// Names of parquet logical types, keyed by the field ID of the type in the LogicalType union
+var parquetLogicalTypes = map[int16]string{
+	1: "string", 2: "map", 3: "list", 4: "enum", 5: "decimal", 6: "date", 7: "time", 8: "timestamp",
+	10: "integer", 12: "json", 13: "bson", 14: "uuid", 15: "float16",
+}
+
+// Names of parquet converted types (used by older writers instead of logical types), keyed by value
+var parquetConvertedTypes = map[int64]string{
+	0: "string", 1: "map", 2: "map", 3: "list", 4: "enum", 5: "decimal", 6: "date", 7: "time", 8: "time",
+	9: "timestamp", 10: "timestamp", 19: "json", 20: "bson", 21: "interval",
+}
+
+// parquetSchemaElement holds the fields of a parquet SchemaElement that are used to describe columns
+type parquetSchemaElement struct {
+	name          string
+	physicalType  int64
+	hasType       bool
+	numChildren   int64
+	convertedType int64
+	hasConverted  bool
+	logicalType   string
+}
+
+// inspectParquet reads the metadata in the footer of a parquet file, which includes the number of rows and
+// the schema, without reading the data in the file. Columns are the top-level fields in the schema.
+func inspectParquet(r io.ReaderAt, size int64) (*artifact.DatasetTable, error) {
+	if size < int64(2*len(parquetMagic)+4) {
+		return nil, fmt.Errorf("file is too small to be a parquet file")
+	}
+	tail := make([]byte, 8)
+	if _, err := r.ReadAt(tail, size-8); err != nil {
+		return nil, err
+	}
+	if string(tail[4:]) != parquetMagic {
+		return nil, fmt.Errorf("file is not a parquet file")
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if footerSize > size-12 || footerSize > maxParquetFooterSize {
+		return nil, fmt.Errorf("invalid parquet footer size %d", footerSize)
+	}
+	footer := make([]byte, footerSize)
+	if _, err := r.ReadAt(footer, size-8-footerSize); err != nil {
+		return nil, err
+	}
+
+	table := &artifact.DatasetTable{}
+	var schema []parquetSchemaElement
+	reader := &thriftReader{buf: footer}
+	err := reader.readStruct(func(id int16, fieldType byte) error {
+		switch {
+		case id == 2 && fieldType == thriftList:
+			return reader.readList(func(elemType byte) error {
+				element, err := readParquetSchemaElement(reader, elemType)
+				schema = append(schema, element)
+				return err
+			})
+		case id == 3 && fieldType == thriftI64:
+			rows, err := reader.readZigzag()
+			table.Rows = rows
+			return err
+		default:
+			return reader.skip(fieldType, 0)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet metadata: %w", err)
+	}
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("parquet file has no schema")
+	}
+
+	// The schema is a flattened tree, where the first element is the root and each element is followed by
+	// its children. Only the root's direct children are listed as columns.
+	idx := 1
+	for i := int64(0); i < schema[0].numChildren; i++ {
+		if idx >= len(schema) {
+			return nil, fmt.Errorf("invalid parquet schema")
+		}
+		element := schema[idx]
+		table.Columns = append(table.Columns, artifact.DatasetColumn{Name: element.name, Type: element.typeName()})
+		next, err := parquetSubtreeEnd(schema, idx, 0)
+		if err != nil {
+			return nil, err
+		}
+		idx = next
+	}
+	return table, nil
+}
+
+func readParquetSchemaElement(reader *thriftReader, elemType byte) (parquetSchemaElement, error) {
+	element := parquetSchemaElement{}
+	if elemType != thriftStruct {
+		return element, fmt.Errorf("invalid parquet schema element")
+	}
+	err := reader.readStruct(func(id int16, fieldType byte) error {
+		var err error
+		switch {
+		case id == 1 && fieldType == thriftI32:
+			element.physicalType, err = reader.readZigzag()
+			element.hasType = true
+		case id == 4 && fieldType == thriftBinary:
+			var name []byte
+			name, err = reader.readBinary()
+			element.name = string(name)
+		case id == 5 && fieldType == thriftI32:
+			element.numChildren, err = reader.readZigzag()
+		case id == 6 && fieldType == thriftI32:
+			element.convertedType, err = reader.readZigzag()
+			element.hasConverted = true
+		case id == 10 && fieldType == thriftStruct:
+			// LogicalType is a union; the ID of the field that is set identifies the type
+			err = reader.readStruct(func(typeID int16, typeFieldType byte) error {
+				element.logicalType = parquetLogicalTypes[typeID]
+				return reader.skip(typeFieldType, 0)
+			})
+		default:
+			err = reader.skip(fieldType, 0)
+		}
+		return err
+	})
+	return element, err
+}
+
+func (e parquetSchemaElement) typeName() string {
+	if e.logicalType != "" && e.logicalType != "integer" {
+		return e.logicalType
+	}
+	if name, ok := parquetConvertedTypes[e.convertedType]; ok && e.hasConverted {
+		return name
+	}
+	if !e.hasType {
+		return "group"
+	}
+	if e.physicalType >= 0 && e.physicalType < int64(len(parquetPhysicalTypes)) {
+		return parquetPhysicalTypes[e.physicalType]
+	}
+	return "unknown"
+}
+
+// parquetSubtreeEnd returns the index following the subtree rooted at schema[idx].
+func parquetSubtreeEnd(schema []parquetSchemaElement, idx, depth int) (int, error) {
+	if depth > maxThriftDepth {
+		return 0, fmt.Errorf("parquet schema is nested too deeply")
+	}
+	end := idx + 1
+	for i := int64(0); i < schema[idx].numChildren; i++ {
+		if end >= len(schema) {
+			return 0, fmt.Errorf("invalid parquet schema")
+		}
+		var err error
+		end, err = parquetSubtreeEnd(schema, end, depth+1)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return end, nil
+}
+
+// Field types in the Thrift compact protocol, used to encode parquet metadata
+const (
+	thriftStop      = 0
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftMap       = 11
+	thriftStruct    = 12
+)
+
+// maxThriftDepth limits nesting of Thrift structures, to avoid unbounded recursion for corrupted files
+const maxThriftDepth = 64
+
+var errThriftEOF = errors.New("unexpected end of metadata")
+
+// thriftReader decodes values encoded with the Thrift compact protocol. Only the operations needed to read
+// parquet file metadata are supported.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftEOF
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) readVarint() (uint64, error) {
+	value, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftEOF
+	}
+	r.pos += n
+	return value, nil
+}
+
+func (r *thriftReader) readZigzag() (int64, error) {
+	value, err := r.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(value>>1) ^ -int64(value&1), nil
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	length, err := r.readVarint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(r.buf)-r.pos) {
+		return nil, errThriftEOF
+	}
+	value := r.buf[r.pos : r.pos+int(length)]
+	r.pos += int(length)
+	return value, nil
+}
+
+// readStruct reads the fields of a struct, calling field with the ID and type of each field. The field
+// function must read or skip the field's value.
+func (r *thriftReader) readStruct(field func(id int16, fieldType byte) error) error {
+	var lastID int16
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		fieldType := header & 0x0f
+		if fieldType == thriftStop {
+			return nil
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			longID, err := r.readZigzag()
+			if err != nil {
+				return err
+			}
+			if longID < math.MinInt16 || longID > math.MaxInt16 {
+				return fmt.Errorf("invalid field ID %d", longID)
+			}
+			id = int16(longID)
+		}
+		lastID = id
+		if err := field(id, fieldType); err != nil {
+			return err
+		}
+	}
+}
+
+// readList reads a list or set, calling elem for each element with the type of the list's elements. The
+// elem function must read or skip the element.
+func (r *thriftReader) readList(elem func(elemType byte) error) error {
+	header, err := r.readByte()
+	if err != nil {
+		return err
+	}
+	size := uint64(header >> 4)
+	elemType := header & 0x0f
+	if size == 15 {
+		if size, err = r.readVarint(); err != nil {
+			return err
+		}
+	}
+	// Each element takes at least one byte, so larger sizes can only come from corrupted metadata
+	if size > uint64(len(r.buf)-r.pos) {
+		return errThriftEOF
+	}
+	for i := uint64(0); i < size; i++ {
+		if err := elem(elemType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip reads and discards a value of type fieldType. Booleans in structs are stored in the field type, so
+// skipping them reads nothing; booleans in lists and maps are stored as a byte.
+func (r *thriftReader) skip(fieldType byte, depth int) error {
+	if depth > maxThriftDepth {
+		return fmt.Errorf("metadata is nested too deeply")
+	}
+	switch fieldType {
+	case thriftBoolTrue, thriftBoolFalse:
+		return nil
+	case thriftByte:
+		_, err := r.readByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := r.readVarint()
+		return err
+	case thriftDouble:
+		if len(r.buf)-r.pos < 8 {
+			return errThriftEOF
+		}
+		r.pos += 8
+		return nil
+	case thriftBinary:
+		_, err := r.readBinary()
+		return err
+	case thriftList, thriftSet:
+		return r.readList(func(elemType byte) error {
+			return r.skipElement(elemType, depth+1)
+		})
+	case thriftMap:
+		size, err := r.readVarint()
+		if err != nil || size == 0 {
+			return err
+		}
+		if size > uint64(len(r.buf)-r.pos) {
+			return errThriftEOF
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < size; i++ {
+			if err := r.skipElement(types>>4, depth+1); err != nil {
+				return err
+			}
+			if err := r.skipElement(types&0x0f, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		return r.readStruct(func(_ int16, fieldType byte) error {
+			return r.skip(fieldType, depth+1)
+		})
+	default:
+		return fmt.Errorf("invalid field type %d", fieldType)
+	}
+}
+
+// skipElement skips an element of a list or map, where booleans are stored as a byte.
+func (r *thriftReader) skipElement(elemType byte, depth int) error {
+	if elemType == thriftBoolTrue || elemType == thriftBoolFalse {
+		_, err := r.readByte()
+		return err
+	}
+	return r.skip(elemType, depth)
+}
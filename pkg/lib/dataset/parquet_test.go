@@ -0,0 +1,169 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// thriftWriter encodes values with the Thrift compact protocol, to create parquet metadata for tests.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	w.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	lastID := w.lastIDs[len(w.lastIDs)-1]
+	if delta := id - lastID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.zigzag(int64(id))
+	}
+	w.lastIDs[len(w.lastIDs)-1] = id
+}
+
+func (w *thriftWriter) beginStruct() { w.lastIDs = append(w.lastIDs, 0) }
+
+func (w *thriftWriter) endStruct() {
+	w.buf.WriteByte(thriftStop)
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+func (w *thriftWriter) i32Field(id int16, v int64) {
+	w.field(id, thriftI32)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) stringField(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+type testSchemaElement struct {
+	name          string
+	physicalType  int64
+	numChildren   int64
+	convertedType int64
+	logicalType   int16
+}
+
+// testParquetFile returns the contents of a parquet file with no data, and the given metadata. Fields that
+// are not used when inspecting files are included to check that they are skipped.
+func testParquetFile(numRows int64, schema []testSchemaElement) []byte {
+	w := &thriftWriter{}
+	w.beginStruct()
+	w.i32Field(1, 2) // version
+	w.field(2, thriftList)
+	w.buf.WriteByte(15<<4 | thriftStruct)
+	w.varint(uint64(len(schema)))
+	for _, element := range schema {
+		w.beginStruct()
+		if element.physicalType >= 0 {
+			w.i32Field(1, element.physicalType)
+		}
+		w.i32Field(3, 1) // repetition type
+		w.stringField(4, element.name)
+		if element.numChildren > 0 {
+			w.i32Field(5, element.numChildren)
+		}
+		if element.convertedType >= 0 {
+			w.i32Field(6, element.convertedType)
+		}
+		if element.logicalType > 0 {
+			w.field(10, thriftStruct)
+			w.beginStruct()
+			w.field(element.logicalType, thriftStruct)
+			w.beginStruct()
+			w.field(1, thriftBoolTrue)
+			w.endStruct()
+			w.endStruct()
+		}
+		w.endStruct()
+	}
+	w.field(3, thriftI64)
+	w.zigzag(numRows)
+	w.field(4, thriftList) // row groups
+	w.buf.WriteByte(0<<4 | thriftStruct)
+	w.field(5, thriftList) // key-value metadata
+	w.buf.WriteByte(1<<4 | thriftStruct)
+	w.beginStruct()
+	w.stringField(1, "writer")
+	w.stringField(2, "test")
+	w.endStruct()
+	w.stringField(6, "created by test")
+	w.endStruct()
+
+	file := &bytes.Buffer{}
+	file.WriteString(parquetMagic)
+	file.Write(w.buf.Bytes())
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(w.buf.Len())))
+	file.WriteString(parquetMagic)
+	return file.Bytes()
+}
+
+func TestInspectParquet(t *testing.T) {
+	schema := []testSchemaElement{
+		{name: "schema", physicalType: -1, numChildren: 4, convertedType: -1},
+		{name: "id", physicalType: 2, convertedType: -1},
+		{name: "text", physicalType: 6, convertedType: 0},
+		{name: "embedding", physicalType: -1, numChildren: 1, convertedType: 3, logicalType: 3},
+		{name: "list", physicalType: -1, numChildren: 1, convertedType: -1},
+		{name: "element", physicalType: 4, convertedType: -1},
+		{name: "created", physicalType: 2, convertedType: -1, logicalType: 8},
+	}
+	contents := testParquetFile(1234, schema)
+	table, err := inspectParquet(bytes.NewReader(contents), int64(len(contents)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, &artifact.DatasetTable{
+			Rows: 1234,
+			Columns: []artifact.DatasetColumn{
+				{Name: "id", Type: "int64"},
+				{Name: "text", Type: "string"},
+				{Name: "embedding", Type: "list"},
+				{Name: "created", Type: "timestamp"},
+			},
+		}, table)
+	}
+
+	// Corrupted metadata should return an error rather than panicking
+	for cut := 1; cut < len(contents)-8; cut++ {
+		truncated := append(append([]byte{}, contents[:len(contents)-8-cut]...), contents[len(contents)-8:]...)
+		binary.LittleEndian.PutUint32(truncated[len(truncated)-8:], uint32(len(contents)-12-cut))
+		_, err := inspectParquet(bytes.NewReader(truncated), int64(len(truncated)))
+		assert.Error(t, err)
+	}
+
+	invalidSchema := testParquetFile(1, []testSchemaElement{{name: "schema", physicalType: -1, numChildren: 3, convertedType: -1}})
+	_, err = inspectParquet(bytes.NewReader(invalidSchema), int64(len(invalidSchema)))
+	assert.Error(t, err)
+}
@@ -40,6 +40,7 @@ type CacheSubDir string
 const (
 	CachePackSubdir   CacheSubDir = "pack"
 	CacheImportSubdir CacheSubDir = "import"
+	CacheCheckSubdir  CacheSubDir = "check"
 )
 
 // MkCacheDir creates a directory within configHome to be used for temporary storage and returns a function that can
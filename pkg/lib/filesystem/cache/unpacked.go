@@ -0,0 +1,127 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// unpackedHomeDir is the directory used to store extracted layers that are shared between
+// unpacked modelkits. It is separate from the temporary cache directory as files in it are
+// expected to persist.
+var unpackedHomeDir = filepath.Join(os.TempDir(), "unpacked")
+
+func SetUnpackedCacheHome(unpackedHome string) {
+	unpackedHomeDir = unpackedHome
+}
+
+// UnpackedLayerDir returns the directory where the extracted contents of the layer with
+// uncompressed digest diffId are stored.
+func UnpackedLayerDir(diffId digest.Digest) string {
+	return filepath.Join(unpackedHomeDir, diffId.Algorithm().String(), diffId.Encoded())
+}
+
+// MkUnpackedTempDir creates a temporary directory within the unpacked layer cache that a layer
+// can be extracted to before being moved to its final location via os.Rename.
+func MkUnpackedTempDir() (tempDir string, cleanup func(), err error) {
+	if err := os.MkdirAll(unpackedHomeDir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create cache directory %s: %w", unpackedHomeDir, err)
+	}
+	tempDir, err = os.MkdirTemp(unpackedHomeDir, ".tmp_")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory in %s: %w", unpackedHomeDir, err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			output.Logf(output.LogLevelWarn, "Failed to remove temporary directory %s: %s", tempDir, err)
+		}
+	}
+	return tempDir, cleanup, nil
+}
+
+// StatUnpackedCache returns the number of layers stored in the unpacked layer cache and their
+// total size on disk. Hard linked files are only counted once.
+func StatUnpackedCache() (numLayers int, totalSize int64, err error) {
+	algDirs, err := os.ReadDir(unpackedHomeDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("failed to read unpacked layer cache: %w", err)
+	}
+	for _, algDir := range algDirs {
+		if !algDir.IsDir() || !digest.Algorithm(algDir.Name()).Available() {
+			continue
+		}
+		layerDirs, err := os.ReadDir(filepath.Join(unpackedHomeDir, algDir.Name()))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read unpacked layer cache: %w", err)
+		}
+		numLayers += len(layerDirs)
+	}
+
+	seen := map[string]bool{}
+	err = filepath.WalkDir(unpackedHomeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to examine %s: %w", path, err)
+		}
+		if key, ok := filesystem.HardlinkKey(info); ok {
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		totalSize += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return numLayers, totalSize, nil
+}
+
+// ClearUnpackedCache removes all extracted layers from the unpacked layer cache. Files that
+// were unpacked as symlinks to the cache will no longer resolve.
+func ClearUnpackedCache() error {
+	if _, err := os.Stat(unpackedHomeDir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read unpacked layer cache: %w", err)
+	}
+	output.Debugf("Removing unpacked layer cache %s", unpackedHomeDir)
+	if err := os.RemoveAll(unpackedHomeDir); err != nil {
+		return fmt.Errorf("failed to remove unpacked layer cache: %w", err)
+	}
+	return nil
+}
@@ -33,9 +33,23 @@ import (
 
 type IgnorePaths interface {
 	Matches(path, layerPath string) (bool, error)
+	// MatchReason is like Matches, but returns why path is excluded from the layer at layerPath. If
+	// path is included in another layer, otherLayer is the path of that layer.
+	MatchReason(path, layerPath string) (reason IgnoreReason, otherLayer string, err error)
 	HasExclusions() bool
 }
 
+// IgnoreReason describes why a path is excluded from a layer
+type IgnoreReason int
+
+const (
+	NotIgnored IgnoreReason = iota
+	// IgnoredByIgnoreFile means the path matches a pattern in the .kitignore file
+	IgnoredByIgnoreFile
+	// IgnoredByOtherLayer means the path is included in another layer in the Kitfile
+	IgnoredByOtherLayer
+)
+
 func NewIgnoreFromContext(contextDir string, kitfile *artifact.KitFile, extraLayers ...string) (IgnorePaths, error) {
 	kitIgnorePaths, err := readIgnoreFile(contextDir)
 	if err != nil {
@@ -46,7 +60,7 @@ func NewIgnoreFromContext(contextDir string, kitfile *artifact.KitFile, extraLay
 
 func NewIgnore(kitIgnorePaths []string, kitfile *artifact.KitFile, extraLayers ...string) (IgnorePaths, error) {
 	kitIgnorePaths = append(kitIgnorePaths, constants.DefaultKitfileNames()...)
-	kitIgnorePaths = append(kitIgnorePaths, constants.IgnoreFileName)
+	kitIgnorePaths = append(kitIgnorePaths, constants.IgnoreFileName, constants.KitfileLockName)
 	kitIgnorePM, err := patternmatcher.New(kitIgnorePaths)
 	if err != nil {
 		return nil, fmt.Errorf("invalid %s file: %w", constants.IgnoreFileName, err)
@@ -66,14 +80,22 @@ type ignorePaths struct {
 }
 
 func (pm *ignorePaths) Matches(path, layerPath string) (bool, error) {
+	reason, _, err := pm.MatchReason(path, layerPath)
+	if err != nil {
+		return false, err
+	}
+	return reason != NotIgnored, nil
+}
+
+func (pm *ignorePaths) MatchReason(path, layerPath string) (IgnoreReason, string, error) {
 	path = cleanPath(path)
 	layerPath = cleanPath(layerPath)
 	ignoreFileMatches, err := pm.ignoreFileMatcher.MatchesOrParentMatches(path)
 	if err != nil {
-		return false, err
+		return NotIgnored, "", err
 	}
 	if ignoreFileMatches {
-		return true, nil
+		return IgnoredByIgnoreFile, "", nil
 	}
 	// ignore file doesn't exclude the current path, check if it should be excluded
 	// since it's included in another layer
@@ -86,11 +108,11 @@ func (pm *ignorePaths) Matches(path, layerPath string) (bool, error) {
 		}
 		if strings.HasPrefix(path, layer) {
 			// The current path is included in another layer that is a subdirectory of the current layer
-			return true, nil
+			return IgnoredByOtherLayer, layer, nil
 		}
 	}
 
-	return false, nil
+	return NotIgnored, "", nil
 }
 
 func (pm *ignorePaths) HasExclusions() bool {
@@ -105,3 +105,27 @@ func TestIgnoreMatches(t *testing.T) {
 		})
 	}
 }
+
+func TestIgnoreMatchReason(t *testing.T) {
+	testKitfile := &artifact.KitFile{
+		Code: []artifact.Code{{Path: "main"}, {Path: "main/subdir"}},
+	}
+	ignore, err := NewIgnore([]string{"**/*.log"}, testKitfile)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	reason, otherLayer, err := ignore.MatchReason("main/train.log", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, IgnoredByIgnoreFile, reason)
+	assert.Empty(t, otherLayer)
+
+	reason, otherLayer, err = ignore.MatchReason("main/subdir/testfile.txt", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, IgnoredByOtherLayer, reason)
+	assert.Equal(t, "main/subdir", otherLayer)
+
+	reason, _, err = ignore.MatchReason("main/testfile.txt", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, NotIgnored, reason)
+}
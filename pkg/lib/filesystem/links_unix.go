@@ -0,0 +1,36 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// HardlinkKey returns a key that uniquely identifies the underlying file for fi, if fi
+// refers to a file with more than one hard link. Files that share a key are hard links
+// to the same content.
+func HardlinkKey(fi os.FileInfo) (key string, ok bool) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino), true
+}
@@ -17,6 +17,7 @@
 package filesystem
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -26,6 +27,10 @@ import (
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 )
 
+// ErrReflinkUnsupported is returned by Reflink when the platform or filesystem does not
+// support copy-on-write clones of files.
+var ErrReflinkUnsupported = errors.New("reflinks are not supported")
+
 // VerifySubpath checks that filepath.Join(context, subDir) is a subdirectory of context, following
 // symlinks if present.
 func VerifySubpath(context, subDir string) (absPath, relPath string, err error) {
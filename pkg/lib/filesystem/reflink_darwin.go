@@ -0,0 +1,38 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build darwin
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Reflink creates a copy-on-write clone of src at dst. If the filesystem does not
+// support cloning files, an error wrapping ErrReflinkUnsupported is returned.
+func Reflink(src, dst string) error {
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) {
+			return fmt.Errorf("%w: %w", ErrReflinkUnsupported, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Reflink creates a copy-on-write clone of src at dst. If the filesystem does not
+// support cloning files, an error wrapping ErrReflinkUnsupported is returned.
+func Reflink(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, srcInfo.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	cloneErr := unix.IoctlFileClone(int(dstFile.Fd()), int(srcFile.Fd()))
+	if err := dstFile.Close(); err != nil && cloneErr == nil {
+		cloneErr = err
+	}
+	if cloneErr != nil {
+		_ = os.Remove(dst)
+		if errors.Is(cloneErr, unix.EOPNOTSUPP) || errors.Is(cloneErr, unix.EXDEV) || errors.Is(cloneErr, unix.EINVAL) || errors.Is(cloneErr, unix.ENOTTY) {
+			return fmt.Errorf("%w: %w", ErrReflinkUnsupported, cloneErr)
+		}
+		return cloneErr
+	}
+	return nil
+}
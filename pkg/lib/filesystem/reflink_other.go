@@ -0,0 +1,25 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux && !darwin
+
+package filesystem
+
+// Reflink creates a copy-on-write clone of src at dst. Cloning files is not supported
+// on this platform, so this always returns ErrReflinkUnsupported.
+func Reflink(src, dst string) error {
+	return ErrReflinkUnsupported
+}
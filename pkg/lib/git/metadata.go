@@ -0,0 +1,92 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+// RepoInfo describes the git commit checked out in a directory.
+type RepoInfo struct {
+	// Source is the URL of the 'origin' remote, if any, with credentials removed
+	Source     string
+	Revision   string
+	CommitTime time.Time
+	// Dirty is true if dir contains changes that are not committed, including untracked files
+	Dirty bool
+}
+
+// GetRepoInfo returns information about the git repository containing dir. If git is not installed,
+// dir is not within a git repository, or the repository has no commits, nil is returned.
+func GetRepoInfo(dir string) *RepoInfo {
+	revision, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		output.Debugf("Not reading git metadata for %s: %s", dir, err)
+		return nil
+	}
+	commitTime, err := runGit(dir, "log", "-1", "--format=%ct")
+	if err != nil {
+		output.Debugf("Failed to read git commit time: %s", err)
+		return nil
+	}
+	seconds, err := strconv.ParseInt(commitTime, 10, 64)
+	if err != nil {
+		output.Debugf("Failed to parse git commit time %q: %s", commitTime, err)
+		return nil
+	}
+	info := &RepoInfo{
+		Revision:   revision,
+		CommitTime: time.Unix(seconds, 0).UTC(),
+	}
+	if remote, err := runGit(dir, "remote", "get-url", "origin"); err == nil {
+		info.Source = stripCredentials(remote)
+	}
+	// Only changes within dir are relevant, as nothing outside it is packed
+	if status, err := runGit(dir, "status", "--porcelain", "--", "."); err != nil {
+		output.Debugf("Failed to read git status: %s", err)
+		info.Dirty = true
+	} else {
+		info.Dirty = status != ""
+	}
+	return info
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// stripCredentials removes any username and password from a remote URL. Remotes that are
+// not URLs (e.g. scp-style 'git@host:path' remotes) are returned unchanged.
+func stripCredentials(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelCardFile is the path of the model card in a Hugging Face repository
+const ModelCardFile = "README.md"
+
+var (
+	headingRegexp     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	htmlCommentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// Placeholder text used in the Hugging Face model card template for sections that were not filled in
+	placeholderRegexp = regexp.MustCompile(`(?i)\[?more information needed\]?`)
+)
+
+// modelCardMetadata is the subset of the YAML front matter of a Hugging Face model card used to populate
+// the Kitfile. See https://huggingface.co/docs/hub/model-cards#model-card-metadata
+type modelCardMetadata struct {
+	Datasets   stringList `yaml:"datasets"`
+	ModelIndex []struct {
+		Results []struct {
+			Dataset struct {
+				Name  string `yaml:"name"`
+				Type  string `yaml:"type"`
+				Split string `yaml:"split"`
+			} `yaml:"dataset"`
+			Metrics []struct {
+				Type  string `yaml:"type"`
+				Name  string `yaml:"name"`
+				Value any    `yaml:"value"`
+			} `yaml:"metrics"`
+		} `yaml:"results"`
+	} `yaml:"model-index"`
+}
+
+// stringList is a list of strings in YAML that may also be written as a single string
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = []string{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// ParseModelCard reads the model card and evaluation results from the README of a Hugging Face repository.
+// Training datasets and evaluation results are read from the YAML front matter, while the intended use and
+// limitations are read from sections of the README with matching headings. If the README does not describe
+// any of these, the returned model card is nil. An error is returned only if the front matter is not valid.
+func ParseModelCard(readme []byte) (*artifact.ModelCard, []artifact.Metric, error) {
+	frontMatter, body := splitFrontMatter(string(readme))
+	metadata := &modelCardMetadata{}
+	if err := yaml.Unmarshal([]byte(frontMatter), metadata); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse model card metadata: %w", err)
+	}
+
+	modelCard := &artifact.ModelCard{}
+	for _, dataset := range metadata.Datasets {
+		if dataset = strings.TrimSpace(dataset); dataset != "" {
+			modelCard.TrainingData = append(modelCard.TrainingData, dataset)
+		}
+	}
+	for _, section := range markdownSections(body) {
+		heading := strings.ToLower(section.heading)
+		switch {
+		case strings.Contains(heading, "intended use") || strings.Contains(heading, "direct use"):
+			if modelCard.IntendedUse == "" {
+				modelCard.IntendedUse = section.content
+			}
+		case strings.Contains(heading, "limitation"):
+			if modelCard.Limitations == "" {
+				modelCard.Limitations = section.content
+			}
+		}
+	}
+
+	var metrics []artifact.Metric
+	type metricKey struct{ name, dataset, split string }
+	seen := map[metricKey]bool{}
+	for _, model := range metadata.ModelIndex {
+		for _, result := range model.Results {
+			dataset := firstNonEmpty(result.Dataset.Type, result.Dataset.Name)
+			for _, metric := range result.Metrics {
+				name := firstNonEmpty(metric.Type, metric.Name)
+				value, ok := metricValue(metric.Value)
+				if name == "" || !ok {
+					output.Debugf("Skipping metric %q with value %v in model card", name, metric.Value)
+					continue
+				}
+				key := metricKey{name, dataset, result.Dataset.Split}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				metrics = append(metrics, artifact.Metric{
+					Name:    name,
+					Value:   value,
+					Dataset: dataset,
+					Split:   result.Dataset.Split,
+				})
+			}
+		}
+	}
+
+	if modelCard.IntendedUse == "" && modelCard.Limitations == "" && len(modelCard.TrainingData) == 0 {
+		modelCard = nil
+	}
+	return modelCard, metrics, nil
+}
+
+// splitFrontMatter returns the YAML front matter of a markdown document, delimited by '---' lines at the
+// start of the document, and the rest of the document.
+func splitFrontMatter(doc string) (frontMatter, body string) {
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	if !strings.HasPrefix(doc, "---\n") {
+		return "", doc
+	}
+	rest := doc[len("---\n"):]
+	if strings.HasPrefix(rest, "---\n") {
+		return "", rest[len("---\n"):]
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end == -1 {
+		if strings.HasSuffix(rest, "\n---") {
+			return strings.TrimSuffix(rest, "\n---"), ""
+		}
+		return "", doc
+	}
+	return rest[:end], rest[end+len("\n---\n"):]
+}
+
+type markdownSection struct {
+	heading string
+	content string
+}
+
+// markdownSections returns the sections of a markdown document in the order they appear. A section extends
+// until the next heading of the same or a higher level, so it includes any subsections. Comments and
+// placeholder text are removed, and sections that are empty as a result are omitted.
+func markdownSections(body string) []markdownSection {
+	type heading struct {
+		level int
+		title string
+		start int
+	}
+	var headings []heading
+	lines := strings.Split(body, "\n")
+	inCodeBlock := false
+	for idx, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock {
+			continue
+		}
+		if match := headingRegexp.FindStringSubmatch(line); match != nil {
+			headings = append(headings, heading{level: len(match[1]), title: match[2], start: idx})
+		}
+	}
+
+	var sections []markdownSection
+	for idx, h := range headings {
+		end := len(lines)
+		for _, next := range headings[idx+1:] {
+			if next.level <= h.level {
+				end = next.start
+				break
+			}
+		}
+		content := strings.Join(lines[h.start+1:end], "\n")
+		content = htmlCommentRegexp.ReplaceAllString(content, "")
+		content = placeholderRegexp.ReplaceAllString(content, "")
+		if content = strings.TrimSpace(content); content != "" && isMeaningful(content) {
+			sections = append(sections, markdownSection{heading: h.title, content: content})
+		}
+	}
+	return sections
+}
+
+// isMeaningful returns false if content consists only of headings, e.g. for a section with only empty
+// subsections.
+func isMeaningful(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !headingRegexp.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// metricValue converts a metric value from a model card into a number. Values may be written as numbers or
+// strings, optionally as percentages.
+func metricValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		v = strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+func firstNonEmpty(strs ...string) string {
+	for _, s := range strs {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hf
+
+import (
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseModelCard(t *testing.T) {
+	tests := []struct {
+		name          string
+		readme        string
+		wantModelCard *artifact.ModelCard
+		wantMetrics   []artifact.Metric
+		wantErr       bool
+	}{
+		{
+			name: "front matter and sections",
+			readme: `---
+license: apache-2.0
+datasets:
+  - imdb
+  - glue
+model-index:
+  - name: my-model
+    results:
+      - task:
+          type: text-classification
+        dataset:
+          name: IMDB
+          type: imdb
+          split: test
+        metrics:
+          - type: accuracy
+            value: 0.93
+          - type: f1
+            value: "91.5%"
+          - type: accuracy
+            value: 0.5
+          - name: Notes
+            value: not a number
+---
+# My model
+
+Some description.
+
+## Uses
+
+### Direct Use
+
+Classifying the sentiment of movie reviews.
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+### Out-of-Scope Use
+
+<!-- Describe out-of-scope uses -->
+[More Information Needed]
+
+## Bias, Risks, and Limitations
+
+Only trained on English text.
+`,
+			wantModelCard: &artifact.ModelCard{
+				IntendedUse:  "Classifying the sentiment of movie reviews.\n\n```\n# not a heading\n```",
+				Limitations:  "Only trained on English text.",
+				TrainingData: []string{"imdb", "glue"},
+			},
+			wantMetrics: []artifact.Metric{
+				{Name: "accuracy", Value: 0.93, Dataset: "imdb", Split: "test"},
+				{Name: "f1", Value: 91.5, Dataset: "imdb", Split: "test"},
+			},
+		},
+		{
+			name:          "single dataset",
+			readme:        "---\ndatasets: imdb\n---\n",
+			wantModelCard: &artifact.ModelCard{TrainingData: []string{"imdb"}},
+		},
+		{
+			name:   "placeholder sections",
+			readme: "# Model\n\n## Intended uses & limitations\n\nMore information needed\n",
+		},
+		{
+			name:   "no front matter",
+			readme: "# Model\n\n## Limitations\n\nMay be inaccurate.\n",
+			wantModelCard: &artifact.ModelCard{
+				Limitations: "May be inaccurate.",
+			},
+		},
+		{
+			name:    "invalid front matter",
+			readme:  "---\ndatasets: [imdb\n---\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelCard, metrics, err := ParseModelCard([]byte(tt.readme))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantModelCard, modelCard)
+			assert.Equal(t, tt.wantMetrics, metrics)
+		})
+	}
+}
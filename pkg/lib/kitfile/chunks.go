@@ -0,0 +1,143 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kitops-ml/kitops/pkg/lib/chunk"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// chunkSaver splits large files into content-defined chunks while packing chunked layers. Each
+// chunk is saved as a separate blob, so that chunks shared between versions of a file are only
+// stored and transferred once.
+type chunkSaver struct {
+	ctx     context.Context
+	targets []oras.Target
+	// descs contains a descriptor for each unique chunk saved, in the order they were first seen
+	descs []ocispec.Descriptor
+	seen  map[digest.Digest]bool
+	// numFiles is the number of files that have been chunked
+	numFiles int
+}
+
+func newChunkSaver(ctx context.Context, targets []oras.Target) *chunkSaver {
+	return &chunkSaver{
+		ctx:     ctx,
+		targets: targets,
+		seen:    map[digest.Digest]bool{},
+	}
+}
+
+// shouldChunk returns true if a file should be split into chunks rather than stored in the layer.
+func (s *chunkSaver) shouldChunk(fi os.FileInfo) bool {
+	return s != nil && fi.Mode().IsRegular() && fi.Size() >= chunk.MinFileSize
+}
+
+// writeChunkedFile saves the content of file as chunks and writes a tar entry for it to ptw. The
+// content of the entry is a chunk.FileManifest listing the chunks that make up the file.
+func (s *chunkSaver) writeChunkedFile(file string, fi os.FileInfo, repro *reproducibility, ptw *output.ProgressTar, plog *output.ProgressLogger) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open file for archiving: %w", err)
+	}
+	defer f.Close()
+
+	manifest := chunk.FileManifest{}
+	fileDigester := digest.Canonical.Digester()
+	chunker := chunk.NewChunker(f)
+	for {
+		data, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		fileDigester.Hash().Write(data)
+		desc := ocispec.Descriptor{
+			MediaType: constants.ChunkMediaType.String(),
+			Digest:    digest.FromBytes(data),
+			Size:      int64(len(data)),
+		}
+		if err := s.saveChunk(desc, data); err != nil {
+			return err
+		}
+		manifest.Chunks = append(manifest.Chunks, chunk.Chunk{Digest: desc.Digest, Size: desc.Size})
+		manifest.Size += desc.Size
+		ptw.IncrementProgress(desc.Size)
+	}
+	if manifest.Size != fi.Size() {
+		return fmt.Errorf("error writing file %s: file changed while packing", file)
+	}
+	manifest.Digest = fileDigester.Digest()
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to generate chunk manifest for %s: %w", file, err)
+	}
+
+	header, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return fmt.Errorf("failed to generate header for %s: %w", file, err)
+	}
+	header.Name = file
+	sanitizeTarHeader(header, repro)
+	header.Size = int64(len(manifestBytes))
+	header.Format = tar.FormatPAX
+	header.PAXRecords = map[string]string{chunk.PAXRecordKey: chunk.ManifestVersion}
+	if err := ptw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	if _, err := ptw.WriteUntracked(manifestBytes); err != nil {
+		return fmt.Errorf("failed to add chunk manifest to archive: %w", err)
+	}
+	s.numFiles += 1
+	plog.Debugf("Wrote file %s to tar file as %d chunks", file, len(manifest.Chunks))
+	return nil
+}
+
+// saveChunk saves a chunk to all targets that do not already contain it.
+func (s *chunkSaver) saveChunk(desc ocispec.Descriptor, data []byte) error {
+	if s.seen[desc.Digest] {
+		return nil
+	}
+	for _, target := range s.targets {
+		if exists, err := target.Exists(s.ctx, desc); err != nil {
+			return fmt.Errorf("failed to check if chunk exists in storage: %w", err)
+		} else if exists {
+			continue
+		}
+		if err := target.Push(s.ctx, desc, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to save chunk %s: %w", desc.Digest, err)
+		}
+	}
+	s.seen[desc.Digest] = true
+	s.descs = append(s.descs, desc)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+const (
+	// entropySampleSize is the size of each sample read from files when detecting compression. Up to three
+	// samples are read from each file (from the start, middle, and end of the file).
+	entropySampleSize = 16 << 10
+	// maxEntropySample is the maximum total amount of data sampled from a layer
+	maxEntropySample = 4 << 20
+	// maxCompressibleEntropy is the entropy (in bits per byte) above which content is considered to be
+	// already compressed or otherwise incompressible
+	maxCompressibleEntropy = 7.5
+)
+
+var errSampleComplete = errors.New("sample complete")
+
+// resolveCompression returns the compression to use for the layer at path. If compression is 'auto',
+// a sample of the layer's content is checked to determine if it is worth compressing.
+func resolveCompression(compression, path string, ignore filesystem.IgnorePaths, dereference bool) (string, error) {
+	if compression != constants.AutoCompression {
+		return compression, nil
+	}
+	entropy, err := sampleEntropy(path, ignore, dereference)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect compression for %s: %w", path, err)
+	}
+	resolved := constants.GzipCompression
+	if entropy > maxCompressibleEntropy {
+		resolved = constants.NoneCompression
+	}
+	output.Debugf("Using compression '%s' for %s (sampled entropy: %.2f bits per byte)", resolved, path, entropy)
+	return resolved, nil
+}
+
+// sampleEntropy estimates the Shannon entropy of the content of files in a layer, in bits per byte,
+// by sampling sections of each file until enough data has been read.
+func sampleEntropy(path string, ignore filesystem.IgnorePaths, dereference bool) (float64, error) {
+	var counts [256]int64
+	var total int64
+	buf := make([]byte, entropySampleSize)
+	err := walkLayerFiles(path, ignore, dereference, func(lf layerFile) error {
+		file, fi := lf.path, lf.info
+		if lf.ignored != filesystem.NotIgnored || !fi.Mode().IsRegular() || lf.linkTarget != "" || fi.Size() == 0 {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		defer f.Close()
+
+		offsets := []int64{0}
+		if fi.Size() > 3*entropySampleSize {
+			offsets = append(offsets, fi.Size()/2, fi.Size()-entropySampleSize)
+		}
+		for _, offset := range offsets {
+			n, err := f.ReadAt(buf, offset)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			for _, b := range buf[:n] {
+				counts[b]++
+			}
+			total += int64(n)
+		}
+		if total >= maxEntropySample {
+			return errSampleComplete
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSampleComplete) {
+		return 0, err
+	}
+	return entropy(counts, total), nil
+}
+
+func entropy(counts [256]int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	var result float64
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(total)
+		result -= p * math.Log2(p)
+	}
+	return result
+}
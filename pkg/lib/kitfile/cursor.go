@@ -0,0 +1,111 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"fmt"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+)
+
+// LayerEntry is the Kitfile entry a layer in a modelkit manifest was packed from.
+type LayerEntry struct {
+	// Index is the index of the entry among Kitfile entries of the same type (e.g. in DataSets). It is
+	// zero for the model.
+	Index int
+	// Path is the path of the entry in the Kitfile
+	Path string
+	// LayerInfo is the layer info for this layer, or nil for modelkits packed without layer info. For
+	// entries split into multiple layers, it describes the shard stored in this layer.
+	LayerInfo *artifact.LayerInfo
+	// Shard is the index of this layer among the layers storing the entry
+	Shard int
+}
+
+type cursorEntry struct {
+	index     int
+	path      string
+	layerInfo *artifact.LayerInfo
+}
+
+// LayerCursor matches the layers of a modelkit manifest to the Kitfile entries they were packed from.
+// Layers of each type are stored in the same order as the corresponding entries in the Kitfile. Entries
+// that were split into multiple layers correspond to consecutive layers, and entries that refer to other
+// modelkits do not have layers.
+type LayerCursor struct {
+	entries map[string][]cursorEntry
+	idx     map[string]int
+	shard   map[string]int
+}
+
+// NewLayerCursor returns a LayerCursor for the layers packed from kitfile.
+func NewLayerCursor(kitfile *artifact.KitFile) *LayerCursor {
+	entries := map[string][]cursorEntry{}
+	addEntry := func(baseType string, index int, path string, layerInfo *artifact.LayerInfo) {
+		entries[baseType] = append(entries[baseType], cursorEntry{index, path, layerInfo})
+	}
+	if kitfile.Model != nil {
+		if kitfile.Model.Path != "" && !util.IsModelKitReference(kitfile.Model.Path) {
+			addEntry(constants.ModelType, 0, kitfile.Model.Path, kitfile.Model.LayerInfo)
+		}
+		for idx, part := range kitfile.Model.Parts {
+			addEntry(constants.ModelPartType, idx, part.Path, part.LayerInfo)
+		}
+	}
+	for idx, dataset := range kitfile.DataSets {
+		if !util.IsModelKitReference(dataset.Path) {
+			addEntry(constants.DatasetType, idx, dataset.Path, dataset.LayerInfo)
+		}
+	}
+	for idx, code := range kitfile.Code {
+		if !util.IsModelKitReference(code.Path) {
+			addEntry(constants.CodeType, idx, code.Path, code.LayerInfo)
+		}
+	}
+	for idx, docs := range kitfile.Docs {
+		addEntry(constants.DocsType, idx, docs.Path, docs.LayerInfo)
+	}
+	return &LayerCursor{
+		entries: entries,
+		idx:     map[string]int{},
+		shard:   map[string]int{},
+	}
+}
+
+// Next returns the Kitfile entry for the next layer of type baseType in the manifest.
+func (c *LayerCursor) Next(baseType string) (*LayerEntry, error) {
+	idx := c.idx[baseType]
+	if idx >= len(c.entries[baseType]) {
+		return nil, fmt.Errorf("manifest contains more %s layers than the Kitfile", baseType)
+	}
+	entry := c.entries[baseType][idx]
+	shard := c.shard[baseType]
+	if shard+1 < entry.layerInfo.NumLayers() {
+		c.shard[baseType] = shard + 1
+	} else {
+		c.idx[baseType] = idx + 1
+		c.shard[baseType] = 0
+	}
+	return &LayerEntry{
+		Index:     entry.index,
+		Path:      entry.path,
+		LayerInfo: entry.layerInfo.Shard(shard),
+		Shard:     shard,
+	}, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayerCursor(t *testing.T) {
+	shardedInfo := &artifact.LayerInfo{
+		Digest: "sha256:a",
+		Shards: []artifact.LayerShard{{Digest: "sha256:a"}, {Digest: "sha256:b"}},
+	}
+	kitfile := &artifact.KitFile{
+		Model: &artifact.Model{Path: "org/base:v1"},
+		DataSets: []artifact.DataSet{
+			{Path: "org/eval-data:v1"},
+			{Path: "data", LayerInfo: shardedInfo},
+			{Path: "more-data"},
+		},
+	}
+	cursor := NewLayerCursor(kitfile)
+
+	_, err := cursor.Next(constants.ModelType)
+	assert.Error(t, err, "Model references do not have layers")
+
+	entry, err := cursor.Next(constants.DatasetType)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &LayerEntry{Index: 1, Path: "data", LayerInfo: &artifact.LayerInfo{Digest: "sha256:a"}, Shard: 0}, entry)
+	}
+	entry, err = cursor.Next(constants.DatasetType)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &LayerEntry{Index: 1, Path: "data", LayerInfo: &artifact.LayerInfo{Digest: "sha256:b"}, Shard: 1}, entry)
+	}
+	entry, err = cursor.Next(constants.DatasetType)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &LayerEntry{Index: 2, Path: "more-data"}, entry)
+	}
+	_, err = cursor.Next(constants.DatasetType)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/dataset"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
+)
+
+// AddDatasetMetadata computes metadata describing the files in each dataset in kitfile and stores it in the
+// dataset's Metadata field. Files are selected using the same rules as SaveModel. The schema and number of
+// rows are read from csv, jsonl, and parquet files; files that cannot be read in the format indicated by
+// their extension are counted, but a warning is printed and they are not described in the metadata's tables.
+// Datasets that refer to other modelkits are skipped.
+func AddDatasetMetadata(kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, opts SaveModelOptions) error {
+	for idx, entry := range kitfile.DataSets {
+		if util.IsModelKitReference(entry.Path) {
+			continue
+		}
+		plan, err := planLayer(constants.DatasetType, entry.Name, entry.Path, entry.MaxLayerSize, entry.Compression, ignore, opts)
+		if err != nil {
+			return err
+		}
+		metadata := &artifact.DatasetMetadata{
+			Files: len(plan.Files),
+			Size:  plan.Size,
+		}
+		for _, file := range plan.Files {
+			file = filepath.FromSlash(file)
+			if dataset.FormatForPath(file) == "" {
+				continue
+			}
+			// Links are only read if their targets are packed
+			if fi, err := os.Lstat(file); err != nil || (!fi.Mode().IsRegular() && !opts.Dereference) {
+				continue
+			}
+			table, err := dataset.InspectFile(file)
+			if err != nil {
+				output.Logf(output.LogLevelWarn, "Could not read metadata for dataset %s from %s: %s", entry.Name, file, err)
+				continue
+			}
+			metadata.Tables = dataset.AddTable(metadata.Tables, *table)
+		}
+		output.Debugf("Computed metadata for dataset %s: %d files, %d tables", entry.Name, metadata.Files, len(metadata.Tables))
+		kitfile.DataSets[idx].Metadata = metadata
+	}
+	return nil
+}
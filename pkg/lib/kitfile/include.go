@@ -0,0 +1,241 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const includeKey = "include"
+
+// ResolveIncludes merges the Kitfile fragments listed in the top-level include field of a Kitfile into
+// it. Include paths are relative to contextDir, and fragments may include other fragments. Fragments are
+// merged in order, followed by the Kitfile itself:
+//   - Maps are merged key by key
+//   - Lists are appended, so that included entries come before entries in the including file
+//   - Other values in later files override values in earlier ones; empty (null) values are ignored
+//
+// Fragments that are included more than once (e.g. by two other fragments) are only merged the first time.
+//
+// It returns the merged Kitfile along with the paths of all included files. If the Kitfile does not
+// include any fragments, content is returned unmodified.
+func ResolveIncludes(content []byte, contextDir string) ([]byte, []string, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || findIncludes(doc.Content[0]) == nil {
+		return content, nil, nil
+	}
+	resolver := newIncludeResolver(contextDir)
+	if err := resolver.resolveDocument(doc); err != nil {
+		return nil, nil, err
+	}
+	merged, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to merge included Kitfiles: %w", err)
+	}
+	return merged, resolver.included, nil
+}
+
+type includeResolver struct {
+	contextDir string
+	// stack holds the files currently being resolved, to detect include cycles
+	stack []string
+	// included lists all files included, in the order they were first loaded
+	included []string
+	// merged contains the cleaned absolute paths of all files loaded so far, so that each is merged only once
+	merged map[string]bool
+	// origins maps nodes loaded from included files to the path of the file they were loaded from
+	origins map[*yaml.Node]string
+}
+
+func newIncludeResolver(contextDir string) *includeResolver {
+	return &includeResolver{
+		contextDir: contextDir,
+		stack:      []string{"Kitfile"},
+		merged:     map[string]bool{},
+		origins:    map[*yaml.Node]string{},
+	}
+}
+
+// resolveDocument replaces the root of doc with the result of merging its includes into it.
+func (r *includeResolver) resolveDocument(doc *yaml.Node) error {
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	merged, err := r.resolve(doc.Content[0])
+	if err != nil {
+		return err
+	}
+	doc.Content[0] = merged
+	return nil
+}
+
+func (r *includeResolver) resolve(root *yaml.Node) (*yaml.Node, error) {
+	includesNode := findIncludes(root)
+	if includesNode == nil {
+		return root, nil
+	}
+	var includes []string
+	if err := includesNode.Decode(&includes); err != nil {
+		return nil, fmt.Errorf("include must be a list of paths (line %d)", includesNode.Line)
+	}
+
+	var merged *yaml.Node
+	for _, include := range includes {
+		fragment, err := r.load(include)
+		if err != nil {
+			return nil, err
+		}
+		merged = r.merge(merged, fragment)
+	}
+	return r.merge(merged, r.withoutIncludes(root)), nil
+}
+
+// load reads and resolves the includes of the Kitfile fragment at path, relative to the context directory.
+func (r *includeResolver) load(path string) (*yaml.Node, error) {
+	if !filepath.IsLocal(path) {
+		return nil, fmt.Errorf("included path %s must be relative to and within the context directory", path)
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if slices.Contains(r.stack, path) {
+		return nil, fmt.Errorf("include cycle detected: %s -> %s", strings.Join(r.stack, " -> "), path)
+	}
+	absPath, err := filepath.Abs(filepath.Join(r.contextDir, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve included file %s: %w", path, err)
+	}
+	if r.merged[absPath] {
+		// Merging a fragment again would duplicate its list entries
+		return nil, nil
+	}
+	r.merged[absPath] = true
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included file %s: %w", path, err)
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse included file %s: %w", path, err)
+	}
+	r.included = append(r.included, path)
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("included file %s must contain a map of Kitfile fields", path)
+	}
+	r.markOrigin(root, path)
+
+	r.stack = append(r.stack, path)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+	return r.resolve(root)
+}
+
+func (r *includeResolver) markOrigin(node *yaml.Node, path string) {
+	r.origins[node] = path
+	for _, child := range node.Content {
+		r.markOrigin(child, path)
+	}
+}
+
+// origin returns the included file node was loaded from, or an empty string if it is part of the
+// including Kitfile.
+func (r *includeResolver) origin(node *yaml.Node) string {
+	return r.origins[node]
+}
+
+// findIncludes returns the value of the include field in root, or nil if it is not present.
+func findIncludes(root *yaml.Node) *yaml.Node {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == includeKey {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func (r *includeResolver) withoutIncludes(root *yaml.Node) *yaml.Node {
+	result := *root
+	r.origins[&result] = r.origins[root]
+	result.Content = nil
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != includeKey {
+			result.Content = append(result.Content, root.Content[i], root.Content[i+1])
+		}
+	}
+	return &result
+}
+
+// merge merges override into base, returning the result. Neither node is modified.
+func (r *includeResolver) merge(base, override *yaml.Node) *yaml.Node {
+	if base != nil && base.Kind == yaml.AliasNode {
+		base = base.Alias
+	}
+	if override != nil && override.Kind == yaml.AliasNode {
+		override = override.Alias
+	}
+	if base == nil || isNull(base) {
+		return override
+	}
+	if override == nil || isNull(override) {
+		return base
+	}
+	switch {
+	case base.Kind == yaml.MappingNode && override.Kind == yaml.MappingNode:
+		merged := *override
+		merged.Content = slices.Clone(base.Content)
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+			found := false
+			for j := 0; j+1 < len(merged.Content); j += 2 {
+				if merged.Content[j].Value == key.Value {
+					merged.Content[j+1] = r.merge(merged.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged.Content = append(merged.Content, key, value)
+			}
+		}
+		r.origins[&merged] = r.origins[override]
+		return &merged
+	case base.Kind == yaml.SequenceNode && override.Kind == yaml.SequenceNode:
+		merged := *override
+		merged.Content = append(slices.Clone(base.Content), override.Content...)
+		r.origins[&merged] = r.origins[override]
+		return &merged
+	default:
+		return override
+	}
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveIncludes(t *testing.T) {
+	contextDir := t.TempDir()
+	writeTestFiles(t, contextDir, map[string]string{
+		"shared/datasets.yaml": `
+include:
+  - shared/docs.yaml
+datasets:
+  - name: eval
+    path: data/eval
+`,
+		"shared/docs.yaml": `
+package:
+  authors: [shared-author]
+  license: MIT
+docs:
+  - path: docs/shared.md
+`,
+		"shared/model.yaml": `
+include:
+  - ./shared/../shared/docs.yaml
+model:
+  path: model
+  parameters:
+    learningRate: 0.1
+    epochs: 3
+`,
+	})
+	kitfileYAML := `
+manifestVersion: 1.0.0
+include:
+  - shared/datasets.yaml
+  - shared/model.yaml
+package:
+  name: my-model
+  authors: [me]
+  license:
+model:
+  parameters:
+    epochs: 5
+datasets:
+  - name: train
+    path: data/train
+`
+	merged, includes, err := ResolveIncludes([]byte(kitfileYAML), contextDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"shared/datasets.yaml", "shared/docs.yaml", "shared/model.yaml"}, includes)
+	kitfile := &artifact.KitFile{}
+	if !assert.NoError(t, yaml.Unmarshal(merged, kitfile)) {
+		return
+	}
+	assert.Empty(t, kitfile.Include)
+	assert.Equal(t, "my-model", kitfile.Package.Name)
+	assert.Equal(t, []string{"shared-author", "me"}, kitfile.Package.Authors, "Fragments included twice should be merged once")
+	assert.Equal(t, "MIT", kitfile.Package.License, "Empty values should not override included values")
+	assert.Equal(t, "model", kitfile.Model.Path)
+	assert.Equal(t, map[string]any{"learningRate": 0.1, "epochs": 5}, kitfile.Model.Parameters)
+	if assert.Len(t, kitfile.DataSets, 2) {
+		assert.Equal(t, "eval", kitfile.DataSets[0].Name)
+		assert.Equal(t, "train", kitfile.DataSets[1].Name)
+	}
+	if assert.Len(t, kitfile.Docs, 1) {
+		assert.Equal(t, "docs/shared.md", kitfile.Docs[0].Path)
+	}
+
+	unchanged := []byte("manifestVersion: 1.0.0\n")
+	merged, includes, err = ResolveIncludes(unchanged, contextDir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, unchanged, merged)
+		assert.Empty(t, includes)
+	}
+}
+
+func TestResolveIncludesErrors(t *testing.T) {
+	contextDir := t.TempDir()
+	writeTestFiles(t, contextDir, map[string]string{
+		"a.yaml": "include: [b.yaml]\n",
+		"b.yaml": "include: [a.yaml]\n",
+		"c.yaml": "- not a map\n",
+	})
+	tests := []struct {
+		name     string
+		kitfile  string
+		errorMsg string
+	}{
+		{name: "cycle", kitfile: "include: [a.yaml]\n", errorMsg: "include cycle detected: Kitfile -> a.yaml -> b.yaml -> a.yaml"},
+		{name: "missing file", kitfile: "include: [missing.yaml]\n", errorMsg: "failed to read included file missing.yaml"},
+		{name: "outside context", kitfile: "include: [../outside.yaml]\n", errorMsg: "must be relative to and within the context directory"},
+		{name: "not a list", kitfile: "include: {a: b}\n", errorMsg: "include must be a list of paths"},
+		{name: "not a map", kitfile: "include: [c.yaml]\n", errorMsg: "included file c.yaml must contain a map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ResolveIncludes([]byte(tt.kitfile), contextDir)
+			assert.ErrorContains(t, err, tt.errorMsg)
+		})
+	}
+}
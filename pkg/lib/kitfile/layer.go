@@ -22,7 +22,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -42,7 +41,11 @@ import (
 // a descriptor (including hash) for the compressed file, the layer is saved to a temporary file
 // on disk and must be moved to an appropriate location. It is the responsibility of the caller
 // to clean up the temporary file when it is no longer needed.
-func compressLayer(path string, mediaType constants.MediaType, ignore filesystem.IgnorePaths) (tempFilePath string, desc ocispec.Descriptor, layerInfo *artifact.LayerInfo, err error) {
+//
+// If chunks is not nil, large files are split into chunks that are saved separately and the layer is
+// marked as chunked if any files were chunked. If shard is not nil, only files in the shard are included.
+// If repro is not nil, file metadata is normalized so that the layer can be reproduced on other systems.
+func compressLayer(path string, mediaType constants.MediaType, ignore filesystem.IgnorePaths, dereference bool, chunks *chunkSaver, shard *layerShard, repro *reproducibility) (tempFilePath string, desc ocispec.Descriptor, layerInfo *artifact.LayerInfo, err error) {
 	// Clean path to ensure consistent format (./path vs path/ vs path)
 	path = filepath.Clean(path)
 
@@ -52,9 +55,14 @@ func compressLayer(path string, mediaType constants.MediaType, ignore filesystem
 		output.Errorf("Warning: %s layer path %s ignored by kitignore", mediaType.BaseType, path)
 	}
 
-	totalSize, err := getTotalSize(path, ignore)
-	if err != nil {
-		return "", ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("error processing %s: %w", mediaType.BaseType, err)
+	var totalSize int64
+	if shard != nil {
+		totalSize = shard.size
+	} else {
+		totalSize, err = getTotalSize(path, ignore, dereference)
+		if err != nil {
+			return "", ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("error processing %s: %w", mediaType.BaseType, err)
+		}
 	}
 	if totalSize == 0 {
 		output.Logf(output.LogLevelWarn, "No files detected in %s layer with path %s", mediaType.BaseType, path)
@@ -74,14 +82,14 @@ func compressLayer(path string, mediaType constants.MediaType, ignore filesystem
 	var compressedWriter io.WriteCloser
 	var tarWriter *tar.Writer
 	switch mediaType.Compression {
-	case constants.GzipCompression:
-		compressedWriter = gzip.NewWriter(fileWriter)
-		diffIdDigester = digest.Canonical.Digester()
-		mw := io.MultiWriter(compressedWriter, diffIdDigester.Hash())
-		tarWriter = tar.NewWriter(mw)
-	case constants.GzipFastestCompression:
-		compressedWriter, err = gzip.NewWriterLevel(fileWriter, gzip.BestSpeed)
+	case constants.GzipCompression, constants.GzipFastestCompression:
+		level := gzip.DefaultCompression
+		if mediaType.Compression == constants.GzipFastestCompression {
+			level = gzip.BestSpeed
+		}
+		compressedWriter, err = newGzipWriter(fileWriter, level)
 		if err != nil {
+			tempFileCleanup()
 			return "", ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("failed to set up gzip compression: %w", err)
 		}
 		diffIdDigester = digest.Canonical.Digester()
@@ -93,14 +101,20 @@ func compressLayer(path string, mediaType constants.MediaType, ignore filesystem
 	}
 	progressTarWriter, plog := output.TarProgress(totalSize, tarWriter)
 
-	if err := writeLayerToTar(path, ignore, progressTarWriter, plog); err != nil {
+	var numChunkedFiles int
+	if chunks != nil {
+		numChunkedFiles = chunks.numFiles
+	}
+	if err := writeLayerToTar(path, ignore, dereference, chunks, shard, repro, progressTarWriter, plog); err != nil {
 		// Don't care about these errors since we'll be deleting the file anyways
 		_ = progressTarWriter.Close()
 		_ = tarWriter.Close()
 		if compressedWriter != nil {
 			_ = compressedWriter.Close()
 		}
+		plog.Wait()
 		tempFileCleanup()
+		return "", ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("error processing %s: %w", mediaType.BaseType, err)
 	}
 	plog.Wait()
 
@@ -117,6 +131,9 @@ func compressLayer(path string, mediaType constants.MediaType, ignore filesystem
 	}
 	callAndPrintError(tempFile.Close, "Failed to close temporary file: %s")
 
+	if chunks != nil && chunks.numFiles > numChunkedFiles {
+		mediaType.Chunked = true
+	}
 	desc = ocispec.Descriptor{
 		MediaType: mediaType.String(),
 		Digest:    digester.Digest(),
@@ -129,83 +146,143 @@ func compressLayer(path string, mediaType constants.MediaType, ignore filesystem
 	return tempFileName, desc, layerInfo, nil
 }
 
-func writeLayerToTar(basePath string, ignore filesystem.IgnorePaths, tarWriter *output.ProgressTar, plog *output.ProgressLogger) error {
-	// Make sure target path exists; otherwise we'll miss it while walking below
-	_, err := os.Stat(basePath)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("path %s does not exist", basePath)
+// writeLayerToTar writes all files under basePath to the tar writer. Symlinks and hard links
+// are archived as links as long as their targets are within basePath; if dereference is true,
+// the content of link targets is archived instead. If chunks is not nil, large files are stored
+// as chunks instead of being written to the tar file directly. If shard is not nil, only files in the
+// shard are written. Files are written in lexical order, as returned by filepath.Walk.
+func writeLayerToTar(basePath string, ignore filesystem.IgnorePaths, dereference bool, chunks *chunkSaver, shard *layerShard, repro *reproducibility, tarWriter *output.ProgressTar, plog *output.ProgressLogger) error {
+	return walkLayerFiles(basePath, ignore, dereference, func(f layerFile) error {
+		if f.ignored != filesystem.NotIgnored {
+			plog.Debugf("Skipping %s: ignored", f.path)
+			return nil
 		}
-		return err
-	}
-
-	// Utility function to decide if two paths are in the same directory tree (i.e. one is a parent of the other)
-	sameDirTree := func(a, b string) bool {
-		aToB, errA := filepath.Rel(a, b)
-		bToA, errB := filepath.Rel(b, a)
-		if errA != nil || errB != nil {
-			plog.Logf(output.LogLevelWarn, "Cannot compare directories %s and %s, skipping path", a, b)
-			return false
+		if !shard.includes(f.path, f.info) {
+			return nil
 		}
-		if strings.Contains(aToB, "..") && strings.Contains(bToA, "..") {
-			return false
+		if f.info.Mode()&os.ModeSymlink != 0 {
+			return writeSymlinkToTar(basePath, f.path, f.info, repro, tarWriter, plog)
 		}
-		return true
-	}
-
-	err = filepath.Walk(".", func(file string, fi os.FileInfo, err error) error {
-		if err != nil {
-			return err
+		if f.linkTarget != "" {
+			return writeHardlinkToTar(f.path, f.linkTarget, f.info, repro, tarWriter, plog)
 		}
-		if file == "." {
-			return nil
+		if chunks.shouldChunk(f.info) {
+			return chunks.writeChunkedFile(f.path, f.info, repro, tarWriter, plog)
 		}
-		// Skip anything that's not a regular file or directory
-		if !fi.Mode().IsRegular() && !fi.Mode().IsDir() {
-			return nil
+		if err := writeHeaderToTar(f.path, f.info, repro, tarWriter, plog); err != nil {
+			return err
 		}
-		// Since we're walking from the context directory, we want to skip irrelevant files (e.g. sibling directories)
-		if !sameDirTree(basePath, file) {
-			if fi.IsDir() {
-				return filepath.SkipDir
-			}
+		if f.info.IsDir() {
 			return nil
 		}
+		return writeFileToTar(f.path, f.info, tarWriter, plog)
+	})
+}
 
-		// Check if file should be ignored by the ignorefile/other Kitfile layers
-		if shouldIgnore, err := ignore.Matches(file, basePath); err != nil {
-			return fmt.Errorf("failed to match %s against ignore file: %w", file, err)
-		} else if shouldIgnore {
-			if !ignore.HasExclusions() && fi.IsDir() {
-				plog.Debugf("Skipping directory %s: ignored", file)
-				return filepath.SkipDir
-			}
-			plog.Debugf("Skipping file %s: ignored", file)
-			return nil
+// walkLayer walks the file tree rooted at root similarly to filepath.Walk. Symlinks for which
+// followLink returns true are resolved and walkFn is called with the target's FileInfo under the
+// symlink's path; symlinked directories are walked as if they were regular directories. If a symlink
+// that should be followed cannot be resolved (e.g. it is broken or forms a loop), walkFn is called
+// with the symlink's own FileInfo.
+func walkLayer(root string, followLink func(string) bool, walkFn filepath.WalkFunc) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+	return walkWithSymlinks(root, root, []string{realRoot}, followLink, walkFn)
+}
+
+// walkWithSymlinks walks dir, reporting paths to walkFn as if dir were located at name. Expanded contains
+// the resolved paths of all directories being walked in the current chain of symlinks, and is used to
+// detect symlink loops.
+func walkWithSymlinks(name, dir string, expanded []string, followLink func(string) bool, walkFn filepath.WalkFunc) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return walkFn(path, fi, relErr)
+		}
+		file := filepath.Join(name, rel)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 || !followLink(file) {
+			return walkFn(file, fi, err)
 		}
 
-		if err := writeHeaderToTar(file, fi, tarWriter, plog); err != nil {
-			return err
+		targetFi, err := os.Stat(path)
+		if err != nil {
+			output.Debugf("Failed to resolve symlink %s: %s", file, err)
+			return walkFn(file, fi, nil)
 		}
-		if fi.IsDir() {
-			return nil
+		if !targetFi.IsDir() {
+			return walkFn(file, targetFi, nil)
+		}
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			output.Debugf("Failed to resolve symlink %s: %s", file, err)
+			return walkFn(file, fi, nil)
 		}
-		return writeFileToTar(file, fi, tarWriter, plog)
+		for _, parent := range expanded {
+			if isWithinPath(parent, target) {
+				output.Debugf("Symlink %s to %s forms a loop", file, target)
+				return walkFn(file, fi, nil)
+			}
+		}
+		return walkWithSymlinks(file, target, append(expanded, target), followLink, walkFn)
 	})
+}
+
+// isWithinPath returns true if path is equal to or a subpath of parent, without resolving symlinks.
+func isWithinPath(path, parent string) bool {
+	rel, err := filepath.Rel(parent, path)
 	if err != nil {
-		return err
+		return false
 	}
+	return filepath.IsLocal(rel) || rel == "."
+}
 
+func writeSymlinkToTar(basePath, file string, fi os.FileInfo, repro *reproducibility, ptw *output.ProgressTar, plog *output.ProgressLogger) error {
+	linkTarget, err := os.Readlink(file)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink %s: %w", file, err)
+	}
+	if filepath.IsAbs(linkTarget) || !isWithinPath(filepath.Join(filepath.Dir(file), linkTarget), basePath) {
+		return fmt.Errorf("symlink %s points to %s, which is outside layer path %s (use --dereference to include its content instead)", file, linkTarget, basePath)
+	}
+	header, err := tar.FileInfoHeader(fi, linkTarget)
+	if err != nil {
+		return fmt.Errorf("failed to generate header for %s: %w", file, err)
+	}
+	header.Name = file
+	sanitizeTarHeader(header, repro)
+	if err := ptw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	plog.Debugf("Wrote symlink %s -> %s to tar file", header.Name, header.Linkname)
+	return nil
+}
+
+func writeHardlinkToTar(file, linkTarget string, fi os.FileInfo, repro *reproducibility, ptw *output.ProgressTar, plog *output.ProgressLogger) error {
+	header, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return fmt.Errorf("failed to generate header for %s: %w", file, err)
+	}
+	header.Name = file
+	header.Typeflag = tar.TypeLink
+	header.Linkname = linkTarget
+	header.Size = 0
+	sanitizeTarHeader(header, repro)
+	if err := ptw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	plog.Debugf("Wrote hard link %s -> %s to tar file", header.Name, header.Linkname)
 	return nil
 }
 
-func writeHeaderToTar(name string, fi os.FileInfo, ptw *output.ProgressTar, plog *output.ProgressLogger) error {
+func writeHeaderToTar(name string, fi os.FileInfo, repro *reproducibility, ptw *output.ProgressTar, plog *output.ProgressLogger) error {
 	header, err := tar.FileInfoHeader(fi, "")
 	if err != nil {
 		return fmt.Errorf("failed to generate header for %s: %w", name, err)
 	}
 	header.Name = name
-	sanitizeTarHeader(header)
+	sanitizeTarHeader(header, repro)
 	if err := ptw.WriteHeader(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
@@ -229,47 +306,186 @@ func writeFileToTar(file string, fi os.FileInfo, ptw *output.ProgressTar, plog *
 	return nil
 }
 
-func getTotalSize(basePath string, ignore filesystem.IgnorePaths) (int64, error) {
+func getTotalSize(basePath string, ignore filesystem.IgnorePaths, dereference bool) (int64, error) {
+	var total int64
+	err := walkLayerFiles(basePath, ignore, dereference, func(f layerFile) error {
+		// Hard linked files are only stored once unless dereferencing
+		if f.ignored == filesystem.NotIgnored && f.info.Mode().IsRegular() && f.linkTarget == "" {
+			total += f.info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// layerFile is a path visited by walkLayerFiles.
+type layerFile struct {
+	path string
+	info os.FileInfo
+	// ignored is the reason the path is excluded from the layer, if it is. If it is excluded because it is
+	// included in another layer, otherLayer is the path of that layer.
+	ignored    filesystem.IgnoreReason
+	otherLayer string
+	// linkTarget is the path of a previously included file that this regular file is hard linked to. It
+	// is always empty when dereferencing links.
+	linkTarget string
+}
+
+// walkLayerFiles calls fn for each regular file, directory and symlink under basePath, in lexical order. This
+// walk defines which files are packed into the layer for basePath, and anything that inspects a layer before
+// it is packed should use it as well. The walk starts at the context directory, so fn is also called for the
+// parent directories of basePath. Paths excluded from the layer are passed to fn with the reason they are
+// excluded; their contents are skipped if the ignore file has no exclusions. Symlinks are passed as-is unless
+// dereference is true or they form the layer path itself (or one of its parents), in which case they are
+// followed.
+func walkLayerFiles(basePath string, ignore filesystem.IgnorePaths, dereference bool, fn func(f layerFile) error) error {
+	// Make sure target path exists; otherwise we'll miss it while walking below
 	pathInfo, err := os.Stat(basePath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return 0, fmt.Errorf("path %s does not exist", basePath)
+			return fmt.Errorf("path %s does not exist", basePath)
 		}
-		return 0, err
+		return err
+	}
+	if !pathInfo.Mode().IsRegular() && !pathInfo.IsDir() {
+		return fmt.Errorf("path %s is neither a file nor a directory", basePath)
+	}
+
+	// Utility function to decide if two paths are in the same directory tree (i.e. one is a parent of the other)
+	sameDirTree := func(a, b string) bool {
+		aToB, errA := filepath.Rel(a, b)
+		bToA, errB := filepath.Rel(b, a)
+		if errA != nil || errB != nil {
+			output.Logf(output.LogLevelWarn, "Cannot compare directories %s and %s, skipping path", a, b)
+			return false
+		}
+		if strings.Contains(aToB, "..") && strings.Contains(bToA, "..") {
+			return false
+		}
+		return true
+	}
+
+	followLink := func(file string) bool {
+		return dereference || isWithinPath(basePath, file)
 	}
 
-	if pathInfo.Mode().IsRegular() {
-		return pathInfo.Size(), nil
-	} else if pathInfo.IsDir() {
-		var total int64
-		err := filepath.WalkDir(basePath, func(file string, d fs.DirEntry, err error) error {
-			if err != nil {
+	// Track hard linked files so that subsequent links to the same file are stored as links
+	hardlinks := map[string]string{}
+
+	return walkLayer(".", followLink, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == "." {
+			return nil
+		}
+		// Since we're walking from the context directory, we want to skip irrelevant files (e.g. sibling directories)
+		if !sameDirTree(basePath, file) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Skip anything that's not a regular file, directory, or symlink
+		isSymlink := fi.Mode()&os.ModeSymlink != 0
+		if !fi.Mode().IsRegular() && !fi.IsDir() && !isSymlink {
+			output.Debugf("Skipping %s: unsupported file type", file)
+			return nil
+		}
+
+		f := layerFile{path: file, info: fi}
+		f.ignored, f.otherLayer, err = ignore.MatchReason(file, basePath)
+		if err != nil {
+			return fmt.Errorf("failed to match %s against ignore file: %w", file, err)
+		}
+		if f.ignored != filesystem.NotIgnored {
+			if err := fn(f); err != nil {
 				return err
 			}
-			if shouldIgnore, err := ignore.Matches(file, basePath); err != nil {
-				return fmt.Errorf("failed to match %s against ignore file: %w", file, err)
-			} else if shouldIgnore {
-				if !ignore.HasExclusions() && d.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+			if fi.IsDir() && !ignore.HasExclusions() {
+				return filepath.SkipDir
 			}
-			if d.Type().IsRegular() {
-				fi, err := d.Info()
-				if err != nil {
-					return fmt.Errorf("failed to stat %s: %w", file, err)
+			return nil
+		}
+
+		if isSymlink && followLink(file) {
+			// walkLayer only passes symlinks it was supposed to follow if they cannot be resolved
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("failed to dereference symlink %s: %w", file, err)
+			}
+			return fmt.Errorf("failed to dereference symlink %s: symlink forms a loop", file)
+		}
+		if fi.Mode().IsRegular() && !dereference {
+			if key, ok := filesystem.HardlinkKey(fi); ok {
+				if target, seen := hardlinks[key]; seen {
+					f.linkTarget = target
+				} else {
+					hardlinks[key] = file
 				}
-				total += fi.Size()
 			}
+		}
+		return fn(f)
+	})
+}
+
+// layerShard is a subset of the files under a layer path that is packed as one of several layers
+// for that path.
+type layerShard struct {
+	files map[string]bool
+	size  int64
+}
+
+// includes returns true if file should be written to the layer for this shard. Directories are
+// included in all shards so that each shard can be extracted independently.
+func (s *layerShard) includes(file string, fi os.FileInfo) bool {
+	return s == nil || fi.IsDir() || s.files[file]
+}
+
+// planShards splits the files under basePath into shards of at most maxSize bytes, on file
+// boundaries. Files larger than maxSize are placed in a shard of their own. Hard links are placed
+// in the same shard as the file they link to. If the layer does not need to be split (including
+// when maxSize is zero), nil is returned.
+func planShards(basePath string, ignore filesystem.IgnorePaths, dereference bool, maxSize int64) ([]*layerShard, error) {
+	if maxSize <= 0 {
+		return nil, nil
+	}
+	var shards []*layerShard
+	fileShards := map[string]*layerShard{}
+	current := &layerShard{files: map[string]bool{}}
+	err := walkLayerFiles(basePath, ignore, dereference, func(f layerFile) error {
+		file, fi := f.path, f.info
+		if f.ignored != filesystem.NotIgnored || fi.IsDir() {
 			return nil
-		})
-		if err != nil {
-			return 0, err
 		}
-		return total, nil
-	} else {
-		return 0, fmt.Errorf("path %s is neither a file nor a directory", basePath)
+		if f.linkTarget != "" {
+			shard := fileShards[f.linkTarget]
+			shard.files[file] = true
+			return nil
+		}
+		if fi.Mode().IsRegular() {
+			if current.size > 0 && current.size+fi.Size() > maxSize {
+				shards = append(shards, current)
+				current = &layerShard{files: map[string]bool{}}
+			}
+			if fi.Size() > maxSize {
+				output.Logf(output.LogLevelWarn, "File %s is larger than the maximum layer size and will be stored in its own layer", file)
+			}
+			current.size += fi.Size()
+		}
+		current.files[file] = true
+		fileShards[file] = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, nil
 	}
+	return append(shards, current), nil
 }
 
 // callAndPrintError is a wrapper to print an error message for a function that
@@ -280,10 +496,13 @@ func callAndPrintError(f func() error, msg string) {
 	}
 }
 
-func sanitizeTarHeader(header *tar.Header) {
+// sanitizeTarHeader clears fields in header that vary between systems or between checkouts of the same
+// files. If repro is not nil, the header is further normalized (see reproducibility.normalize).
+func sanitizeTarHeader(header *tar.Header, repro *reproducibility) {
 	// On windows, store paths linux-style (forward slashes). This is a no-op if
 	// filepath.Separator is '/'
 	header.Name = filepath.ToSlash(header.Name)
+	header.Linkname = filepath.ToSlash(header.Linkname)
 	// Clear fields that break reproducible tars
 	header.AccessTime = time.Time{}
 	header.ModTime = time.Time{}
@@ -292,4 +511,5 @@ func sanitizeTarHeader(header *tar.Header) {
 	header.Gid = 0
 	header.Uname = ""
 	header.Gname = ""
+	repro.normalize(header)
 }
@@ -0,0 +1,137 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+)
+
+var (
+	spdxIdstringRegexp   = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	spdxLicenseRefRegexp = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?LicenseRef-[A-Za-z0-9.-]+$`)
+	spdxTokenRegexp      = regexp.MustCompile(`\(|\)|[^\s()]+`)
+)
+
+// knownLicenseIDs returns the lowercased set of license identifiers known to licensecheck, which includes
+// the SPDX license list.
+var knownLicenseIDs = sync.OnceValue(func() map[string]bool {
+	ids := map[string]bool{"none": true, "noassertion": true}
+	for _, license := range licensecheck.BuiltinLicenses() {
+		ids[strings.ToLower(license.ID)] = true
+	}
+	return ids
+})
+
+// ValidateLicenseExpression returns an error if expr is not a valid SPDX license expression
+// (e.g. "Apache-2.0" or "MIT OR (GPL-2.0-or-later WITH Classpath-exception-2.0)"). License identifiers
+// must be known SPDX identifiers or custom LicenseRef-* identifiers.
+func ValidateLicenseExpression(expr string) error {
+	p := &licenseParser{tokens: spdxTokenRegexp.FindAllString(expr, -1)}
+	if len(p.tokens) == 0 {
+		return fmt.Errorf("license expression is empty")
+	}
+	if err := p.parseOr(); err != nil {
+		return err
+	}
+	if p.pos < len(p.tokens) {
+		return fmt.Errorf("unexpected %q in license expression", p.tokens[p.pos])
+	}
+	return nil
+}
+
+// licenseParser is a recursive descent parser for SPDX license expressions. In order of increasing
+// precedence, the operators are OR, AND, and WITH.
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peekOperator(op string) bool {
+	return p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], op)
+}
+
+func (p *licenseParser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for p.peekOperator("OR") {
+		p.pos++
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *licenseParser) parseAnd() error {
+	if err := p.parseWith(); err != nil {
+		return err
+	}
+	for p.peekOperator("AND") {
+		p.pos++
+		if err := p.parseWith(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *licenseParser) parseWith() error {
+	if err := p.parsePrimary(); err != nil {
+		return err
+	}
+	if p.peekOperator("WITH") {
+		p.pos++
+		if p.pos >= len(p.tokens) || !spdxIdstringRegexp.MatchString(p.tokens[p.pos]) {
+			return fmt.Errorf("expected license exception after WITH")
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func (p *licenseParser) parsePrimary() error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("unexpected end of license expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token == "(":
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return fmt.Errorf("missing ')' in license expression")
+		}
+		p.pos++
+		return nil
+	case token == ")", strings.EqualFold(token, "AND"), strings.EqualFold(token, "OR"), strings.EqualFold(token, "WITH"):
+		return fmt.Errorf("unexpected %q in license expression", token)
+	case spdxLicenseRefRegexp.MatchString(token):
+		return nil
+	case knownLicenseIDs()[strings.ToLower(strings.TrimSuffix(token, "+"))]:
+		return nil
+	default:
+		return fmt.Errorf("unknown SPDX license identifier %q", token)
+	}
+}
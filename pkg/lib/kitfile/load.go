@@ -0,0 +1,58 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// LoadOptions configures how a Kitfile is loaded by LoadKitfile.
+type LoadOptions struct {
+	// ContextDir is the directory that included fragments and paths in the Kitfile are relative to
+	ContextDir string
+	// Variables are the values used when expanding variables in the Kitfile (see ExpandVariables)
+	Variables map[string]string
+	// ExpandParameters expands variables in parameters sections as well
+	ExpandParameters bool
+}
+
+// LoadKitfile parses and validates a Kitfile read from a context directory. Included fragments are merged
+// and variables are expanded first, so that the result is what gets validated, and paths that exist in the
+// context directory are treated as local paths even if they look like modelkit references (see
+// DisambiguateLocalPaths). It returns the Kitfile along with the paths of any included fragments.
+func LoadKitfile(content []byte, opts LoadOptions) (*artifact.KitFile, []string, error) {
+	content, includes, err := ResolveIncludes(content, opts.ContextDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err = ExpandVariables(content, opts.Variables, opts.ExpandParameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	kitfile := &artifact.KitFile{}
+	if err := kitfile.LoadModel(io.NopCloser(bytes.NewReader(content))); err != nil {
+		return nil, nil, err
+	}
+	DisambiguateLocalPaths(kitfile, opts.ContextDir)
+	if err := ValidateKitfile(kitfile); err != nil {
+		return nil, nil, err
+	}
+	return kitfile, includes, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadKitfile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("File names cannot contain ':' on Windows")
+	}
+	contextDir := t.TempDir()
+	writeTestFiles(t, contextDir, map[string]string{
+		"shared.yaml": `
+datasets:
+  - name: eval
+    path: data/eval:v2
+`,
+		"data/eval:v2/eval.csv": "a,b\n",
+	})
+	content := []byte(`
+manifestVersion: 1.0.0
+include:
+  - shared.yaml
+package:
+  version: ${VERSION}
+model:
+  path: model
+`)
+
+	kitfile, includes, err := LoadKitfile(content, LoadOptions{
+		ContextDir: contextDir,
+		Variables:  map[string]string{"VERSION": "1.2.0"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"shared.yaml"}, includes)
+	assert.Equal(t, "1.2.0", kitfile.Package.Version)
+	if assert.Len(t, kitfile.DataSets, 1) {
+		assert.Equal(t, "./data/eval:v2", kitfile.DataSets[0].Path)
+	}
+
+	// The Kitfile is validated after includes are merged
+	duplicate := []byte(`
+include: [shared.yaml]
+datasets:
+  - name: other
+    path: data/eval:v2
+`)
+	_, _, err = LoadKitfile(duplicate, LoadOptions{ContextDir: contextDir})
+	assert.ErrorContains(t, err, "use the same path")
+}
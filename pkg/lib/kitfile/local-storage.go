@@ -23,6 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
@@ -38,23 +41,83 @@ import (
 	"oras.land/oras-go/v2"
 )
 
+// SaveModelOptions configures how layers are generated when saving a modelkit.
+type SaveModelOptions struct {
+	// Compression is the compression format to use for layers, unless overridden by compression
+	// in the Kitfile
+	Compression string
+	// Dereference stores the contents of symlink and hard link targets in layers
+	// instead of storing the links themselves
+	Dereference bool
+	// Remote is an optional target that layers, config, and manifest are uploaded to
+	// as soon as each is generated
+	Remote oras.Target
+	// SkipLocal skips saving the modelkit to local storage. Only valid if Remote is set.
+	SkipLocal bool
+	// Chunked splits large files into content-defined chunks stored as separate blobs, allowing
+	// content to be deduplicated between modelkits
+	Chunked bool
+	// MaxLayerSize is the maximum size of layers, in bytes. Paths larger than this are split into
+	// multiple layers, unless overridden by maxLayerSize in the Kitfile. Zero means no limit.
+	MaxLayerSize int64
+	// Reproducible normalizes file permissions and modification times in layers so that packing the
+	// same content produces the same modelkit on any system
+	Reproducible bool
+	// SourceDateEpoch is the modification time stored for files when Reproducible is set. If nil, the
+	// zero time is used.
+	SourceDateEpoch *time.Time
+	// ArtifactType is set as the manifest's artifactType if not empty, marking the modelkit as an
+	// OCI 1.1 artifact (see constants.ModelKitArtifactType)
+	ArtifactType string
+	// Annotations are added to the manifest, in addition to the annotations generated from the
+	// Kitfile's package section (e.g. the creation time and source repository)
+	Annotations map[string]string
+
+	chunks *chunkSaver
+	repro  *reproducibility
+}
+
+// targets returns the destinations that blobs should be saved to.
+func (opts SaveModelOptions) targets(localRepo local.LocalRepo) []oras.Target {
+	var targets []oras.Target
+	if !opts.SkipLocal {
+		targets = append(targets, localRepo)
+	}
+	if opts.Remote != nil {
+		targets = append(targets, opts.Remote)
+	}
+	return targets
+}
+
 // SaveModel saves an *artifact.Model to the provided oras.Target, compressing layers. It attempts to block
 // modelkits that include paths that leave the base context directory, allowing only subdirectories of the root
-// context to be included in the modelkit.
-func SaveModel(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, compression string) (*ocispec.Descriptor, error) {
-	layerDescs, err := saveKitfileLayers(ctx, localRepo, kitfile, ignore, compression)
+// context to be included in the modelkit. If opts.Remote is set, each layer is uploaded to the remote as soon
+// as it is generated, followed by the config and manifest.
+func SaveModel(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, opts SaveModelOptions) (*ocispec.Descriptor, error) {
+	if opts.Chunked {
+		opts.chunks = newChunkSaver(ctx, opts.targets(localRepo))
+	}
+	opts.repro = newReproducibility(opts.Reproducible, opts.SourceDateEpoch)
+	layerDescs, err := saveKitfileLayers(ctx, localRepo, kitfile, ignore, opts)
 	if err != nil {
 		return nil, err
 	}
+	if opts.chunks != nil {
+		// Chunks are referenced by the manifest after all other layers so that they are included
+		// when the modelkit is pushed and pulled.
+		output.Debugf("Saved %d chunks for %d files", len(opts.chunks.descs), opts.chunks.numFiles)
+		layerDescs = append(layerDescs, opts.chunks.descs...)
+	}
 
-	configDesc, err := saveConfig(ctx, localRepo, kitfile)
+	configDesc, err := saveConfig(ctx, opts.targets(localRepo), kitfile)
 	if err != nil {
 		return nil, err
 	}
 
-	manifest := createManifest(configDesc, layerDescs)
+	manifest := createManifest(configDesc, layerDescs, kitfile, opts.Annotations)
+	manifest.ArtifactType = opts.ArtifactType
 
-	manifestDesc, err := saveModelManifest(ctx, localRepo, manifest)
+	manifestDesc, err := saveModelManifest(ctx, opts.targets(localRepo), manifest)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +129,7 @@ func SaveModel(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact
 	return manifestDesc, nil
 }
 
-func saveConfig(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact.KitFile) (ocispec.Descriptor, error) {
+func saveConfig(ctx context.Context, targets []oras.Target, kitfile *artifact.KitFile) (ocispec.Descriptor, error) {
 	modelBytes, err := kitfile.MarshalToJSON()
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, err
@@ -77,106 +140,190 @@ func saveConfig(ctx context.Context, localRepo local.LocalRepo, kitfile *artifac
 		Size:      int64(len(modelBytes)),
 	}
 
-	exists, err := localRepo.Exists(ctx, desc)
-	if err != nil {
-		return ocispec.DescriptorEmptyJSON, err
-	}
-	if !exists {
-		// Does not exist in storage, need to push
-		err = localRepo.Push(ctx, desc, bytes.NewReader(modelBytes))
+	for _, target := range targets {
+		exists, err := target.Exists(ctx, desc)
 		if err != nil {
 			return ocispec.DescriptorEmptyJSON, err
 		}
-		output.Infof("Saved configuration: %s", desc.Digest)
-	} else {
-		output.Infof("Configuration already exists in storage: %s", desc.Digest)
+		if !exists {
+			// Does not exist in storage, need to push
+			err = target.Push(ctx, desc, bytes.NewReader(modelBytes))
+			if err != nil {
+				return ocispec.DescriptorEmptyJSON, err
+			}
+			output.Infof("Saved configuration: %s", desc.Digest)
+		} else {
+			output.Infof("Configuration already exists in storage: %s", desc.Digest)
+		}
 	}
 
 	return desc, nil
 }
 
-func saveKitfileLayers(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, compression string) ([]ocispec.Descriptor, error) {
+func saveKitfileLayers(ctx context.Context, localRepo local.LocalRepo, kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, opts SaveModelOptions) ([]ocispec.Descriptor, error) {
 	var layers []ocispec.Descriptor
 	if kitfile.Model != nil {
 		if kitfile.Model.Path != "" && !util.IsModelKitReference(kitfile.Model.Path) {
 			mediaType := constants.MediaType{
 				BaseType:    constants.ModelType,
-				Compression: compression,
+				Compression: kitfile.Model.Compression,
 			}
-			layer, layerInfo, err := saveContentLayer(ctx, localRepo, kitfile.Model.Path, mediaType, ignore)
+			descs, layerInfo, err := saveContentLayer(ctx, localRepo, kitfile.Model.Path, kitfile.Model.MaxLayerSize, mediaType, ignore, opts)
 			if err != nil {
 				return nil, err
 			}
-			layers = append(layers, layer)
+			layers = append(layers, descs...)
 			kitfile.Model.LayerInfo = layerInfo
 		}
 		for idx, part := range kitfile.Model.Parts {
 			mediaType := constants.MediaType{
 				BaseType:    constants.ModelPartType,
-				Compression: compression,
+				Compression: part.Compression,
 			}
-			layer, layerInfo, err := saveContentLayer(ctx, localRepo, part.Path, mediaType, ignore)
+			descs, layerInfo, err := saveContentLayer(ctx, localRepo, part.Path, part.MaxLayerSize, mediaType, ignore, opts)
 			if err != nil {
 				return nil, err
 			}
-			layers = append(layers, layer)
+			layers = append(layers, descs...)
 			kitfile.Model.Parts[idx].LayerInfo = layerInfo
 		}
 	}
+	// Datasets and code that refer to other modelkits are stored in those modelkits, and are only recorded in the config
 	for idx, code := range kitfile.Code {
+		if util.IsModelKitReference(code.Path) {
+			continue
+		}
 		mediaType := constants.MediaType{
 			BaseType:    constants.CodeType,
-			Compression: compression,
+			Compression: code.Compression,
 		}
-		layer, layerInfo, err := saveContentLayer(ctx, localRepo, code.Path, mediaType, ignore)
+		descs, layerInfo, err := saveContentLayer(ctx, localRepo, code.Path, code.MaxLayerSize, mediaType, ignore, opts)
 		if err != nil {
 			return nil, err
 		}
-		layers = append(layers, layer)
+		layers = append(layers, descs...)
 		kitfile.Code[idx].LayerInfo = layerInfo
 	}
 	for idx, dataset := range kitfile.DataSets {
+		if util.IsModelKitReference(dataset.Path) {
+			continue
+		}
 		mediaType := constants.MediaType{
 			BaseType:    constants.DatasetType,
-			Compression: compression,
+			Compression: dataset.Compression,
 		}
-		layer, layerInfo, err := saveContentLayer(ctx, localRepo, dataset.Path, mediaType, ignore)
+		descs, layerInfo, err := saveContentLayer(ctx, localRepo, dataset.Path, dataset.MaxLayerSize, mediaType, ignore, opts)
 		if err != nil {
 			return nil, err
 		}
-		layers = append(layers, layer)
+		layers = append(layers, descs...)
 		kitfile.DataSets[idx].LayerInfo = layerInfo
 	}
 	for idx, docs := range kitfile.Docs {
 		mediaType := constants.MediaType{
 			BaseType:    constants.DocsType,
-			Compression: compression,
+			Compression: docs.Compression,
 		}
-		layer, layerInfo, err := saveContentLayer(ctx, localRepo, docs.Path, mediaType, ignore)
+		descs, layerInfo, err := saveContentLayer(ctx, localRepo, docs.Path, docs.MaxLayerSize, mediaType, ignore, opts)
 		if err != nil {
 			return nil, err
 		}
-		layers = append(layers, layer)
+		layers = append(layers, descs...)
 		kitfile.Docs[idx].LayerInfo = layerInfo
 	}
 
 	return layers, nil
 }
 
-func saveContentLayer(ctx context.Context, localRepo local.LocalRepo, path string, mediaType constants.MediaType, ignore filesystem.IgnorePaths) (ocispec.Descriptor, *artifact.LayerInfo, error) {
+// saveContentLayer saves the content at path as one or more layers. If mediaType does not specify a compression,
+// the compression in opts is used. If the path is larger than the maximum
+// layer size (from maxLayerSize or opts), it is split into multiple layers, which are recorded as shards
+// in the returned LayerInfo.
+func saveContentLayer(ctx context.Context, localRepo local.LocalRepo, path, maxLayerSize string, mediaType constants.MediaType, ignore filesystem.IgnorePaths, opts SaveModelOptions) ([]ocispec.Descriptor, *artifact.LayerInfo, error) {
+	mediaType, shards, err := planContentLayer(path, maxLayerSize, mediaType, ignore, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shards == nil {
+		desc, info, err := saveLayer(ctx, localRepo, path, mediaType, ignore, nil, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []ocispec.Descriptor{desc}, info, nil
+	}
+
+	output.Infof("Splitting %s path %s into %d layers", mediaType.BaseType, path, len(shards))
+	var descs []ocispec.Descriptor
+	layerInfo := &artifact.LayerInfo{}
+	for idx, shard := range shards {
+		desc, info, err := saveLayer(ctx, localRepo, path, mediaType, ignore, shard, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		desc.Annotations[constants.LayerShardAnnotation] = fmt.Sprintf("%d/%d", idx+1, len(shards))
+		descs = append(descs, desc)
+		layerInfo.Shards = append(layerInfo.Shards, artifact.LayerShard{Digest: info.Digest, DiffId: info.DiffId})
+	}
+	layerInfo.Digest = layerInfo.Shards[0].Digest
+	layerInfo.DiffId = layerInfo.Shards[0].DiffId
+	return descs, layerInfo, nil
+}
+
+// planContentLayer resolves the compression for the layer at path and splits it into shards if it is
+// larger than the maximum layer size. The returned shards are nil if the path is saved as a single layer.
+func planContentLayer(path, maxLayerSize string, mediaType constants.MediaType, ignore filesystem.IgnorePaths, opts SaveModelOptions) (constants.MediaType, []*layerShard, error) {
+	if mediaType.Compression == "" {
+		mediaType.Compression = opts.Compression
+	} else if err := constants.IsValidCompression(mediaType.Compression); err != nil {
+		return mediaType, nil, fmt.Errorf("invalid compression for %s: %w", mediaType.BaseType, err)
+	}
+	compression, err := resolveCompression(mediaType.Compression, filepath.Clean(path), ignore, opts.Dereference)
+	if err != nil {
+		return mediaType, nil, err
+	}
+	mediaType.Compression = compression
+
+	maxSize := opts.MaxLayerSize
+	if maxLayerSize != "" {
+		size, err := output.ParseBytes(maxLayerSize)
+		if err != nil {
+			return mediaType, nil, fmt.Errorf("invalid maxLayerSize for %s: %w", mediaType.BaseType, err)
+		}
+		maxSize = size
+	}
+	shards, err := planShards(filepath.Clean(path), ignore, opts.Dereference, maxSize)
+	if err != nil {
+		return mediaType, nil, fmt.Errorf("error processing %s: %w", mediaType.BaseType, err)
+	}
+	return mediaType, shards, nil
+}
+
+func saveLayer(ctx context.Context, localRepo local.LocalRepo, path string, mediaType constants.MediaType, ignore filesystem.IgnorePaths, shard *layerShard, opts SaveModelOptions) (ocispec.Descriptor, *artifact.LayerInfo, error) {
 	// We want to store a gzipped tar file in store, but to do so we need a descriptor, so we have to compress
 	// to a temporary file. Ideally, we'd also add this to the internal store by moving the file to avoid
 	// copying if possible.
-	tempPath, desc, info, err := compressLayer(path, mediaType, ignore)
+	tempPath, desc, info, err := compressLayer(path, mediaType, ignore, opts.Dereference, opts.chunks, shard, opts.repro)
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, nil, err
 	}
+	desc.Annotations = map[string]string{
+		ocispec.AnnotationTitle: filepath.ToSlash(path),
+	}
 	defer func() {
 		if err := os.Remove(tempPath); err != nil && !errors.Is(err, os.ErrNotExist) {
 			output.Errorf("Failed to remove temporary file %s: %s", tempPath, err)
 		}
 	}()
 
+	if opts.Remote != nil {
+		if err := pushLayerFile(ctx, opts.Remote, desc, tempPath, mediaType); err != nil {
+			return ocispec.DescriptorEmptyJSON, nil, err
+		}
+	}
+	if opts.SkipLocal {
+		return desc, info, nil
+	}
+
 	if exists, err := localRepo.Exists(ctx, desc); err != nil {
 		return ocispec.DescriptorEmptyJSON, nil, err
 	} else if exists {
@@ -217,7 +364,27 @@ func saveContentLayer(ctx context.Context, localRepo local.LocalRepo, path strin
 	return desc, info, nil
 }
 
-func saveModelManifest(ctx context.Context, store oras.Target, manifest ocispec.Manifest) (*ocispec.Descriptor, error) {
+// pushLayerFile uploads the layer stored at path to target, unless target already contains it.
+func pushLayerFile(ctx context.Context, target oras.Target, desc ocispec.Descriptor, path string, mediaType constants.MediaType) error {
+	if exists, err := target.Exists(ctx, desc); err != nil {
+		return fmt.Errorf("failed to check if layer exists in remote: %w", err)
+	} else if exists {
+		output.Infof("Remote already has %s layer: %s", mediaType.BaseType, desc.Digest)
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open temporary file: %w", err)
+	}
+	defer file.Close()
+	if err := target.Push(ctx, desc, file); err != nil {
+		return fmt.Errorf("failed to upload %s layer: %w", mediaType.BaseType, err)
+	}
+	output.Infof("Uploaded %s layer: %s", mediaType.BaseType, desc.Digest)
+	return nil
+}
+
+func saveModelManifest(ctx context.Context, targets []oras.Target, manifest ocispec.Manifest) (*ocispec.Descriptor, error) {
 	manifestBytes, err := json.Marshal(manifest)
 	if err != nil {
 		return nil, err
@@ -229,29 +396,63 @@ func saveModelManifest(ctx context.Context, store oras.Target, manifest ocispec.
 		Size:      int64(len(manifestBytes)),
 	}
 
-	if exists, err := store.Exists(ctx, desc); err != nil {
-		return nil, err
-	} else if !exists {
-		// Does not exist in storage, need to push
-		err = store.Push(ctx, desc, bytes.NewReader(manifestBytes))
-		if err != nil {
+	for _, store := range targets {
+		if exists, err := store.Exists(ctx, desc); err != nil {
 			return nil, err
+		} else if !exists {
+			// Does not exist in storage, need to push
+			err = store.Push(ctx, desc, bytes.NewReader(manifestBytes))
+			if err != nil {
+				return nil, err
+			}
+			output.Infof("Saved manifest to storage: %s", desc.Digest)
+		} else {
+			output.Infof("Manifest already exists in storage: %s", desc.Digest)
 		}
-		output.Infof("Saved manifest to storage: %s", desc.Digest)
-	} else {
-		output.Infof("Manifest already exists in storage: %s", desc.Digest)
 	}
 	return &desc, nil
 }
 
-func createManifest(configDesc ocispec.Descriptor, layerDescs []ocispec.Descriptor) ocispec.Manifest {
+// createManifest generates the manifest for a modelkit. Standard OCI annotations are set from the
+// package section of the Kitfile, the model card and metrics are stored as JSON annotations, and
+// extraAnnotations are added as-is.
+func createManifest(configDesc ocispec.Descriptor, layerDescs []ocispec.Descriptor, kitfile *artifact.KitFile, extraAnnotations map[string]string) ocispec.Manifest {
+	annotations := map[string]string{
+		constants.CliVersionAnnotation: constants.Version,
+	}
+	setAnnotation := func(key, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	setAnnotation(ocispec.AnnotationTitle, kitfile.Package.Name)
+	setAnnotation(ocispec.AnnotationDescription, kitfile.Package.Description)
+	setAnnotation(ocispec.AnnotationVersion, kitfile.Package.Version)
+	setAnnotation(ocispec.AnnotationLicenses, kitfile.Package.License)
+	setAnnotation(ocispec.AnnotationAuthors, strings.Join(kitfile.Package.Authors, ", "))
+	setJSONAnnotation := func(key string, value any) {
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			output.Logf(output.LogLevelWarn, "Failed to set annotation %s: %s", key, err)
+			return
+		}
+		annotations[key] = string(valueJSON)
+	}
+	if kitfile.ModelCard != nil {
+		setJSONAnnotation(constants.ModelCardAnnotation, kitfile.ModelCard)
+	}
+	if len(kitfile.Metrics) > 0 {
+		setJSONAnnotation(constants.MetricsAnnotation, kitfile.Metrics)
+	}
+	for key, value := range extraAnnotations {
+		setAnnotation(key, value)
+	}
+
 	manifest := ocispec.Manifest{
-		Versioned: specs.Versioned{SchemaVersion: 2},
-		Config:    configDesc,
-		Layers:    layerDescs,
-		Annotations: map[string]string{
-			constants.CliVersionAnnotation: constants.Version,
-		},
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		Config:      configDesc,
+		Layers:      layerDescs,
+		Annotations: annotations,
 	}
 
 	return manifest
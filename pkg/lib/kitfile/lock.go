@@ -0,0 +1,170 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gopkg.in/yaml.v3"
+)
+
+const lockVersion = "1"
+
+// Lock pins references to other modelkits, as used in a Kitfile and in the Kitfiles of the modelkits it
+// references, to the digests they resolved to when the lock was created.
+type Lock struct {
+	LockVersion string            `json:"lockVersion" yaml:"lockVersion"`
+	ModelKits   []LockedReference `json:"modelkits" yaml:"modelkits"`
+}
+
+// LockedReference is a modelkit reference pinned to a manifest digest
+type LockedReference struct {
+	Reference string `json:"reference" yaml:"reference"`
+	Digest    string `json:"digest" yaml:"digest"`
+}
+
+// LockFilePath returns the path of the Kitfile.lock file for a Kitfile, which is stored next to the Kitfile.
+// If the Kitfile is read from standard input ("-"), the lock file is stored in the context directory.
+func LockFilePath(kitfilePath, contextDir string) string {
+	if kitfilePath == "-" {
+		return filepath.Join(contextDir, constants.KitfileLockName)
+	}
+	return filepath.Join(filepath.Dir(kitfilePath), constants.KitfileLockName)
+}
+
+// ReadLockFile reads a Kitfile.lock file. If the file does not exist, it returns nil and no error.
+func ReadLockFile(path string) (*Lock, error) {
+	lockBytes, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	lock := &Lock{}
+	if err := yaml.Unmarshal(lockBytes, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if lock.LockVersion != lockVersion {
+		return nil, fmt.Errorf("unsupported lock version %q in %s", lock.LockVersion, path)
+	}
+	return lock, nil
+}
+
+// WriteFile writes the lock to path in YAML format.
+func (l *Lock) WriteFile(path string) error {
+	lockBytes, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to format lock file: %w", err)
+	}
+	header := "# This file is generated by Kit and pins referenced modelkits to digests. Use 'kit lock' to update it.\n"
+	if err := os.WriteFile(path, append([]byte(header), lockBytes...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// LockFromManifest returns the lock stored in a modelkit's manifest when it was packed, or nil if the
+// modelkit was packed without one.
+func LockFromManifest(manifest *ocispec.Manifest) (*Lock, error) {
+	lockJSON, ok := manifest.Annotations[constants.LockAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	lock := &Lock{}
+	if err := json.Unmarshal([]byte(lockJSON), lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock annotation: %w", err)
+	}
+	return lock, nil
+}
+
+// Annotation returns the lock in the format used for the lock annotation on manifests.
+func (l *Lock) Annotation() (string, error) {
+	lockBytes, err := json.Marshal(l)
+	if err != nil {
+		return "", fmt.Errorf("failed to format lock: %w", err)
+	}
+	return string(lockBytes), nil
+}
+
+// Pin returns the reference to use for fetching ref. If ref is pinned in the lock, a digest reference
+// to the same repository is returned; otherwise, ref is returned unmodified. It is safe to call Pin on
+// a nil Lock.
+func (l *Lock) Pin(ref string) string {
+	if l == nil {
+		return ref
+	}
+	for _, locked := range l.ModelKits {
+		if locked.Reference != ref {
+			continue
+		}
+		parsed, _, err := util.ParseReference(ref)
+		if err != nil {
+			return ref
+		}
+		parsed.Reference = locked.Digest
+		return util.FormatRepositoryForDisplay(parsed.String())
+	}
+	return ref
+}
+
+// Equal returns true if both locks pin the same references to the same digests.
+func (l *Lock) Equal(other *Lock) bool {
+	if l == nil || other == nil {
+		return l == other
+	}
+	return l.LockVersion == other.LockVersion && slices.Equal(l.ModelKits, other.ModelKits)
+}
+
+// LockKitfile walks the modelkits referenced by kitfile, as in WalkReferences, and returns a Lock pinning
+// each reference to a digest. References already pinned in existing keep their digest, while other references
+// are resolved to the digest they currently refer to. The baseRef is the reference the Kitfile is being packed
+// as, and is used to detect cycles. If the Kitfile does not reference any modelkits, nil is returned.
+func LockKitfile(ctx context.Context, configHome string, kitfile *artifact.KitFile, baseRef string, existing *Lock) (*Lock, error) {
+	if len(util.ReferencesFromKitfile(kitfile)) == 0 {
+		return nil, nil
+	}
+	lock := &Lock{LockVersion: lockVersion, ModelKits: []LockedReference{}}
+	err := WalkReferences(ctx, configHome, kitfile, baseRef, existing, func(ref util.KitfileReference, desc ocispec.Descriptor, _ *artifact.KitFile) error {
+		parsed, _, err := util.ParseReference(ref.Ref)
+		if err != nil {
+			return err
+		}
+		// References that are already digests do not need to be pinned, and a reference used in multiple
+		// places is pinned to the same digest
+		if util.ReferenceIsDigest(parsed.Reference) || lock.Pin(ref.Ref) != ref.Ref {
+			return nil
+		}
+		lock.ModelKits = append(lock.ModelKits, LockedReference{Reference: ref.Ref, Digest: desc.Digest.String()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDigest = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+func TestLockPin(t *testing.T) {
+	lock := &Lock{
+		LockVersion: lockVersion,
+		ModelKits: []LockedReference{
+			{Reference: "org/base:latest", Digest: testDigest},
+			{Reference: "registry.example.com/org/base:v1", Digest: testDigest},
+		},
+	}
+	assert.Equal(t, "org/base@"+testDigest, lock.Pin("org/base:latest"))
+	assert.Equal(t, "registry.example.com/org/base@"+testDigest, lock.Pin("registry.example.com/org/base:v1"))
+	assert.Equal(t, "org/other:latest", lock.Pin("org/other:latest"))
+
+	var nilLock *Lock
+	assert.Equal(t, "org/base:latest", nilLock.Pin("org/base:latest"))
+}
+
+func TestLockFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := LockFilePath(filepath.Join(dir, "Kitfile"), "")
+	assert.Equal(t, filepath.Join(dir, constants.KitfileLockName), lockPath)
+	assert.Equal(t, filepath.Join(dir, constants.KitfileLockName), LockFilePath("-", dir))
+
+	missing, err := ReadLockFile(lockPath)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	lock := &Lock{
+		LockVersion: lockVersion,
+		ModelKits:   []LockedReference{{Reference: "org/base:latest", Digest: testDigest}},
+	}
+	if !assert.NoError(t, lock.WriteFile(lockPath)) {
+		return
+	}
+	readLock, err := ReadLockFile(lockPath)
+	if assert.NoError(t, err) {
+		assert.True(t, lock.Equal(readLock))
+	}
+
+	annotation, err := lock.Annotation()
+	if !assert.NoError(t, err) {
+		return
+	}
+	manifest := &ocispec.Manifest{Annotations: map[string]string{constants.LockAnnotation: annotation}}
+	manifestLock, err := LockFromManifest(manifest)
+	if assert.NoError(t, err) {
+		assert.True(t, lock.Equal(manifestLock))
+	}
+	manifestLock, err = LockFromManifest(&ocispec.Manifest{})
+	assert.NoError(t, err)
+	assert.Nil(t, manifestLock)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"slices"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+)
+
+// MergeKitfiles merges the Kitfile of a modelkit (into) with the Kitfile of the modelkit referenced by its
+// model (from), returning the effective Kitfile. Neither Kitfile is modified. Fields are merged as follows:
+//
+//   - Metadata (the package fields, and the model's name, license, framework, format, version, and
+//     description) is taken from into, falling back to from where it is empty.
+//   - Package authors are combined, with duplicates removed.
+//   - The model's path, layer settings and layer information are taken from from, as the model's layers
+//     are stored in that modelkit.
+//   - Model parameters are merged deeply: maps are merged key by key, and for any other value, the value
+//     in into is used unless it is empty.
+//   - Model parts, code, datasets, and docs are combined, with entries in into first. Entries in from with
+//     the same path as an earlier entry are dropped.
+//   - Model card fields are taken from into, falling back to from where they are empty, and training
+//     data is combined, with duplicates removed.
+//   - Metrics are combined, with metrics in into first. Metrics in from with the same name, dataset, and
+//     split as an earlier metric are dropped.
+func MergeKitfiles(into, from *artifact.KitFile) *artifact.KitFile {
+	result := &artifact.KitFile{}
+	result.ManifestVersion = firstNonEmpty(into.ManifestVersion, from.ManifestVersion)
+	result.Package.Name = firstNonEmpty(into.Package.Name, from.Package.Name)
+	result.Package.Description = firstNonEmpty(into.Package.Description, from.Package.Description)
+	result.Package.License = firstNonEmpty(into.Package.License, from.Package.License)
+	result.Package.Version = firstNonEmpty(into.Package.Version, from.Package.Version)
+	for _, author := range slices.Concat(into.Package.Authors, from.Package.Authors) {
+		if !slices.Contains(result.Package.Authors, author) {
+			result.Package.Authors = append(result.Package.Authors, author)
+		}
+	}
+
+	if into.Model != nil || from.Model != nil {
+		intoModel := into.Model
+		fromModel := from.Model
+		if intoModel == nil {
+			intoModel = &artifact.Model{}
+		}
+		if fromModel == nil {
+			fromModel = &artifact.Model{}
+		}
+		result.Model = &artifact.Model{
+			Name:         firstNonEmpty(intoModel.Name, fromModel.Name),
+			Path:         fromModel.Path,
+			License:      firstNonEmpty(intoModel.License, fromModel.License),
+			Framework:    firstNonEmpty(intoModel.Framework, fromModel.Framework),
+			Format:       firstNonEmpty(intoModel.Format, fromModel.Format),
+			Version:      firstNonEmpty(intoModel.Version, fromModel.Version),
+			Description:  firstNonEmpty(intoModel.Description, fromModel.Description),
+			Parts:        mergeByKey(intoModel.Parts, fromModel.Parts, func(p artifact.ModelPart) string { return p.Path }),
+			Parameters:   mergeParameters(intoModel.Parameters, fromModel.Parameters),
+			MaxLayerSize: fromModel.MaxLayerSize,
+			Compression:  fromModel.Compression,
+			LayerInfo:    fromModel.LayerInfo,
+		}
+	}
+
+	if into.ModelCard != nil || from.ModelCard != nil {
+		intoCard := into.ModelCard
+		fromCard := from.ModelCard
+		if intoCard == nil {
+			intoCard = &artifact.ModelCard{}
+		}
+		if fromCard == nil {
+			fromCard = &artifact.ModelCard{}
+		}
+		result.ModelCard = &artifact.ModelCard{
+			IntendedUse:  firstNonEmpty(intoCard.IntendedUse, fromCard.IntendedUse),
+			Limitations:  firstNonEmpty(intoCard.Limitations, fromCard.Limitations),
+			TrainingData: mergeByKey(intoCard.TrainingData, fromCard.TrainingData, func(d string) string { return d }),
+		}
+	}
+	type metricKey struct{ name, dataset, split string }
+	result.Metrics = mergeByKey(into.Metrics, from.Metrics, func(m artifact.Metric) metricKey {
+		return metricKey{m.Name, m.Dataset, m.Split}
+	})
+
+	result.Code = mergeByKey(into.Code, from.Code, func(c artifact.Code) string { return c.Path })
+	result.DataSets = mergeByKey(into.DataSets, from.DataSets, func(d artifact.DataSet) string { return d.Path })
+	result.Docs = mergeByKey(into.Docs, from.Docs, func(d artifact.Docs) string { return d.Path })
+	return result
+}
+
+// mergeByKey returns the entries in into followed by the entries in from, skipping entries with the same
+// key as an earlier entry.
+func mergeByKey[T any, K comparable](into, from []T, key func(T) K) []T {
+	var result []T
+	var keys []K
+	for _, entry := range slices.Concat(into, from) {
+		if slices.Contains(keys, key(entry)) {
+			continue
+		}
+		keys = append(keys, key(entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+// mergeParameters merges two parameters sections, preferring values in into. If both are maps, the maps are
+// merged recursively.
+func mergeParameters(into, from any) any {
+	intoMap, intoIsMap := into.(map[string]any)
+	fromMap, fromIsMap := from.(map[string]any)
+	if !intoIsMap || !fromIsMap {
+		if into != nil {
+			return into
+		}
+		return from
+	}
+	result := make(map[string]any, len(intoMap)+len(fromMap))
+	for key, value := range fromMap {
+		result[key] = value
+	}
+	for key, value := range intoMap {
+		result[key] = mergeParameters(value, fromMap[key])
+	}
+	return result
+}
+
+func firstNonEmpty(strs ...string) string {
+	for _, s := range strs {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeKitfiles(t *testing.T) {
+	parentLayer := &artifact.LayerInfo{Digest: testDigest}
+	child := &artifact.KitFile{
+		ManifestVersion: "1.0.0",
+		Package: artifact.Package{
+			Name:    "fine-tuned",
+			Authors: []string{"Alice", "Bob"},
+		},
+		Model: &artifact.Model{
+			Name: "fine-tuned-model",
+			Path: "org/base:v1",
+			Parts: []artifact.ModelPart{
+				{Name: "lora", Path: "lora"},
+			},
+			Parameters: map[string]any{
+				"temperature": 0.2,
+				"training": map[string]any{
+					"epochs": 3,
+				},
+			},
+		},
+		ModelCard: &artifact.ModelCard{
+			IntendedUse:  "Answering support questions",
+			TrainingData: []string{"support-tickets"},
+		},
+		Metrics: []artifact.Metric{{Name: "accuracy", Value: 0.91, Dataset: "support-tickets"}},
+		Docs:    []artifact.Docs{{Path: "README.md", Description: "Fine-tuned model"}},
+	}
+	parent := &artifact.KitFile{
+		ManifestVersion: "1.0.0",
+		Package: artifact.Package{
+			Name:        "base",
+			Description: "Base model",
+			License:     "Apache-2.0",
+			Authors:     []string{"Bob", "Carol"},
+		},
+		Model: &artifact.Model{
+			Name:         "base-model",
+			Path:         "model.gguf",
+			License:      "MIT",
+			Format:       "gguf",
+			Framework:    "llama.cpp",
+			MaxLayerSize: "1GB",
+			LayerInfo:    parentLayer,
+			Parts: []artifact.ModelPart{
+				{Name: "tokenizer", Path: "tokenizer"},
+				{Name: "base-lora", Path: "lora"},
+			},
+			Parameters: map[string]any{
+				"temperature": 0.7,
+				"top_p":       0.9,
+				"training": map[string]any{
+					"epochs":        10,
+					"learning_rate": 0.001,
+				},
+			},
+		},
+		ModelCard: &artifact.ModelCard{
+			IntendedUse:  "General text generation",
+			Limitations:  "English only",
+			TrainingData: []string{"support-tickets", "wikipedia"},
+		},
+		Metrics: []artifact.Metric{
+			{Name: "accuracy", Value: 0.7, Dataset: "support-tickets"},
+			{Name: "perplexity", Value: 12.5, Dataset: "wikipedia", Split: "test"},
+		},
+		DataSets: []artifact.DataSet{{Name: "train", Path: "data/train"}},
+		Docs:     []artifact.Docs{{Path: "README.md", Description: "Base model"}, {Path: "LICENSE"}},
+	}
+
+	expected := &artifact.KitFile{
+		ManifestVersion: "1.0.0",
+		Package: artifact.Package{
+			Name:        "fine-tuned",
+			Description: "Base model",
+			License:     "Apache-2.0",
+			Authors:     []string{"Alice", "Bob", "Carol"},
+		},
+		Model: &artifact.Model{
+			Name:         "fine-tuned-model",
+			Path:         "model.gguf",
+			License:      "MIT",
+			Format:       "gguf",
+			Framework:    "llama.cpp",
+			MaxLayerSize: "1GB",
+			LayerInfo:    parentLayer,
+			Parts: []artifact.ModelPart{
+				{Name: "lora", Path: "lora"},
+				{Name: "tokenizer", Path: "tokenizer"},
+			},
+			Parameters: map[string]any{
+				"temperature": 0.2,
+				"top_p":       0.9,
+				"training": map[string]any{
+					"epochs":        3,
+					"learning_rate": 0.001,
+				},
+			},
+		},
+		ModelCard: &artifact.ModelCard{
+			IntendedUse:  "Answering support questions",
+			Limitations:  "English only",
+			TrainingData: []string{"support-tickets", "wikipedia"},
+		},
+		Metrics: []artifact.Metric{
+			{Name: "accuracy", Value: 0.91, Dataset: "support-tickets"},
+			{Name: "perplexity", Value: 12.5, Dataset: "wikipedia", Split: "test"},
+		},
+		DataSets: []artifact.DataSet{{Name: "train", Path: "data/train"}},
+		Docs:     []artifact.Docs{{Path: "README.md", Description: "Fine-tuned model"}, {Path: "LICENSE"}},
+	}
+	assert.Equal(t, expected, MergeKitfiles(child, parent))
+	assert.Equal(t, []string{"Alice", "Bob"}, child.Package.Authors, "MergeKitfiles should not modify its arguments")
+	assert.Equal(t, "org/base:v1", child.Model.Path, "MergeKitfiles should not modify its arguments")
+}
+
+func TestMergeParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		into     any
+		from     any
+		expected any
+	}{
+		{name: "both empty", into: nil, from: nil, expected: nil},
+		{name: "only into", into: map[string]any{"a": 1}, from: nil, expected: map[string]any{"a": 1}},
+		{name: "only from", into: nil, from: map[string]any{"a": 1}, expected: map[string]any{"a": 1}},
+		{name: "lists are not merged", into: []any{1}, from: []any{2, 3}, expected: []any{1}},
+		{name: "scalar overrides map", into: "value", from: map[string]any{"a": 1}, expected: "value"},
+		{
+			name:     "nested null does not override",
+			into:     map[string]any{"a": nil},
+			from:     map[string]any{"a": map[string]any{"b": 1}},
+			expected: map[string]any{"a": map[string]any{"b": 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, mergeParameters(tt.into, tt.from))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/filesystem"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+)
+
+// LayerPlan describes the content that would be packed for an entry in a Kitfile.
+type LayerPlan struct {
+	// Type is the type of the entry (model, modelpart, code, dataset, or docs)
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	Path string `json:"path"`
+	// Size is the total size of files included in the layer, before compression
+	Size        int64  `json:"size"`
+	Layers      int    `json:"layers"`
+	Compression string `json:"compression"`
+	// Files lists the files, symlinks and hard links included in the layer
+	Files []string `json:"files"`
+	// Ignored lists files and directories excluded by the .kitignore file
+	Ignored []string `json:"ignored"`
+	// Claimed lists files and directories excluded because they are included in another layer
+	Claimed []ClaimedPath `json:"claimed"`
+}
+
+// ClaimedPath is a path excluded from a layer because it is included in the layer for another entry.
+type ClaimedPath struct {
+	Path  string `json:"path"`
+	Layer string `json:"layer"`
+}
+
+// PlanLayers determines which files would be packed into each layer for kitfile, using the same rules
+// as SaveModel, without writing anything to storage. Entries that refer to other modelkits are skipped.
+func PlanLayers(kitfile *artifact.KitFile, ignore filesystem.IgnorePaths, opts SaveModelOptions) ([]LayerPlan, error) {
+	var plans []LayerPlan
+	addPlan := func(baseType, name, path, maxLayerSize, compression string) error {
+		plan, err := planLayer(baseType, name, path, maxLayerSize, compression, ignore, opts)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, *plan)
+		return nil
+	}
+
+	if kitfile.Model != nil {
+		if kitfile.Model.Path != "" && !util.IsModelKitReference(kitfile.Model.Path) {
+			if err := addPlan(constants.ModelType, kitfile.Model.Name, kitfile.Model.Path, kitfile.Model.MaxLayerSize, kitfile.Model.Compression); err != nil {
+				return nil, err
+			}
+		}
+		for _, part := range kitfile.Model.Parts {
+			if err := addPlan(constants.ModelPartType, part.Name, part.Path, part.MaxLayerSize, part.Compression); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, code := range kitfile.Code {
+		if util.IsModelKitReference(code.Path) {
+			continue
+		}
+		if err := addPlan(constants.CodeType, "", code.Path, code.MaxLayerSize, code.Compression); err != nil {
+			return nil, err
+		}
+	}
+	for _, dataset := range kitfile.DataSets {
+		if util.IsModelKitReference(dataset.Path) {
+			continue
+		}
+		if err := addPlan(constants.DatasetType, dataset.Name, dataset.Path, dataset.MaxLayerSize, dataset.Compression); err != nil {
+			return nil, err
+		}
+	}
+	for _, docs := range kitfile.Docs {
+		if err := addPlan(constants.DocsType, "", docs.Path, docs.MaxLayerSize, docs.Compression); err != nil {
+			return nil, err
+		}
+	}
+	return plans, nil
+}
+
+func planLayer(baseType, name, path, maxLayerSize, compression string, ignore filesystem.IgnorePaths, opts SaveModelOptions) (*LayerPlan, error) {
+	mediaType := constants.MediaType{BaseType: baseType, Compression: compression}
+	mediaType, shards, err := planContentLayer(path, maxLayerSize, mediaType, ignore, opts)
+	if err != nil {
+		return nil, err
+	}
+	plan := &LayerPlan{
+		Type:        baseType,
+		Name:        name,
+		Path:        path,
+		Layers:      max(len(shards), 1),
+		Compression: mediaType.Compression,
+		Files:       []string{},
+		Ignored:     []string{},
+		Claimed:     []ClaimedPath{},
+	}
+
+	basePath := filepath.Clean(path)
+	err = walkLayerFiles(basePath, ignore, opts.Dereference, func(f layerFile) error {
+		if !isWithinPath(f.path, basePath) {
+			// Parent directories of the layer path are not part of its content
+			return nil
+		}
+		displayPath := filepath.ToSlash(f.path)
+		if f.info.IsDir() {
+			displayPath += "/"
+		}
+		switch f.ignored {
+		case filesystem.IgnoredByIgnoreFile:
+			plan.Ignored = append(plan.Ignored, displayPath)
+		case filesystem.IgnoredByOtherLayer:
+			plan.Claimed = append(plan.Claimed, ClaimedPath{Path: displayPath, Layer: filepath.ToSlash(f.otherLayer)})
+		case filesystem.NotIgnored:
+			if !f.info.IsDir() {
+				plan.Files = append(plan.Files, displayPath)
+			}
+			// Hard linked files are only stored once unless dereferencing
+			if f.info.Mode().IsRegular() && f.linkTarget == "" {
+				plan.Size += f.info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error processing %s: %w", baseType, err)
+	}
+	return plan, nil
+}
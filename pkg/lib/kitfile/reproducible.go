@@ -0,0 +1,77 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// reproducibility holds the settings used to normalize tar headers when packing in reproducible
+// mode. A nil *reproducibility leaves headers as generated by sanitizeTarHeader.
+type reproducibility struct {
+	// modTime is the modification time stored for all files (from SOURCE_DATE_EPOCH, if set)
+	modTime time.Time
+}
+
+func newReproducibility(enabled bool, sourceDateEpoch *time.Time) *reproducibility {
+	if !enabled {
+		return nil
+	}
+	r := &reproducibility{}
+	if sourceDateEpoch != nil {
+		r.modTime = sourceDateEpoch.UTC()
+	}
+	return r
+}
+
+// normalize sets the modification time and permissions in header to fixed values. Regular files
+// are stored as 0755 if they are executable by anyone and 0644 otherwise, directories as 0755, and
+// symlinks as 0777, so that the umask and platform of the packing system do not affect the layer.
+func (r *reproducibility) normalize(header *tar.Header) {
+	if r == nil {
+		return
+	}
+	header.ModTime = r.modTime
+	switch header.Typeflag {
+	case tar.TypeDir:
+		header.Mode = 0755
+	case tar.TypeSymlink:
+		header.Mode = 0777
+	default:
+		if header.Mode&0111 != 0 {
+			header.Mode = 0755
+		} else {
+			header.Mode = 0644
+		}
+	}
+}
+
+// newGzipWriter returns a gzip writer with all header fields pinned, so that the compressed
+// output depends only on the uncompressed content and compression level.
+func newGzipWriter(w io.Writer, level int) (*gzip.Writer, error) {
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, err
+	}
+	gw.Header = gzip.Header{
+		OS: 255, // unknown
+	}
+	return gw, nil
+}
@@ -19,6 +19,9 @@ package kitfile
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
@@ -27,53 +30,80 @@ import (
 	"github.com/kitops-ml/kitops/pkg/lib/repo/local"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/remote"
 	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry"
 )
 
 func GetKitfileForRefString(ctx context.Context, configHome string, ref string) (*artifact.KitFile, error) {
+	_, kitfile, err := getKitfileAndDescForRefString(ctx, configHome, ref)
+	return kitfile, err
+}
+
+func GetKitfileForRef(ctx context.Context, configHome string, ref *registry.Reference) (*artifact.KitFile, error) {
+	_, kitfile, err := getKitfileAndDescForRef(ctx, configHome, ref)
+	return kitfile, err
+}
+
+func getKitfileAndDescForRefString(ctx context.Context, configHome string, ref string) (ocispec.Descriptor, *artifact.KitFile, error) {
 	modelRef, _, err := util.ParseReference(ref)
 	if err != nil {
-		return nil, err
+		return ocispec.DescriptorEmptyJSON, nil, err
 	}
-
-	return GetKitfileForRef(ctx, configHome, modelRef)
+	return getKitfileAndDescForRef(ctx, configHome, modelRef)
 }
 
-func GetKitfileForRef(ctx context.Context, configHome string, ref *registry.Reference) (*artifact.KitFile, error) {
+// getKitfileAndDescForRef returns the Kitfile for a reference along with the descriptor of its manifest,
+// checking local storage before the remote registry.
+func getKitfileAndDescForRef(ctx context.Context, configHome string, ref *registry.Reference) (ocispec.Descriptor, *artifact.KitFile, error) {
 	storageRoot := constants.StoragePath(configHome)
 	localRepo, err := local.NewLocalRepo(storageRoot, ref)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read local storage: %w", err)
+		return ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("failed to read local storage: %w", err)
 	}
-	_, _, localKitfile, err := util.ResolveManifestAndConfig(ctx, localRepo, ref.Reference)
+	desc, _, localKitfile, err := util.ResolveManifestAndConfig(ctx, localRepo, ref.Reference)
 	if err == nil {
-		return localKitfile, nil
+		return desc, localKitfile, nil
 	}
 
 	repository, err := remote.NewRepository(ctx, ref.Registry, ref.Repository, options.DefaultNetworkOptions(configHome))
 	if err != nil {
-		return nil, err
+		return ocispec.DescriptorEmptyJSON, nil, err
 	}
-	_, _, remoteKitfile, err := util.ResolveManifestAndConfig(ctx, repository, ref.Reference)
+	desc, _, remoteKitfile, err := util.ResolveManifestAndConfig(ctx, repository, ref.Reference)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch Kitfile for %s: %w", ref, err)
+		return ocispec.DescriptorEmptyJSON, nil, fmt.Errorf("failed to fetch Kitfile for %s: %w", ref, err)
 	}
-	return remoteKitfile, nil
+	return desc, remoteKitfile, nil
 }
 
 // ResolveKitfile returns the Kitfile for a reference. Any references to other modelkits
 // are fetched and included in the resolved Kitfile, giving the equivalent Kitfile including
-// the model, datasets, and code from those referenced modelkits.
-func ResolveKitfile(ctx context.Context, configHome, kitfileRef, baseRef string) (*artifact.KitFile, error) {
-	resolved := &artifact.KitFile{}
-	refChain := []string{baseRef, kitfileRef}
+// the model, datasets, and code from those referenced modelkits. If lock is not nil, references
+// pinned in it are fetched by digest.
+func ResolveKitfile(ctx context.Context, configHome, kitfileRef, baseRef string, lock *Lock) (*artifact.KitFile, error) {
+	return resolveKitfile(ctx, configHome, &artifact.KitFile{}, kitfileRef, []string{baseRef, kitfileRef}, lock)
+}
+
+// ResolveModelReferences returns the effective Kitfile for kitfile, merging it with the modelkit referenced
+// by its model, if any, and the modelkits referenced by that modelkit's model, as described in
+// MergeKitfiles. The baseRef is the reference of the modelkit kitfile belongs to and is used to detect
+// cycles. If lock is not nil, references pinned in it are fetched by digest.
+func ResolveModelReferences(ctx context.Context, configHome string, kitfile *artifact.KitFile, baseRef string, lock *Lock) (*artifact.KitFile, error) {
+	if kitfile.Model == nil || !util.IsModelKitReference(kitfile.Model.Path) {
+		return kitfile, nil
+	}
+	return resolveKitfile(ctx, configHome, kitfile, kitfile.Model.Path, []string{baseRef, kitfile.Model.Path}, lock)
+}
+
+func resolveKitfile(ctx context.Context, configHome string, resolved *artifact.KitFile, kitfileRef string, refChain []string, lock *Lock) (*artifact.KitFile, error) {
 	for i := 0; i < constants.MaxModelRefChain; i++ {
-		kitfile, err := GetKitfileForRefString(ctx, configHome, kitfileRef)
+		kitfile, err := GetKitfileForRefString(ctx, configHome, lock.Pin(kitfileRef))
 		if err != nil {
 			return nil, err
 		}
-		resolved = mergeKitfiles(resolved, kitfile)
+		resolved = MergeKitfiles(resolved, kitfile)
 		if resolved.Model == nil || !util.IsModelKitReference(resolved.Model.Path) {
 			if err := ValidateKitfile(resolved); err != nil {
 				return nil, err
@@ -90,49 +120,68 @@ func ResolveKitfile(ctx context.Context, configHome, kitfileRef, baseRef string)
 	return nil, fmt.Errorf("reached maximum number of model references: [%s]", strings.Join(refChain, "=>"))
 }
 
-func mergeKitfiles(into, from *artifact.KitFile) *artifact.KitFile {
-	firstNonEmpty := func(strs ...string) string {
-		for _, s := range strs {
-			if s != "" {
-				return s
-			}
+// ReferenceVisitor is called for each modelkit reached by WalkReferences, with the reference to it and the
+// descriptor and Kitfile of the referenced modelkit.
+type ReferenceVisitor func(ref util.KitfileReference, desc ocispec.Descriptor, kitfile *artifact.KitFile) error
+
+// WalkReferences calls visit for each modelkit referenced by kitfile, in any entry, and then for modelkits
+// referenced by entries of the same type in the referenced modelkit, and so on. References pinned in lock
+// are fetched by digest. An error is returned if the references contain a cycle or a chain of more than
+// constants.MaxModelRefChain modelkits. The baseRef is the reference of the modelkit kitfile belongs to,
+// if any, and is used to detect cycles.
+func WalkReferences(ctx context.Context, configHome string, kitfile *artifact.KitFile, baseRef string, lock *Lock, visit ReferenceVisitor) error {
+	return walkReferences(ctx, configHome, util.ReferencesFromKitfile(kitfile), []string{baseRef}, lock, visit)
+}
+
+func walkReferences(ctx context.Context, configHome string, refs []util.KitfileReference, refChain []string, lock *Lock, visit ReferenceVisitor) error {
+	for _, ref := range refs {
+		if idx := getIndex(refChain, ref.Ref); idx != -1 {
+			cycleStr := fmt.Sprintf("[%s=>%s]", strings.Join(refChain[idx:], "=>"), ref.Ref)
+			return fmt.Errorf("Found cycle in modelkit references: %s", cycleStr)
+		}
+		chain := append(slices.Clone(refChain), ref.Ref)
+		// The first entry in the chain is the modelkit being resolved, which does not count as a reference
+		if len(chain)-1 > constants.MaxModelRefChain {
+			return fmt.Errorf("reached maximum number of model references: [%s]", strings.Join(chain, "=>"))
+		}
+		desc, kitfile, err := getKitfileAndDescForRefString(ctx, configHome, lock.Pin(ref.Ref))
+		if err != nil {
+			return fmt.Errorf("failed to resolve referenced modelkit %s: %w", ref.Ref, err)
+		}
+		if err := visit(ref, desc, kitfile); err != nil {
+			return err
+		}
+		if err := walkReferences(ctx, configHome, util.ReferencesOfType(kitfile, ref.BaseType), chain, lock, visit); err != nil {
+			return err
 		}
-		return ""
 	}
+	return nil
+}
 
-	result := &artifact.KitFile{}
-	result.ManifestVersion = firstNonEmpty(into.ManifestVersion, from.ManifestVersion)
-	result.Package.Name = firstNonEmpty(into.Package.Name, from.Package.Name)
-	result.Package.Description = firstNonEmpty(into.Package.Description, from.Package.Description)
-	result.Package.License = firstNonEmpty(into.Package.License, from.Package.License)
-	result.Package.Version = firstNonEmpty(into.Package.Version, from.Package.Version)
-	result.Package.Authors = append(into.Package.Authors, from.Package.Authors...)
-
-	if into.Model != nil || from.Model != nil {
-		result.Model = &artifact.Model{}
-		intoModel := into.Model
-		fromModel := from.Model
-		if intoModel == nil {
-			intoModel = &artifact.Model{}
+// DisambiguateLocalPaths prefixes the paths of the model, datasets, and code in kitfile with './' if they
+// look like references to other modelkits (e.g. data/eval:v2) but exist in contextDir, so that they are
+// packed as local paths and are not mistaken for references when the modelkit is unpacked. It should be
+// called on Kitfiles read from a context directory before they are used.
+func DisambiguateLocalPaths(kitfile *artifact.KitFile, contextDir string) {
+	localPath := func(path string) string {
+		if !util.IsModelKitReference(path) {
+			return path
 		}
-		if fromModel == nil {
-			fromModel = &artifact.Model{}
+		if _, err := os.Lstat(filepath.Join(contextDir, path)); err != nil {
+			return path
 		}
-		result.Model.Path = fromModel.Path
-		result.Model.Name = firstNonEmpty(intoModel.Name, fromModel.Name)
-		result.Model.Description = firstNonEmpty(intoModel.Description, fromModel.Description)
-		result.Model.Framework = firstNonEmpty(intoModel.Framework, fromModel.Framework)
-		result.Model.Version = firstNonEmpty(intoModel.Version, fromModel.Version)
-		result.Model.Parts = append(intoModel.Parts, fromModel.Parts...)
+		output.Debugf("Path %s exists in the context directory and is not treated as a modelkit reference", path)
+		return "./" + path
+	}
+	if kitfile.Model != nil && kitfile.Model.Path != "" {
+		kitfile.Model.Path = localPath(kitfile.Model.Path)
+	}
+	for idx := range kitfile.DataSets {
+		kitfile.DataSets[idx].Path = localPath(kitfile.DataSets[idx].Path)
+	}
+	for idx := range kitfile.Code {
+		kitfile.Code[idx].Path = localPath(kitfile.Code[idx].Path)
 	}
-
-	result.Code = into.Code
-	result.Code = append(result.Code, from.Code...)
-	result.DataSets = into.DataSets
-	result.DataSets = append(result.DataSets, from.DataSets...)
-	result.Docs = into.Docs
-	result.Docs = append(result.Docs, from.Docs...)
-	return result
 }
 
 func getIndex(list []string, s string) int {
@@ -0,0 +1,325 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue found while validating a Kitfile, along with the position of the YAML node it
+// applies to. Line and Column are zero if the position is not known.
+type Problem struct {
+	// File is the included Kitfile fragment the problem was found in, relative to the context directory.
+	// It is empty for problems in the Kitfile itself.
+	File    string
+	Line    int
+	Column  int
+	Message string
+	// Warning is true for problems that do not prevent the Kitfile from being packed
+	Warning bool
+}
+
+// ValidateOptions enables additional checks in ValidateKitfileYAML beyond the schema and the checks
+// done by ValidateKitfile.
+type ValidateOptions struct {
+	// Variables are used to expand ${VAR} expressions in the Kitfile, as in ExpandVariables
+	Variables map[string]string
+	// ExpandParameters enables expanding variables in parameters sections
+	ExpandParameters bool
+	// ContextDir is the directory paths in the Kitfile are relative to. If set, includes are resolved
+	// and paths that do not exist are reported.
+	ContextDir string
+	// CheckLicenses reports licenses that are not valid SPDX license expressions
+	CheckLicenses bool
+	// ResolveReference is called for each modelkit reference in the Kitfile, if set, and should
+	// return an error if the reference cannot be resolved.
+	ResolveReference func(ref string) error
+}
+
+// ValidateKitfileYAML parses and validates a Kitfile, returning the parsed Kitfile along with any problems
+// found. Includes are resolved and variables are expanded first, and the Kitfile is then validated against the Kitfile JSON schema and
+// the checks in ValidateKitfile, plus any checks enabled in opts. An error is returned only if the Kitfile is not valid YAML; if the Kitfile cannot be
+// parsed due to schema violations, the returned Kitfile is nil.
+func ValidateKitfileYAML(content []byte, opts ValidateOptions) (*artifact.KitFile, []Problem, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, []Problem{{Message: "Kitfile is empty"}}, nil
+	}
+	root := doc.Content[0]
+
+	var problems []Problem
+	var resolver *includeResolver
+	addProblem := func(node *yaml.Node, warning bool, format string, a ...any) {
+		var file string
+		if resolver != nil {
+			file = resolver.origin(node)
+		}
+		problems = append(problems, Problem{
+			File:    file,
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: fmt.Sprintf(format, a...),
+			Warning: warning,
+		})
+	}
+	// Includes are merged as YAML nodes so that the position of each node in its file is preserved
+	if includes := findIncludes(root); includes != nil && opts.ContextDir != "" {
+		resolver = newIncludeResolver(opts.ContextDir)
+		if err := resolver.resolveDocument(doc); err != nil {
+			addProblem(includes, false, "%s", err)
+			return nil, problems, nil
+		}
+		root = doc.Content[0]
+	}
+	// Variables are expanded in place so that the positions of nodes are preserved
+	expander := &variableExpander{vars: opts.Variables, expandParameters: opts.ExpandParameters}
+	expander.expandNode(doc)
+	for _, v := range expander.undefined {
+		addProblem(v.node, true, "variable %s is not defined and is left unchanged", v.name)
+	}
+
+	validateSchema(root, artifact.KitfileJSONSchema(), "", func(node *yaml.Node, msg string) {
+		addProblem(node, false, "%s", msg)
+	})
+	if len(problems) > 0 {
+		sortProblems(problems)
+		return nil, problems, nil
+	}
+
+	// Unknown fields are reported by validateSchema, so decoding from the node does not need to check for them
+	kitfile := &artifact.KitFile{}
+	if err := root.Decode(kitfile); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, nil, err
+		}
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, Problem{Message: msg})
+		}
+		return nil, problems, nil
+	}
+
+	if opts.ContextDir != "" {
+		DisambiguateLocalPaths(kitfile, opts.ContextDir)
+	}
+	for _, issue := range validateKitfile(kitfile) {
+		addProblem(findNode(root, issue.field), false, "%s", issue.message)
+	}
+
+	type fieldValue struct {
+		value string
+		field []any
+	}
+	var fieldValues []fieldValue
+	var licenseFields []fieldValue
+	licenseFields = append(licenseFields, fieldValue{kitfile.Package.License, []any{"package", "license"}})
+	// Paths of the model, datasets, and code may refer to other modelkits instead of files in the context
+	addPathOrReference := func(path, kind string, field []any) {
+		if !util.IsModelKitReference(path) {
+			fieldValues = append(fieldValues, fieldValue{path, field})
+			return
+		}
+		if opts.ResolveReference != nil {
+			if err := opts.ResolveReference(path); err != nil {
+				addProblem(findNode(root, field), false, "%s references modelkit %s, which could not be resolved: %s", kind, path, err)
+			}
+		}
+	}
+	if kitfile.Model != nil {
+		if kitfile.Model.Path != "" {
+			addPathOrReference(kitfile.Model.Path, "model", []any{"model", "path"})
+		}
+		licenseFields = append(licenseFields, fieldValue{kitfile.Model.License, []any{"model", "license"}})
+		for idx, part := range kitfile.Model.Parts {
+			fieldValues = append(fieldValues, fieldValue{part.Path, []any{"model", "parts", idx, "path"}})
+			licenseFields = append(licenseFields, fieldValue{part.License, []any{"model", "parts", idx, "license"}})
+		}
+	}
+	for idx, dataset := range kitfile.DataSets {
+		addPathOrReference(dataset.Path, "dataset", []any{"datasets", idx, "path"})
+		licenseFields = append(licenseFields, fieldValue{dataset.License, []any{"datasets", idx, "license"}})
+	}
+	for idx, code := range kitfile.Code {
+		addPathOrReference(code.Path, "code", []any{"code", idx, "path"})
+		licenseFields = append(licenseFields, fieldValue{code.License, []any{"code", idx, "license"}})
+	}
+	for idx, docs := range kitfile.Docs {
+		fieldValues = append(fieldValues, fieldValue{docs.Path, []any{"docs", idx, "path"}})
+	}
+
+	if opts.ContextDir != "" {
+		for _, pf := range fieldValues {
+			if filepath.IsAbs(pf.value) {
+				// Already reported by validateKitfile
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(opts.ContextDir, pf.value)); err != nil {
+				addProblem(findNode(root, pf.field), false, "path %s does not exist", pf.value)
+			}
+		}
+		for idx, dataset := range kitfile.DataSets {
+			for splitIdx, split := range dataset.Splits {
+				if validateSplitPath(dataset.Path, split.Path) != nil {
+					// Already reported by validateKitfile
+					continue
+				}
+				matches, err := filepath.Glob(filepath.Join(opts.ContextDir, filepath.FromSlash(split.Path)))
+				if err == nil && len(matches) == 0 {
+					addProblem(findNode(root, []any{"datasets", idx, "splits", splitIdx, "path"}), true, "split %s of dataset %s does not match any files", split.Name, dataset.Name)
+				}
+			}
+		}
+	}
+	if opts.CheckLicenses {
+		for _, lf := range licenseFields {
+			if lf.value == "" {
+				continue
+			}
+			if err := ValidateLicenseExpression(lf.value); err != nil {
+				addProblem(findNode(root, lf.field), true, "license %q is not a valid SPDX license expression: %s", lf.value, err)
+			}
+		}
+	}
+
+	sortProblems(problems)
+	return kitfile, problems, nil
+}
+
+// validateSchema reports nodes that do not match schema by calling report. Only the parts of JSON schema
+// used by the Kitfile schema are supported.
+func validateSchema(node *yaml.Node, schema *artifact.JSONSchema, field string, report func(node *yaml.Node, msg string)) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if schema.Type == "" || (node.Kind == yaml.ScalarNode && node.Tag == "!!null") {
+		return
+	}
+	fieldName := field
+	if fieldName == "" {
+		fieldName = "Kitfile"
+	}
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report(node, fmt.Sprintf("%s must be an object", fieldName))
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			propSchema, ok := schema.Properties[key.Value]
+			if !ok {
+				report(key, fmt.Sprintf("unknown field %s", joinField(field, key.Value)))
+				continue
+			}
+			seen[key.Value] = true
+			validateSchema(value, propSchema, joinField(field, key.Value), report)
+		}
+		for _, required := range schema.Required {
+			if !seen[required] {
+				report(node, fmt.Sprintf("missing required field %s", joinField(field, required)))
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report(node, fmt.Sprintf("%s must be a list", fieldName))
+			return
+		}
+		for idx, item := range node.Content {
+			validateSchema(item, schema.Items, fmt.Sprintf("%s[%d]", field, idx), report)
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			report(node, fmt.Sprintf("%s must be a string", fieldName))
+			return
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, node.Value) {
+			report(node, fmt.Sprintf("%s must be one of %s", fieldName, strings.Join(schema.Enum, ", ")))
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			report(node, fmt.Sprintf("%s must be an integer", fieldName))
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			report(node, fmt.Sprintf("%s must be a number", fieldName))
+		}
+	}
+}
+
+func joinField(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// findNode returns the YAML node for a field in a Kitfile, identified by a list of keys and indices. If
+// the field is not present, the node for the closest parent field is returned.
+func findNode(root *yaml.Node, field []any) *yaml.Node {
+	node := root
+	for _, segment := range field {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var next *yaml.Node
+		switch s := segment.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == s {
+						next = node.Content[i+1]
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && s < len(node.Content) {
+				next = node.Content[s]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+func sortProblems(problems []Problem) {
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		if a.File != b.File {
+			return strings.Compare(a.File, b.File)
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expr    string
+		isValid bool
+	}{
+		{expr: "Apache-2.0", isValid: true},
+		{expr: "mit", isValid: true},
+		{expr: "GPL-2.0+", isValid: true},
+		{expr: "MIT OR Apache-2.0", isValid: true},
+		{expr: "(MIT OR Apache-2.0) AND BSD-3-Clause", isValid: true},
+		{expr: "GPL-2.0-or-later WITH Classpath-exception-2.0", isValid: true},
+		{expr: "LicenseRef-my-license", isValid: true},
+		{expr: "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", isValid: true},
+		{expr: "", isValid: false},
+		{expr: "Apache 2.0", isValid: false},
+		{expr: "MIT OR", isValid: false},
+		{expr: "(MIT", isValid: false},
+		{expr: "MIT Apache-2.0", isValid: false},
+		{expr: "MIT WITH", isValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			err := ValidateLicenseExpression(tt.expr)
+			if tt.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateKitfileYAML(t *testing.T) {
+	contextDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(contextDir, "model.gguf"), []byte("model"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(contextDir, "data/train"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(contextDir, "data/train/part-0.csv"), []byte("id\n1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type expectedProblem struct {
+		line, column int
+		warning      bool
+	}
+	tests := []struct {
+		name     string
+		kitfile  string
+		expected []expectedProblem
+	}{
+		{
+			name: "valid",
+			kitfile: `manifestVersion: 1.0.0
+package:
+  license: MIT
+model:
+  path: model.gguf
+`,
+		},
+		{
+			name: "schema violations",
+			kitfile: `manifestVersion: 1.0.0
+package:
+  nme: test
+datasets:
+  path: data
+model:
+  path: model.gguf
+  compression: zstd
+`,
+			expected: []expectedProblem{{3, 3, false}, {5, 3, false}, {8, 16, false}},
+		},
+		{
+			name: "missing required field",
+			kitfile: `package:
+  name: test
+`,
+			expected: []expectedProblem{{1, 1, false}},
+		},
+		{
+			name: "semantic problems",
+			kitfile: `manifestVersion: 1.0.0
+package:
+  license: Apache 2.0
+model:
+  path: model.gguf
+  maxLayerSize: big
+datasets:
+  - name: missing
+    path: data.csv
+  - name: duplicate
+    path: model.gguf
+`,
+			expected: []expectedProblem{{3, 12, true}, {6, 17, false}, {9, 11, false}, {11, 11, false}},
+		},
+		{
+			name: "dataset splits",
+			kitfile: `manifestVersion: 1.0.0
+datasets:
+  - name: data
+    path: data
+    format: csv
+    splits:
+      - name: train
+        path: data/train/*.csv
+        records: 1
+      - name: train
+        path: data/test
+      - name: eval
+        path: other/*.csv
+      - name: bad name
+        path: data/[
+        records: -1
+`,
+			expected: []expectedProblem{{10, 15, false}, {11, 15, true}, {13, 15, false}, {14, 15, false}, {15, 15, false}, {16, 18, false}},
+		},
+		{
+			name: "model card and metrics",
+			kitfile: `manifestVersion: 1.0.0
+modelcard:
+  intendedUse: Classifying text
+  trainingData: [data, ""]
+metrics:
+  - name: accuracy
+    value: 0.9
+    dataset: data
+    split: test
+  - name: accuracy
+    value: 0.91
+    dataset: data
+    split: test
+  - name: f1
+    value: 1
+    dataset: data
+    split: missing
+datasets:
+  - name: data
+    path: data
+    splits:
+      - name: test
+        path: data/train
+`,
+			expected: []expectedProblem{{4, 24, false}, {10, 5, false}, {17, 12, false}},
+		},
+		{
+			name: "metric value is not a number",
+			kitfile: `manifestVersion: 1.0.0
+metrics:
+  - name: accuracy
+    value: high
+`,
+			expected: []expectedProblem{{4, 12, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems, err := ValidateKitfileYAML([]byte(tt.kitfile), ValidateOptions{ContextDir: contextDir, CheckLicenses: true})
+			if !assert.NoError(t, err) {
+				return
+			}
+			var actual []expectedProblem
+			for _, problem := range problems {
+				actual = append(actual, expectedProblem{problem.Line, problem.Column, problem.Warning})
+			}
+			assert.Equal(t, tt.expected, actual, "problems: %v", problems)
+		})
+	}
+}
+
+func TestValidateKitfileYAMLIncludes(t *testing.T) {
+	contextDir := t.TempDir()
+	writeTestFiles(t, contextDir, map[string]string{
+		"model.gguf": "model",
+		"shared.yaml": `datasets:
+  - name: shared
+    path: missing.csv
+`,
+	})
+	kitfileYAML := `manifestVersion: 1.0.0
+include: [shared.yaml]
+model:
+  path: model.gguf
+  compression: none
+  maxLayerSize: big
+`
+	kitfile, problems, err := ValidateKitfileYAML([]byte(kitfileYAML), ValidateOptions{ContextDir: contextDir})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Problem{
+		{Line: 6, Column: 17, Message: "model  has invalid maxLayerSize: invalid size \"big\""},
+		{File: "shared.yaml", Line: 3, Column: 11, Message: "path missing.csv does not exist"},
+	}, problems)
+	if assert.NotNil(t, kitfile) && assert.Len(t, kitfile.DataSets, 1) {
+		assert.Equal(t, "shared", kitfile.DataSets[0].Name)
+	}
+
+	_, problems, err = ValidateKitfileYAML([]byte("manifestVersion: 1.0.0\ninclude: [missing.yaml]\n"), ValidateOptions{ContextDir: contextDir})
+	if assert.NoError(t, err) && assert.Len(t, problems, 1) {
+		assert.Equal(t, 2, problems[0].Line)
+		assert.Contains(t, problems[0].Message, "failed to read included file missing.yaml")
+	}
+}
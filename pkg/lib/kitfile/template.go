@@ -0,0 +1,169 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"gopkg.in/yaml.v3"
+)
+
+// variableRegexp matches ${VAR} and ${VAR:-default} expressions. A leading '$' ($${VAR}) escapes the
+// expression, which is then replaced with the literal text ${VAR}.
+var variableRegexp = regexp.MustCompile(`\$(\$)?\{([A-Za-z_][A-Za-z0-9_.]*)(?::-([^}]*))?\}`)
+
+// ExpandVariables expands ${VAR} and ${VAR:-default} expressions in the values of a Kitfile. Variables are
+// looked up in vars first and then in the environment. Keys are never expanded, and values within parameters
+// sections are only expanded if expandParameters is true, as parameters may contain arbitrary text. Expressions
+// for variables that are not defined and have no default are left unchanged, as Kitfiles written before variables
+// were supported may contain them as literal text; a warning is printed for each of them.
+//
+// If the Kitfile does not contain any expressions, content is returned unmodified.
+func ExpandVariables(content []byte, vars map[string]string, expandParameters bool) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, err
+	}
+	expander := &variableExpander{vars: vars, expandParameters: expandParameters}
+	expander.expandNode(doc)
+	for _, v := range expander.undefined {
+		output.Logf(output.LogLevelWarn, "Variable %s (line %d) is not defined and is left unchanged in the Kitfile", v.name, v.node.Line)
+	}
+	if !expander.changed {
+		return content, nil
+	}
+	return yaml.Marshal(doc)
+}
+
+type variableExpander struct {
+	vars             map[string]string
+	expandParameters bool
+	changed          bool
+	undefined        []undefinedVariable
+}
+
+type undefinedVariable struct {
+	name string
+	node *yaml.Node
+}
+
+func (e *variableExpander) expandNode(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			e.expandNode(child)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "parameters" && !e.expandParameters {
+				continue
+			}
+			e.expandNode(node.Content[i+1])
+		}
+	case yaml.ScalarNode:
+		expanded := variableRegexp.ReplaceAllStringFunc(node.Value, func(expr string) string {
+			match := variableRegexp.FindStringSubmatch(expr)
+			if match[1] != "" {
+				return expr[1:]
+			}
+			if value, ok := e.lookup(match[2]); ok {
+				return value
+			}
+			if strings.Contains(expr, ":-") {
+				return match[3]
+			}
+			e.undefined = append(e.undefined, undefinedVariable{name: match[2], node: node})
+			return expr
+		})
+		if expanded != node.Value {
+			node.Value = expanded
+			e.changed = true
+			if node.Style == 0 {
+				// Let the type of plain values be resolved from the expanded value (e.g. numbers in parameters)
+				node.Tag = ""
+			}
+		}
+	}
+}
+
+func (e *variableExpander) lookup(name string) (string, bool) {
+	if value, ok := e.vars[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+// ParseVariables parses variables from a values file (if not empty) and from key=value pairs, with pairs
+// taking precedence. Nested keys in the values file are joined with '.' (e.g. ${model.version}).
+func ParseVariables(valuesFile string, pairs []string) (map[string]string, error) {
+	vars := map[string]string{}
+	if valuesFile != "" {
+		valuesBytes, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file: %w", err)
+		}
+		values := map[string]any{}
+		if err := yaml.Unmarshal(valuesBytes, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse values file %s: %w", valuesFile, err)
+		}
+		if err := flattenValues("", values, vars); err != nil {
+			return nil, fmt.Errorf("invalid values file %s: %w", valuesFile, err)
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: must be in the format key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+func flattenValues(prefix string, values map[string]any, vars map[string]string) error {
+	// Sort keys so that errors are reported consistently
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch value := values[key].(type) {
+		case map[string]any:
+			if err := flattenValues(name, value, vars); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("value for %s must not be a list", name)
+		case nil:
+			vars[name] = ""
+		default:
+			vars[name] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
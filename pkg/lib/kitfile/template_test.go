@@ -0,0 +1,98 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kitfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("KIT_TEST_ENV", "from-env")
+	vars := map[string]string{"VERSION": "1.2.0", "model.path": "models/prod"}
+	kitfileYAML := `manifestVersion: 1.0.0
+package:
+  name: ${KIT_TEST_ENV}
+  version: ${VERSION}
+  description: "built with $${VERSION} at ${UNSET:-unknown}"
+model:
+  path: ${model.path}
+  parameters:
+    version: ${VERSION}
+`
+	expanded, err := ExpandVariables([]byte(kitfileYAML), vars, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	kitfile := &artifact.KitFile{}
+	if !assert.NoError(t, yaml.Unmarshal(expanded, kitfile)) {
+		return
+	}
+	assert.Equal(t, "from-env", kitfile.Package.Name)
+	assert.Equal(t, "1.2.0", kitfile.Package.Version)
+	assert.Equal(t, "built with ${VERSION} at unknown", kitfile.Package.Description)
+	assert.Equal(t, "models/prod", kitfile.Model.Path)
+	assert.Equal(t, map[string]any{"version": "${VERSION}"}, kitfile.Model.Parameters)
+
+	expanded, err = ExpandVariables([]byte(kitfileYAML), vars, true)
+	if assert.NoError(t, err) && assert.NoError(t, yaml.Unmarshal(expanded, kitfile)) {
+		assert.Equal(t, map[string]any{"version": "1.2.0"}, kitfile.Model.Parameters)
+	}
+
+	// Undefined variables without a default are left as-is
+	expanded, err = ExpandVariables([]byte("package:\n  name: ${KIT_TEST_UNDEFINED}\n  description: ${VERSION}\n"), vars, false)
+	if assert.NoError(t, err) && assert.NoError(t, yaml.Unmarshal(expanded, kitfile)) {
+		assert.Equal(t, "${KIT_TEST_UNDEFINED}", kitfile.Package.Name)
+		assert.Equal(t, "1.2.0", kitfile.Package.Description)
+	}
+
+	unchanged := []byte("# comment\npackage:\n  name: test\n")
+	expanded, err = ExpandVariables(unchanged, nil, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, unchanged, expanded)
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	valuesPath := filepath.Join(t.TempDir(), "values.yaml")
+	values := `VERSION: 1.0.0
+model:
+  path: models/dev
+  quantized: true
+`
+	if err := os.WriteFile(valuesPath, []byte(values), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := ParseVariables(valuesPath, []string{"VERSION=2.0.0", "EMPTY="})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{
+			"VERSION":         "2.0.0",
+			"EMPTY":           "",
+			"model.path":      "models/dev",
+			"model.quantized": "true",
+		}, vars)
+	}
+
+	_, err = ParseVariables("", []string{"no-equals"})
+	assert.Error(t, err)
+}
@@ -18,6 +18,7 @@ package kitfile
 
 import (
 	"fmt"
+	"math"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -25,67 +26,211 @@ import (
 	"strings"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/repo/util"
+	"github.com/kitops-ml/kitops/pkg/output"
 )
 
 const partTypeMaxLen = 64
 
-var partTypeRegexp = regexp.MustCompile(`^[\w][\w.-]*$`)
+// identifierRegexp matches values that are used as identifiers, such as modelpart types, dataset
+// formats, and split names
+var identifierRegexp = regexp.MustCompile(`^[\w][\w.-]*$`)
+
+// validationIssue is an issue found while validating a Kitfile. The field is the path to the field
+// in the Kitfile that caused the issue, as a list of keys and indices (e.g. ["model", "parts", 0, "type"])
+type validationIssue struct {
+	field   []any
+	message string
+}
 
 // ValidateKitfile returns an error if the parsed Kitfile is not valid. The error string
 // is multiple lines, each consisting of an issue in the kitfile (e.g. duplicate path).
 func ValidateKitfile(kf *artifact.KitFile) error {
+	issues := validateKitfile(kf)
+	if len(issues) == 0 {
+		return nil
+	}
 	var errs []string
-	addErr := func(format string, a ...any) {
-		s := fmt.Sprintf(format, a...)
-		errs = append(errs, fmt.Sprintf("  * %s", s))
+	for _, issue := range issues {
+		errs = append(errs, fmt.Sprintf("  * %s", issue.message))
+	}
+	// Iterating through the paths map is random; sort to get a consistent message
+	slices.Sort(errs)
+	return fmt.Errorf("errors while validating Kitfile: \n%s", strings.Join(errs, "\n"))
+}
+
+func validateKitfile(kf *artifact.KitFile) []validationIssue {
+	var issues []validationIssue
+	addErr := func(field []any, format string, a ...any) {
+		issues = append(issues, validationIssue{field: field, message: fmt.Sprintf(format, a...)})
+	}
+	fieldPath := func(base []any, keys ...any) []any {
+		return append(slices.Clone(base), keys...)
 	}
 
 	// Map of paths to the component that uses them; used to detect duplicate paths
-	paths := map[string][]string{}
-	addPath := func(path, source string) {
+	type pathSource struct {
+		source string
+		field  []any
+	}
+	paths := map[string][]pathSource{}
+	addPath := func(path, source string, field []any) {
 		if path == "" {
 			path = "."
 		}
-		paths[path] = append(paths[path], source)
+		paths[path] = append(paths[path], pathSource{source, fieldPath(field, "path")})
+	}
+	checkLayerSettings := func(source string, field []any, maxLayerSize, compression string) {
+		if maxLayerSize != "" {
+			if _, err := output.ParseBytes(maxLayerSize); err != nil {
+				addErr(fieldPath(field, "maxLayerSize"), "%s has invalid maxLayerSize: %s", source, err)
+			}
+		}
+		if compression != "" {
+			if err := constants.IsValidCompression(compression); err != nil {
+				addErr(fieldPath(field, "compression"), "%s has %s", source, err)
+			}
+		}
+	}
+
+	if len(kf.Include) > 0 {
+		addErr([]any{"include"}, "Kitfile includes must be resolved before it is used")
 	}
 
 	if kf.Model != nil {
-		addPath(kf.Model.Path, fmt.Sprintf("model %s", kf.Model.Name))
-		for _, part := range kf.Model.Parts {
-			addPath(part.Path, fmt.Sprintf("modelpart %s", part.Name))
+		modelField := []any{"model"}
+		addPath(kf.Model.Path, fmt.Sprintf("model %s", kf.Model.Name), modelField)
+		checkLayerSettings(fmt.Sprintf("model %s", kf.Model.Name), modelField, kf.Model.MaxLayerSize, kf.Model.Compression)
+		for idx, part := range kf.Model.Parts {
+			partField := fieldPath(modelField, "parts", idx)
+			addPath(part.Path, fmt.Sprintf("modelpart %s", part.Name), partField)
+			checkLayerSettings(fmt.Sprintf("modelpart %s", part.Name), partField, part.MaxLayerSize, part.Compression)
 			if part.Type != "" {
-				if !partTypeRegexp.MatchString(part.Type) {
-					addErr("modelpart %s has invalid type (must be alphanumeric with dots, dashes, and underscores)", part.Name)
+				if !identifierRegexp.MatchString(part.Type) {
+					addErr(fieldPath(partField, "type"), "modelpart %s has invalid type (must be alphanumeric with dots, dashes, and underscores)", part.Name)
 				}
 				if len(part.Type) > partTypeMaxLen {
-					addErr("modelpart %s type is too long (must be fewer than %d characters)", part.Name, partTypeMaxLen)
+					addErr(fieldPath(partField, "type"), "modelpart %s type is too long (must be fewer than %d characters)", part.Name, partTypeMaxLen)
 				}
 			}
 		}
 	}
-	for _, dataset := range kf.DataSets {
-		addPath(dataset.Path, fmt.Sprintf("dataset %s", dataset.Name))
+	checkFormat := func(source string, field []any, format string) {
+		if format != "" && !identifierRegexp.MatchString(format) {
+			addErr(fieldPath(field, "format"), "%s has invalid format (must be alphanumeric with dots, dashes, and underscores)", source)
+		}
+	}
+	for idx, dataset := range kf.DataSets {
+		datasetField := []any{"datasets", idx}
+		addPath(dataset.Path, fmt.Sprintf("dataset %s", dataset.Name), datasetField)
+		checkLayerSettings(fmt.Sprintf("dataset %s", dataset.Name), datasetField, dataset.MaxLayerSize, dataset.Compression)
+		checkFormat(fmt.Sprintf("dataset %s", dataset.Name), datasetField, dataset.Format)
+		if len(dataset.Splits) > 0 && util.IsModelKitReference(dataset.Path) {
+			addErr(fieldPath(datasetField, "splits"), "dataset %s refers to another modelkit and cannot define splits", dataset.Name)
+		}
+		splitNames := map[string]bool{}
+		for splitIdx, split := range dataset.Splits {
+			splitField := fieldPath(datasetField, "splits", splitIdx)
+			splitSource := fmt.Sprintf("split %s of dataset %s", split.Name, dataset.Name)
+			switch {
+			case split.Name == "":
+				addErr(fieldPath(splitField, "name"), "split %d of dataset %s does not have a name", splitIdx, dataset.Name)
+			case !identifierRegexp.MatchString(split.Name):
+				addErr(fieldPath(splitField, "name"), "%s has invalid name (must be alphanumeric with dots, dashes, and underscores)", splitSource)
+			case splitNames[split.Name]:
+				addErr(fieldPath(splitField, "name"), "dataset %s has multiple splits named %s", dataset.Name, split.Name)
+			}
+			splitNames[split.Name] = true
+			if err := validateSplitPath(dataset.Path, split.Path); err != nil {
+				addErr(fieldPath(splitField, "path"), "%s has invalid path: %s", splitSource, err)
+			}
+			checkFormat(splitSource, splitField, split.Format)
+			if split.Records < 0 {
+				addErr(fieldPath(splitField, "records"), "%s has a negative number of records", splitSource)
+			}
+		}
+	}
+	if kf.ModelCard != nil {
+		for idx, data := range kf.ModelCard.TrainingData {
+			if strings.TrimSpace(data) == "" {
+				addErr([]any{"modelcard", "trainingData", idx}, "modelcard training data entry %d is empty", idx)
+			}
+		}
+	}
+	type metricKey struct{ name, dataset, split string }
+	metricKeys := map[metricKey]bool{}
+	for idx, metric := range kf.Metrics {
+		metricField := []any{"metrics", idx}
+		if metric.Name == "" {
+			addErr(fieldPath(metricField, "name"), "metric %d does not have a name", idx)
+		}
+		if math.IsNaN(metric.Value) || math.IsInf(metric.Value, 0) {
+			addErr(fieldPath(metricField, "value"), "metric %s has invalid value %v", metric.Name, metric.Value)
+		}
+		key := metricKey{metric.Name, metric.Dataset, metric.Split}
+		if metricKeys[key] {
+			addErr(metricField, "metric %s is listed more than once for the same dataset and split", metric.Name)
+		}
+		metricKeys[key] = true
+		if metric.Split == "" {
+			continue
+		}
+		if metric.Dataset == "" {
+			addErr(fieldPath(metricField, "split"), "metric %s has a split but no dataset", metric.Name)
+			continue
+		}
+		// Splits can only be checked for datasets in the Kitfile; other datasets are external references
+		datasetIdx := slices.IndexFunc(kf.DataSets, func(d artifact.DataSet) bool { return d.Name == metric.Dataset })
+		if datasetIdx == -1 || len(kf.DataSets[datasetIdx].Splits) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(kf.DataSets[datasetIdx].Splits, func(s artifact.DatasetSplit) bool { return s.Name == metric.Split }) {
+			addErr(fieldPath(metricField, "split"), "metric %s refers to split %s, which is not defined by dataset %s", metric.Name, metric.Split, metric.Dataset)
+		}
 	}
 	for idx, code := range kf.Code {
-		addPath(code.Path, fmt.Sprintf("code layer %d", idx))
+		codeField := []any{"code", idx}
+		addPath(code.Path, fmt.Sprintf("code layer %d", idx), codeField)
+		checkLayerSettings(fmt.Sprintf("code layer %d", idx), codeField, code.MaxLayerSize, code.Compression)
 	}
 	for idx, docs := range kf.Docs {
-		addPath(docs.Path, fmt.Sprintf("docs layer %d", idx))
+		docsField := []any{"docs", idx}
+		addPath(docs.Path, fmt.Sprintf("docs layer %d", idx), docsField)
+		checkLayerSettings(fmt.Sprintf("docs layer %d", idx), docsField, docs.MaxLayerSize, docs.Compression)
 	}
 
-	for layerPath, layerIds := range paths {
-		if len := len(layerIds); len > 1 {
-			addErr("%s and %s use the same path %s", strings.Join(layerIds[:len-1], ", "), layerIds[len-1], layerPath)
+	for layerPath, sources := range paths {
+		if len := len(sources); len > 1 {
+			var layerIds []string
+			for _, source := range sources {
+				layerIds = append(layerIds, source.source)
+			}
+			addErr(sources[len-1].field, "%s and %s use the same path %s", strings.Join(layerIds[:len-1], ", "), layerIds[len-1], layerPath)
 		}
 		if path.IsAbs(layerPath) || filepath.IsAbs(layerPath) {
-			addErr("absolute paths are not supported in a Kitfile (path %s in %s)", layerPath, layerIds[0])
+			addErr(sources[0].field, "absolute paths are not supported in a Kitfile (path %s in %s)", layerPath, sources[0].source)
 		}
 	}
-	if len(errs) > 0 {
-		// Iterating through the paths map is random; sort to get a consistent message
-		slices.Sort(errs)
-		return fmt.Errorf("errors while validating Kitfile: \n%s", strings.Join(errs, "\n"))
-	}
+	return issues
+}
 
+// validateSplitPath checks that the glob pattern for a dataset split is valid and can only match paths
+// within the dataset's path.
+func validateSplitPath(datasetPath, pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("path is required")
+	}
+	if path.IsAbs(pattern) || filepath.IsAbs(pattern) {
+		return fmt.Errorf("absolute paths are not supported")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	cleanPattern := path.Clean(filepath.ToSlash(pattern))
+	basePath := path.Clean(filepath.ToSlash(datasetPath))
+	if basePath != "." && cleanPattern != basePath && !strings.HasPrefix(cleanPattern, basePath+"/") {
+		return fmt.Errorf("%s is not within the dataset path %s", pattern, datasetPath)
+	}
 	return nil
 }
@@ -0,0 +1,68 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package modelpack
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Model is the config of a ModelPack artifact, as defined by the ModelPack specification.
+type Model struct {
+	Descriptor ModelDescriptor `json:"descriptor"`
+	ModelFS    ModelFS         `json:"modelfs"`
+	Config     ModelConfig     `json:"config"`
+}
+
+// ModelDescriptor describes the packaged model.
+type ModelDescriptor struct {
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	Authors     []string   `json:"authors,omitempty"`
+	Family      string     `json:"family,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	DocURL      string     `json:"docURL,omitempty"`
+	SourceURL   string     `json:"sourceURL,omitempty"`
+	DatasetsURL []string   `json:"datasetsURL,omitempty"`
+	Version     string     `json:"version,omitempty"`
+	Revision    string     `json:"revision,omitempty"`
+	Vendor      string     `json:"vendor,omitempty"`
+	Licenses    []string   `json:"licenses,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+}
+
+// ModelFS lists the digests of the uncompressed layers of the artifact, in manifest order.
+type ModelFS struct {
+	Type    string          `json:"type"`
+	DiffIDs []digest.Digest `json:"diffIds"`
+}
+
+// ModelConfig describes the execution parameters of the model.
+type ModelConfig struct {
+	Architecture string `json:"architecture,omitempty"`
+	Format       string `json:"format,omitempty"`
+	ParamSize    string `json:"paramSize,omitempty"`
+	Precision    string `json:"precision,omitempty"`
+	Quantization string `json:"quantization,omitempty"`
+	// Capabilities are not used by KitOps, but are preserved when reading and writing configs
+	Capabilities json.RawMessage `json:"capabilities,omitempty"`
+}
+
+// modelFSTypeLayers is the only ModelFS type defined by the specification
+const modelFSTypeLayers = "layers"
@@ -0,0 +1,363 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package modelpack
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// DiffIDFunc returns the digest of the uncompressed contents of a layer. It is used when converting
+// modelkits that do not record diffIDs in their Kitfile.
+type DiffIDFunc func(desc ocispec.Descriptor) (digest.Digest, error)
+
+// IsModelPackManifest returns true if manifest describes a ModelPack artifact.
+func IsModelPackManifest(manifest *ocispec.Manifest) bool {
+	if manifest.Config.MediaType != constants.ModelPackConfigMediaType {
+		return false
+	}
+	return manifest.ArtifactType == "" || manifest.ArtifactType == constants.ModelPackArtifactType
+}
+
+// LayerEntryFunc returns the path and layer info of the Kitfile entry that the next layer of type baseType
+// in a modelkit manifest was packed from. It is used to set the file path of converted layers.
+type LayerEntryFunc func(baseType string) (path string, layerInfo *artifact.LayerInfo, err error)
+
+// FromModelKit converts a modelkit manifest to a ModelPack manifest, returning the new manifest and the bytes
+// of its config. Layers are reused as-is, with only their media types and annotations changed. The modelkit's
+// config (rawKitfile) is stored in the manifest's annotations so that ToModelKit can restore it exactly.
+func FromModelKit(manifest *ocispec.Manifest, kitfile *artifact.KitFile, rawKitfile []byte, nextEntry LayerEntryFunc, diffID DiffIDFunc) (*ocispec.Manifest, []byte, error) {
+	var layers []ocispec.Descriptor
+	var diffIDs []digest.Digest
+	for _, layerDesc := range manifest.Layers {
+		mediaType := constants.ParseMediaType(layerDesc.MediaType)
+		if mediaType.BaseType == "" || mediaType.ModelPack {
+			return nil, nil, fmt.Errorf("unsupported layer media type %s", layerDesc.MediaType)
+		}
+		if mediaType.Chunked || mediaType.BaseType == constants.ChunkType {
+			return nil, nil, fmt.Errorf("chunked layers cannot be converted to ModelPack layers")
+		}
+		entryPath, layerInfo, err := nextEntry(mediaType.BaseType)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		layerDiffID := digest.Digest("")
+		if layerInfo != nil && layerInfo.DiffId != "" {
+			layerDiffID = digest.Digest(layerInfo.DiffId)
+		} else if mediaType.Compression == constants.NoneCompression {
+			layerDiffID = layerDesc.Digest
+		} else if layerDiffID, err = diffID(layerDesc); err != nil {
+			return nil, nil, fmt.Errorf("failed to compute diffID for layer %s: %w", layerDesc.Digest, err)
+		}
+		diffIDs = append(diffIDs, layerDiffID)
+
+		mediaType.ModelPack = true
+		converted := layerDesc
+		converted.MediaType = mediaType.String()
+		converted.Annotations = map[string]string{}
+		for key, value := range layerDesc.Annotations {
+			converted.Annotations[key] = value
+		}
+		if title := layerDesc.Annotations[ocispec.AnnotationTitle]; title != "" {
+			entryPath = title
+		}
+		converted.Annotations[constants.ModelPackFilepathAnnotation] = entryPath
+		layers = append(layers, converted)
+	}
+
+	config := configFromKitfile(kitfile, manifest.Annotations)
+	config.ModelFS.DiffIDs = diffIDs
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate ModelPack config: %w", err)
+	}
+
+	converted := *manifest
+	converted.ArtifactType = constants.ModelPackArtifactType
+	converted.Config = content.NewDescriptorFromBytes(constants.ModelPackConfigMediaType, configBytes)
+	converted.Layers = layers
+	converted.Annotations = map[string]string{}
+	for key, value := range manifest.Annotations {
+		converted.Annotations[key] = value
+	}
+	converted.Annotations[constants.KitfileAnnotation] = string(rawKitfile)
+	return &converted, configBytes, nil
+}
+
+// ToModelKit converts a ModelPack manifest and its config to a modelkit manifest, returning the new manifest
+// and the bytes of its config (Kitfile). If the artifact was created by FromModelKit, the original modelkit
+// is restored; otherwise, a Kitfile is generated from the ModelPack config.
+func ToModelKit(manifest *ocispec.Manifest, configBytes []byte) (*ocispec.Manifest, []byte, error) {
+	var layers []ocispec.Descriptor
+	for _, layerDesc := range manifest.Layers {
+		mediaType := constants.ParseMediaType(layerDesc.MediaType)
+		if !mediaType.ModelPack {
+			return nil, nil, fmt.Errorf("unsupported layer media type %s", layerDesc.MediaType)
+		}
+		if mediaType.Raw {
+			return nil, nil, fmt.Errorf("raw ModelPack layers cannot be converted to modelkit layers")
+		}
+		if err := constants.IsValidCompression(mediaType.Compression); err != nil {
+			return nil, nil, fmt.Errorf("unsupported compression %s for layer %s", mediaType.Compression, layerDesc.Digest)
+		}
+		mediaType.ModelPack = false
+		converted := layerDesc
+		converted.MediaType = mediaType.String()
+		converted.Annotations = map[string]string{}
+		for key, value := range layerDesc.Annotations {
+			converted.Annotations[key] = value
+		}
+		if filepath, ok := converted.Annotations[constants.ModelPackFilepathAnnotation]; ok {
+			if converted.Annotations[ocispec.AnnotationTitle] == "" {
+				converted.Annotations[ocispec.AnnotationTitle] = filepath
+			}
+			delete(converted.Annotations, constants.ModelPackFilepathAnnotation)
+		}
+		if len(converted.Annotations) == 0 {
+			converted.Annotations = nil
+		}
+		layers = append(layers, converted)
+	}
+
+	var kitfileBytes []byte
+	if rawKitfile, ok := manifest.Annotations[constants.KitfileAnnotation]; ok {
+		kitfileBytes = []byte(rawKitfile)
+	} else {
+		kitfile, err := ToKitfile(manifest, configBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		kitfileBytes, err = kitfile.MarshalToJSON()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate Kitfile: %w", err)
+		}
+	}
+
+	converted := *manifest
+	converted.ArtifactType = ""
+	converted.Config = content.NewDescriptorFromBytes(constants.ModelConfigMediaType.String(), kitfileBytes)
+	converted.Layers = layers
+	converted.Annotations = map[string]string{}
+	for key, value := range manifest.Annotations {
+		if key != constants.KitfileAnnotation {
+			converted.Annotations[key] = value
+		}
+	}
+	if len(converted.Annotations) == 0 {
+		converted.Annotations = nil
+	}
+	return &converted, kitfileBytes, nil
+}
+
+// ToKitfile returns the Kitfile describing a ModelPack artifact. If the artifact was converted from a modelkit,
+// the original Kitfile is returned. Otherwise, a Kitfile is generated from the ModelPack config and the paths
+// of the artifact's layers.
+func ToKitfile(manifest *ocispec.Manifest, configBytes []byte) (*artifact.KitFile, error) {
+	if rawKitfile, ok := manifest.Annotations[constants.KitfileAnnotation]; ok {
+		kitfile := &artifact.KitFile{}
+		if err := json.Unmarshal([]byte(rawKitfile), kitfile); err != nil {
+			return nil, fmt.Errorf("failed to parse Kitfile annotation: %w", err)
+		}
+		return kitfile, nil
+	}
+
+	config := &Model{}
+	if err := json.Unmarshal(configBytes, config); err != nil {
+		return nil, fmt.Errorf("failed to parse ModelPack config: %w", err)
+	}
+	if config.ModelFS.Type != "" && config.ModelFS.Type != modelFSTypeLayers {
+		return nil, fmt.Errorf("unsupported ModelPack modelfs type %s", config.ModelFS.Type)
+	}
+
+	kitfile := &artifact.KitFile{
+		ManifestVersion: "1.0.0",
+		Package: artifact.Package{
+			Name:        config.Descriptor.Name,
+			Version:     config.Descriptor.Version,
+			Description: config.Descriptor.Description,
+			License:     strings.Join(config.Descriptor.Licenses, ", "),
+			Authors:     config.Descriptor.Authors,
+		},
+	}
+	if kitfile.Package.Name == "" {
+		kitfile.Package.Name = config.Descriptor.Title
+	}
+
+	var weightPaths []string
+	var weightShards []artifact.LayerShard
+	for idx, layerDesc := range manifest.Layers {
+		layerInfo := &artifact.LayerInfo{Digest: layerDesc.Digest.String()}
+		if len(config.ModelFS.DiffIDs) == len(manifest.Layers) {
+			layerInfo.DiffId = config.ModelFS.DiffIDs[idx].String()
+		}
+		layerPath := layerFilepath(layerDesc)
+
+		mediaType := constants.ParseMediaType(layerDesc.MediaType)
+		switch mediaType.BaseType {
+		case constants.ModelType:
+			weightPaths = append(weightPaths, layerPath)
+			weightShards = append(weightShards, artifact.LayerShard{Digest: layerInfo.Digest, DiffId: layerInfo.DiffId})
+		case constants.ModelPartType:
+			if kitfile.Model == nil {
+				kitfile.Model = &artifact.Model{}
+			}
+			kitfile.Model.Parts = append(kitfile.Model.Parts, artifact.ModelPart{
+				Name:      path.Base(layerPath),
+				Path:      layerPath,
+				LayerInfo: layerInfo,
+			})
+		case constants.DatasetType:
+			kitfile.DataSets = append(kitfile.DataSets, artifact.DataSet{
+				Name:      path.Base(layerPath),
+				Path:      layerPath,
+				LayerInfo: layerInfo,
+			})
+		case constants.CodeType:
+			kitfile.Code = append(kitfile.Code, artifact.Code{
+				Path:      layerPath,
+				LayerInfo: layerInfo,
+			})
+		case constants.DocsType:
+			kitfile.Docs = append(kitfile.Docs, artifact.Docs{
+				Path:      layerPath,
+				LayerInfo: layerInfo,
+			})
+		default:
+			return nil, fmt.Errorf("unsupported layer media type %s", layerDesc.MediaType)
+		}
+	}
+
+	if len(weightShards) > 0 {
+		if kitfile.Model == nil {
+			kitfile.Model = &artifact.Model{}
+		}
+		kitfile.Model.Name = config.Descriptor.Name
+		kitfile.Model.Path = commonDir(weightPaths)
+		kitfile.Model.Format = config.Config.Format
+		kitfile.Model.Parameters = configParameters(config.Config)
+		kitfile.Model.LayerInfo = &artifact.LayerInfo{
+			Digest: weightShards[0].Digest,
+			DiffId: weightShards[0].DiffId,
+		}
+		if len(weightShards) > 1 {
+			kitfile.Model.LayerInfo.Shards = weightShards
+		}
+	}
+	return kitfile, nil
+}
+
+// configFromKitfile generates a ModelPack config for a Kitfile. Fields that are not part of the Kitfile are
+// read from the modelkit's manifest annotations where possible. The config's diffIDs are not set.
+func configFromKitfile(kitfile *artifact.KitFile, annotations map[string]string) *Model {
+	config := &Model{
+		Descriptor: ModelDescriptor{
+			Authors:     kitfile.Package.Authors,
+			Name:        kitfile.Package.Name,
+			Version:     kitfile.Package.Version,
+			Description: kitfile.Package.Description,
+			SourceURL:   annotations[ocispec.AnnotationSource],
+			Revision:    annotations[ocispec.AnnotationRevision],
+		},
+		ModelFS: ModelFS{Type: modelFSTypeLayers},
+	}
+	if kitfile.Package.License != "" {
+		config.Descriptor.Licenses = []string{kitfile.Package.License}
+	}
+	if created, err := time.Parse(time.RFC3339, annotations[ocispec.AnnotationCreated]); err == nil {
+		config.Descriptor.CreatedAt = &created
+	}
+	if kitfile.Model != nil {
+		if config.Descriptor.Name == "" {
+			config.Descriptor.Name = kitfile.Model.Name
+		}
+		config.Config.Format = kitfile.Model.Format
+		if params, ok := kitfile.Model.Parameters.(map[string]any); ok {
+			config.Config.Architecture = stringParameter(params, "architecture")
+			config.Config.ParamSize = stringParameter(params, "paramSize")
+			config.Config.Precision = stringParameter(params, "precision")
+			config.Config.Quantization = stringParameter(params, "quantization")
+		}
+	}
+	return config
+}
+
+// configParameters returns the ModelPack config fields that have no equivalent in the Kitfile, to be stored
+// as model parameters. Returns nil if none are set.
+func configParameters(config ModelConfig) any {
+	params := map[string]any{}
+	setParameter := func(key, value string) {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	setParameter("architecture", config.Architecture)
+	setParameter("paramSize", config.ParamSize)
+	setParameter("precision", config.Precision)
+	setParameter("quantization", config.Quantization)
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+func stringParameter(params map[string]any, key string) string {
+	switch value := params[key].(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// layerFilepath returns the path stored in a ModelPack layer's annotations
+func layerFilepath(desc ocispec.Descriptor) string {
+	if filepath := desc.Annotations[constants.ModelPackFilepathAnnotation]; filepath != "" {
+		return filepath
+	}
+	if title := desc.Annotations[ocispec.AnnotationTitle]; title != "" {
+		return title
+	}
+	return "."
+}
+
+// commonDir returns the longest directory that contains all paths. If there is only one path, it is
+// returned as-is.
+func commonDir(paths []string) string {
+	if len(paths) == 1 {
+		return paths[0]
+	}
+	common := path.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for common != "." && common != "/" && !strings.HasPrefix(p, common+"/") {
+			common = path.Dir(common)
+		}
+	}
+	return common
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package modelpack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func layerDesc(mediaType, content string, annotations map[string]string) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:   mediaType,
+		Digest:      digest.FromString(content),
+		Size:        int64(len(content)),
+		Annotations: annotations,
+	}
+}
+
+func TestToKitfileGenerated(t *testing.T) {
+	manifest := &ocispec.Manifest{
+		ArtifactType: constants.ModelPackArtifactType,
+		Config:       ocispec.Descriptor{MediaType: constants.ModelPackConfigMediaType},
+		Layers: []ocispec.Descriptor{
+			layerDesc("application/vnd.cncf.model.weight.v1.tar", "w1", map[string]string{constants.ModelPackFilepathAnnotation: "weights/model-1.safetensors"}),
+			layerDesc("application/vnd.cncf.model.weight.v1.tar", "w2", map[string]string{constants.ModelPackFilepathAnnotation: "weights/model-2.safetensors"}),
+			layerDesc("application/vnd.cncf.model.weight.config.v1.tar", "c", map[string]string{constants.ModelPackFilepathAnnotation: "config.json"}),
+			layerDesc("application/vnd.cncf.model.doc.v1.tar+gzip", "d", map[string]string{ocispec.AnnotationTitle: "README.md"}),
+		},
+	}
+	config := Model{
+		Descriptor: ModelDescriptor{Name: "llama", Version: "3", Licenses: []string{"MIT", "Apache-2.0"}},
+		ModelFS:    ModelFS{Type: "layers", DiffIDs: []digest.Digest{"sha256:w1", "sha256:w2", "sha256:c", "sha256:d"}},
+		Config:     ModelConfig{Format: "safetensors", ParamSize: "8b"},
+	}
+	configBytes, err := json.Marshal(config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	kitfile, err := ToKitfile(manifest, configBytes)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "llama", kitfile.Package.Name)
+	assert.Equal(t, "MIT, Apache-2.0", kitfile.Package.License)
+	if assert.NotNil(t, kitfile.Model) {
+		assert.Equal(t, "weights", kitfile.Model.Path)
+		assert.Equal(t, "safetensors", kitfile.Model.Format)
+		assert.Equal(t, map[string]any{"paramSize": "8b"}, kitfile.Model.Parameters)
+		assert.Equal(t, 2, kitfile.Model.LayerInfo.NumLayers())
+		assert.Equal(t, manifest.Layers[1].Digest.String(), kitfile.Model.LayerInfo.Shard(1).Digest)
+		assert.Equal(t, "sha256:w2", kitfile.Model.LayerInfo.Shard(1).DiffId)
+		if assert.Len(t, kitfile.Model.Parts, 1) {
+			assert.Equal(t, "config.json", kitfile.Model.Parts[0].Path)
+		}
+	}
+	if assert.Len(t, kitfile.Docs, 1) {
+		assert.Equal(t, "README.md", kitfile.Docs[0].Path)
+		assert.Equal(t, "sha256:d", kitfile.Docs[0].DiffId)
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	kitfile := &artifact.KitFile{
+		ManifestVersion: "1.0.0",
+		Package:         artifact.Package{Name: "test", License: "MIT"},
+		Model: &artifact.Model{
+			Path:       "model",
+			Format:     "gguf",
+			Parameters: map[string]any{"quantization": "q4"},
+			LayerInfo: &artifact.LayerInfo{
+				Digest: digest.FromString("m1").String(),
+				DiffId: digest.FromString("m1").String(),
+				Shards: []artifact.LayerShard{
+					{Digest: digest.FromString("m1").String(), DiffId: digest.FromString("m1").String()},
+					{Digest: digest.FromString("m2").String(), DiffId: digest.FromString("m2").String()},
+				},
+			},
+		},
+		DataSets: []artifact.DataSet{{Name: "train", Path: "train.csv"}},
+	}
+	rawKitfile, err := kitfile.MarshalToJSON()
+	if !assert.NoError(t, err) {
+		return
+	}
+	manifest := &ocispec.Manifest{
+		Config: ocispec.Descriptor{MediaType: constants.ModelConfigMediaType.String()},
+		Layers: []ocispec.Descriptor{
+			layerDesc("application/vnd.kitops.modelkit.model.v1.tar", "m1", map[string]string{ocispec.AnnotationTitle: "model"}),
+			layerDesc("application/vnd.kitops.modelkit.model.v1.tar", "m2", map[string]string{ocispec.AnnotationTitle: "model"}),
+			layerDesc("application/vnd.kitops.modelkit.dataset.v1.tar+gzip", "d", nil),
+		},
+		Annotations: map[string]string{ocispec.AnnotationCreated: "2024-01-01T00:00:00Z"},
+	}
+	diffID := func(desc ocispec.Descriptor) (digest.Digest, error) {
+		return digest.FromString("uncompressed"), nil
+	}
+
+	// Layers are matched to Kitfile entries by kitfile.LayerCursor when converting; this test only needs
+	// the entries for each layer in order
+	entries := []struct {
+		path      string
+		layerInfo *artifact.LayerInfo
+	}{
+		{"model", kitfile.Model.LayerInfo.Shard(0)},
+		{"model", kitfile.Model.LayerInfo.Shard(1)},
+		{"train.csv", nil},
+	}
+	nextEntry := func(baseType string) (string, *artifact.LayerInfo, error) {
+		entry := entries[0]
+		entries = entries[1:]
+		return entry.path, entry.layerInfo, nil
+	}
+	converted, configBytes, err := FromModelKit(manifest, kitfile, rawKitfile, nextEntry, diffID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, IsModelPackManifest(converted))
+	assert.Equal(t, "application/vnd.cncf.model.weight.v1.tar", converted.Layers[0].MediaType)
+	assert.Equal(t, "application/vnd.cncf.model.dataset.v1.tar+gzip", converted.Layers[2].MediaType)
+	assert.Equal(t, "train.csv", converted.Layers[2].Annotations[constants.ModelPackFilepathAnnotation])
+	config := &Model{}
+	if assert.NoError(t, json.Unmarshal(configBytes, config)) {
+		assert.Equal(t, "q4", config.Config.Quantization)
+		assert.Equal(t, []digest.Digest{digest.FromString("m1"), digest.FromString("m2"), digest.FromString("uncompressed")}, config.ModelFS.DiffIDs)
+		assert.Equal(t, "2024-01-01T00:00:00Z", config.Descriptor.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	}
+
+	restored, restoredKitfile, err := ToModelKit(converted, configBytes)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, rawKitfile, restoredKitfile)
+	assert.Equal(t, manifest.Layers[0], restored.Layers[0])
+	assert.Equal(t, "application/vnd.kitops.modelkit.dataset.v1.tar+gzip", restored.Layers[2].MediaType)
+	assert.Equal(t, manifest.Annotations, restored.Annotations)
+	assert.Empty(t, restored.ArtifactType)
+}
+
+func TestConvertUnsupportedLayers(t *testing.T) {
+	chunked := &ocispec.Manifest{
+		Layers: []ocispec.Descriptor{layerDesc("application/vnd.kitops.modelkit.model.v1.chunked.tar", "m", nil)},
+	}
+	_, _, err := FromModelKit(chunked, &artifact.KitFile{Model: &artifact.Model{Path: "model"}}, nil, nil, nil)
+	assert.Error(t, err)
+
+	for _, mediaType := range []string{"application/vnd.cncf.model.weight.v1.raw", "application/vnd.cncf.model.weight.v1.tar+zstd"} {
+		modelPack := &ocispec.Manifest{
+			Config: ocispec.Descriptor{MediaType: constants.ModelPackConfigMediaType},
+			Layers: []ocispec.Descriptor{layerDesc(mediaType, "m", nil)},
+		}
+		_, _, err := ToModelKit(modelPack, []byte("{}"))
+		assert.Error(t, err, mediaType)
+	}
+}
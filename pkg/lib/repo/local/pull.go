@@ -38,12 +38,12 @@ import (
 )
 
 func (l *localRepo) PullModel(ctx context.Context, src oras.ReadOnlyTarget, ref registry.Reference, opts *options.NetworkOptions) (ocispec.Descriptor, error) {
-	// Only support pulling image manifests
+	// Only support pulling image manifests and indexes of modelkit variants
 	desc, err := src.Resolve(ctx, ref.Reference)
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, err
 	}
-	if desc.MediaType != ocispec.MediaTypeImageManifest {
+	if desc.MediaType != ocispec.MediaTypeImageManifest && desc.MediaType != ocispec.MediaTypeImageIndex {
 		return ocispec.DescriptorEmptyJSON, fmt.Errorf("expected manifest for pull but got %s", desc.MediaType)
 	}
 
@@ -53,9 +53,48 @@ func (l *localRepo) PullModel(ctx context.Context, src oras.ReadOnlyTarget, ref
 
 	progress := output.NewPullProgress(ctx)
 
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		index, err := util.GetIndex(ctx, src, desc)
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+		// Pull each variant as a regular modelkit, then pull the index that groups them
+		for _, variantDesc := range index.Manifests {
+			if err := l.pullManifest(ctx, src, variantDesc, opts, progress); err != nil {
+				return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to pull variant %s: %w", util.VariantName(variantDesc), err)
+			}
+		}
+		if err := l.pullNode(ctx, src, desc, progress); err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to get index: %w", err)
+		}
+		if err := l.addPulledManifest(ctx, desc); err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+	} else {
+		if err := l.pullManifest(ctx, src, desc, opts, progress); err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+	}
+
+	if !util.ReferenceIsDigest(ref.Reference) {
+		if err := l.localIndex.tag(desc, ref.Reference); err != nil {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to save tag: %w", err)
+		}
+	}
+	progress.Done()
+
+	if err := l.cleanupIngestDir(); err != nil {
+		output.Logln(output.LogLevelWarn, err)
+	}
+
+	return desc, nil
+}
+
+// pullManifest pulls a modelkit manifest along with its config and layers and adds it to the local repository.
+func (l *localRepo) pullManifest(ctx context.Context, src oras.ReadOnlyTarget, desc ocispec.Descriptor, opts *options.NetworkOptions, progress *output.PullProgress) error {
 	manifest, err := util.GetManifest(ctx, src, desc)
 	if err != nil {
-		return ocispec.DescriptorEmptyJSON, err
+		return err
 	}
 
 	toPull := []ocispec.Descriptor{manifest.Config}
@@ -91,33 +130,33 @@ func (l *localRepo) PullModel(ctx context.Context, src oras.ReadOnlyTarget, ref
 		})
 	}
 	if err := errs.Wait(); err != nil {
-		return ocispec.DescriptorEmptyJSON, err
+		return err
 	}
 	if semErr != nil {
-		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to acquire lock: %w", semErr)
+		return fmt.Errorf("failed to acquire lock: %w", semErr)
 	}
 
+	return l.addPulledManifest(ctx, desc)
+}
+
+// addPulledManifest records a pulled manifest or index in both the local (scoped) repo and the shared index.
+func (l *localRepo) addPulledManifest(ctx context.Context, desc ocispec.Descriptor) error {
+	// Index entries for variants carry annotations that do not belong to the manifest itself
+	desc = ocispec.Descriptor{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+		ArtifactType: desc.ArtifactType,
+	}
 	// Special handling to make sure local (scoped) repo contains the just-pulled manifest
 	if err := l.localIndex.addManifest(desc); err != nil {
-		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to add manifest to index: %w", err)
+		return fmt.Errorf("failed to add manifest to index: %w", err)
 	}
 	// This is a workaround to add the manifest to the main index as well; this is necessary for garbage collection to work
 	if err := l.Store.Tag(ctx, desc, desc.Digest.String()); err != nil {
-		return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to add manifest to shared index: %w", err)
-	}
-
-	if !util.ReferenceIsDigest(ref.Reference) {
-		if err := l.localIndex.tag(desc, ref.Reference); err != nil {
-			return ocispec.DescriptorEmptyJSON, fmt.Errorf("failed to save tag: %w", err)
-		}
+		return fmt.Errorf("failed to add manifest to shared index: %w", err)
 	}
-	progress.Done()
-
-	if err := l.cleanupIngestDir(); err != nil {
-		output.Logln(output.LogLevelWarn, err)
-	}
-
-	return desc, nil
+	return nil
 }
 
 func (l *localRepo) pullNode(ctx context.Context, src oras.ReadOnlyTarget, desc ocispec.Descriptor, p *output.PullProgress) error {
@@ -18,6 +18,7 @@ package local
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +33,7 @@ import (
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
 	"github.com/kitops-ml/kitops/pkg/output"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
@@ -132,24 +134,98 @@ func (lr *localRepo) BlobPath(desc ocispec.Descriptor) string {
 
 func (lr *localRepo) Delete(ctx context.Context, target ocispec.Descriptor) error {
 	output.SafeLogf(output.LogLevelTrace, "Deleting digest %s in local repository %s", target.Digest.String(), lr.nameRef)
-	if target.MediaType != ocispec.MediaTypeImageManifest {
+	if !isManifestMediaType(target.MediaType) {
 		return lr.Store.Delete(ctx, target)
 	}
+	if indexDigest, ok := lr.variantOf(ctx, target); ok {
+		return fmt.Errorf("manifest is a variant in index %s", indexDigest)
+	}
+	// Read the index before deleting it so that its variants can be cleaned up afterwards
+	var index *ocispec.Index
+	if target.MediaType == ocispec.MediaTypeImageIndex {
+		var err error
+		if index, err = lr.readIndex(ctx, target); err != nil {
+			return err
+		}
+	}
 
 	canDelete, err := canSafelyDeleteManifest(ctx, lr.storagePath, target)
 	if err != nil {
 		return fmt.Errorf("failed to check if manifest can be deleted: %w", err)
 	}
 	if canDelete {
+		if index != nil {
+			// Variants are cleaned up by deleteVariants below, as some may still be tagged or be part of another
+			// index. Garbage collection in the oci.Store would delete them along with the index.
+			autoGC := lr.Store.AutoGC
+			lr.Store.AutoGC = false
+			defer func() { lr.Store.AutoGC = autoGC }()
+		}
 		if err := lr.Store.Delete(ctx, target); err != nil {
 			return err
 		}
 	}
-	return lr.localIndex.delete(target)
+	if err := lr.localIndex.delete(target); err != nil {
+		return err
+	}
+	if index != nil {
+		return lr.deleteVariants(ctx, index)
+	}
+	return nil
+}
+
+// deleteVariants removes the variant manifests of a deleted index from the local repository, unless
+// they are still tagged or are referenced by another index.
+func (lr *localRepo) deleteVariants(ctx context.Context, index *ocispec.Index) error {
+	for _, variantDesc := range index.Manifests {
+		if !lr.localIndex.exists(variantDesc) || len(lr.GetTags(variantDesc)) > 0 {
+			continue
+		}
+		if _, ok := lr.variantOf(ctx, variantDesc); ok {
+			continue
+		}
+		if err := lr.Delete(ctx, variantDesc); err != nil {
+			return fmt.Errorf("failed to remove variant %s: %w", variantDesc.Digest, err)
+		}
+	}
+	return nil
+}
+
+// variantOf checks whether desc is a variant in any index in the local repository, returning the digest
+// of the first index found.
+func (lr *localRepo) variantOf(ctx context.Context, desc ocispec.Descriptor) (digest.Digest, bool) {
+	for _, indexDesc := range lr.localIndex.Manifests {
+		if indexDesc.MediaType != ocispec.MediaTypeImageIndex || indexDesc.Digest == desc.Digest {
+			continue
+		}
+		index, err := lr.readIndex(ctx, indexDesc)
+		if err != nil {
+			output.SafeLogf(output.LogLevelWarn, "Failed to read index %s: %s", indexDesc.Digest, err)
+			continue
+		}
+		for _, variantDesc := range index.Manifests {
+			if variantDesc.Digest == desc.Digest {
+				return indexDesc.Digest, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (lr *localRepo) readIndex(ctx context.Context, indexDesc ocispec.Descriptor) (*ocispec.Index, error) {
+	indexBytes, err := content.FetchAll(ctx, lr.Store, indexDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", indexDesc.Digest, err)
+	}
+	index := &ocispec.Index{}
+	if err := json.Unmarshal(indexBytes, index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", indexDesc.Digest, err)
+	}
+	return index, nil
 }
 
 func (lr *localRepo) Exists(ctx context.Context, target ocispec.Descriptor) (exists bool, err error) {
-	if target.MediaType == ocispec.MediaTypeImageManifest {
+	if isManifestMediaType(target.MediaType) {
 		exists, err = lr.localIndex.exists(target), nil
 	} else {
 		exists, err = lr.Store.Exists(ctx, target)
@@ -167,7 +243,7 @@ func (lr *localRepo) Exists(ctx context.Context, target ocispec.Descriptor) (exi
 
 func (lr *localRepo) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
 	output.SafeLogf(output.LogLevelTrace, "Fetching digest %s in local repository %s", target.Digest.String(), lr.nameRef)
-	if target.MediaType == ocispec.MediaTypeImageManifest {
+	if isManifestMediaType(target.MediaType) {
 		if exists := lr.localIndex.exists(target); !exists {
 			return nil, errdef.ErrNotFound
 		}
@@ -177,7 +253,7 @@ func (lr *localRepo) Fetch(ctx context.Context, target ocispec.Descriptor) (io.R
 
 func (lr *localRepo) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
 	output.SafeLogf(output.LogLevelTrace, "Pushing digest %s to local repository %s", expected.Digest.String(), lr.nameRef)
-	if expected.MediaType == ocispec.MediaTypeImageManifest {
+	if isManifestMediaType(expected.MediaType) {
 		// Attempting to push a manifest to oci.Store will return an error if it already exists.
 		// Normally, clients check before pushing, but in our case, the manifest may exist in the
 		// oci.Store but not the local index. As a result, we have to check if it exists before pushing.
@@ -227,4 +303,10 @@ func (lr *localRepo) EnsureDirs(desc ocispec.Descriptor) error {
 	return nil
 }
 
+// isManifestMediaType returns true for media types that are tracked in the local repository index: modelkit
+// manifests and image indexes of modelkit variants.
+func isManifestMediaType(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageManifest || mediaType == ocispec.MediaTypeImageIndex
+}
+
 var _ LocalRepo = (*localRepo)(nil)
@@ -44,8 +44,8 @@ type Repository struct {
 
 // Push pushes the content, matching the expected descriptor.
 func (r *Repository) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
-	if expected.MediaType == ocispec.MediaTypeImageManifest {
-		// If it's a manifest, we can just use the regular implementation
+	if expected.MediaType == ocispec.MediaTypeImageManifest || expected.MediaType == ocispec.MediaTypeImageIndex {
+		// If it's a manifest or index, we can just use the regular implementation
 		return r.Repository.Push(ctx, expected, content)
 	}
 
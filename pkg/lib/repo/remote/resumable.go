@@ -0,0 +1,118 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kitops-ml/kitops/pkg/output"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxResumeAttempts is the number of times a single blob download is resumed before giving up
+const maxResumeAttempts = 5
+
+// resumeBackoff is the base delay between resume attempts; it is multiplied by the attempt number
+var resumeBackoff = 1 * time.Second
+
+// resumableReader wraps a blob fetched from a remote registry that supports range requests. If reading
+// fails partway through (e.g. due to a dropped connection), the download is resumed from the current
+// offset instead of restarting from zero. The content read is verified against the descriptor's digest.
+type resumableReader struct {
+	ctx      context.Context
+	blob     io.ReadSeekCloser
+	desc     ocispec.Descriptor
+	verifier digest.Verifier
+	offset   int64
+	attempts int
+}
+
+// NewResumableReader returns a reader for a blob fetched from a remote registry. If the blob supports
+// seeking (i.e. the registry supports range requests), reads that fail are retried from the last
+// successfully read offset. Otherwise, blob is returned unchanged.
+func NewResumableReader(ctx context.Context, blob io.ReadCloser, desc ocispec.Descriptor) io.ReadCloser {
+	seekBlob, ok := blob.(io.ReadSeekCloser)
+	if !ok {
+		return blob
+	}
+	return &resumableReader{
+		ctx:      ctx,
+		blob:     seekBlob,
+		desc:     desc,
+		verifier: desc.Digest.Verifier(),
+	}
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.blob.Read(p)
+		if n > 0 {
+			r.verifier.Write(p[:n])
+			r.offset += int64(n)
+		}
+		switch {
+		case err == nil:
+			return n, nil
+		case errors.Is(err, io.EOF):
+			if r.offset < r.desc.Size {
+				err = io.ErrUnexpectedEOF
+				break
+			}
+			if !r.verifier.Verified() {
+				return n, fmt.Errorf("downloaded content for %s does not match digest", r.desc.Digest)
+			}
+			return n, io.EOF
+		}
+		if r.ctx.Err() != nil || r.attempts >= maxResumeAttempts {
+			return n, err
+		}
+		r.attempts++
+		output.SafeDebugf("Download of %s interrupted at %d bytes, resuming (attempt %d of %d): %s", r.desc.Digest, r.offset, r.attempts, maxResumeAttempts, err)
+		select {
+		case <-r.ctx.Done():
+			return n, r.ctx.Err()
+		case <-time.After(time.Duration(r.attempts) * resumeBackoff):
+		}
+		if resumeErr := r.reopen(); resumeErr != nil {
+			return n, fmt.Errorf("failed to resume download: %w", errors.Join(err, resumeErr))
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// reopen re-requests the blob starting at the current offset using a range request. Seeking to the
+// current offset is a no-op for blobs fetched by oras, so we first seek to the end of the blob (which
+// does not make a request) to force a new request to be made.
+func (r *resumableReader) reopen() error {
+	if _, err := r.blob.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	_, err := r.blob.Seek(r.offset, io.SeekStart)
+	return err
+}
+
+func (r *resumableReader) Close() error {
+	return r.blob.Close()
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyBlob simulates a blob fetched from a registry whose connection drops after failAfter bytes
+// for each of the first numFailures requests.
+type flakyBlob struct {
+	data        string
+	offset      int64
+	read        int64
+	failAfter   int64
+	numFailures int
+	requests    int
+}
+
+func (b *flakyBlob) Read(p []byte) (int, error) {
+	if b.offset >= int64(len(b.data)) {
+		return 0, io.EOF
+	}
+	if b.requests < b.numFailures && b.read >= b.failAfter {
+		return 0, errors.New("connection reset by peer")
+	}
+	n := copy(p[:1], b.data[b.offset:])
+	b.offset += int64(n)
+	b.read += int64(n)
+	return n, nil
+}
+
+func (b *flakyBlob) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekEnd:
+		offset += int64(len(b.data))
+	default:
+		return 0, errors.New("unsupported whence")
+	}
+	if offset < int64(len(b.data)) {
+		b.requests++
+		b.read = 0
+	}
+	b.offset = offset
+	return offset, nil
+}
+
+func (b *flakyBlob) Close() error {
+	return nil
+}
+
+func TestResumableReader(t *testing.T) {
+	resumeBackoff = 0
+	data := strings.Repeat("0123456789", 10)
+	desc := ocispec.Descriptor{
+		Digest: digest.FromString(data),
+		Size:   int64(len(data)),
+	}
+
+	tests := []struct {
+		name        string
+		desc        ocispec.Descriptor
+		numFailures int
+		expectErr   string
+	}{
+		{name: "no failures", desc: desc, numFailures: 0},
+		{name: "resumes after failures", desc: desc, numFailures: 3},
+		{name: "gives up after max attempts", desc: desc, numFailures: maxResumeAttempts + 1, expectErr: "connection reset by peer"},
+		{name: "verifies digest", desc: ocispec.Descriptor{Digest: digest.FromString("other"), Size: desc.Size}, expectErr: "does not match digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := &flakyBlob{data: data, failAfter: 10, numFailures: tt.numFailures}
+			rc := NewResumableReader(context.Background(), blob, tt.desc)
+			read, err := io.ReadAll(rc)
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, data, string(read))
+			assert.Equal(t, tt.numFailures, blob.requests)
+		})
+	}
+}
+
+func TestResumableReaderNotSeekable(t *testing.T) {
+	rc := io.NopCloser(strings.NewReader("test"))
+	assert.Equal(t, rc, NewResumableReader(context.Background(), rc, ocispec.Descriptor{}))
+}
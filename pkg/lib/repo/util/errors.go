@@ -19,3 +19,7 @@ package util
 import "errors"
 
 var ErrNotAModelKit = errors.New("reference exists but is not a modelkit")
+
+// ErrVariantIndex is returned when a modelkit manifest is expected but the reference resolves to an
+// index of modelkit variants. Use SelectVariant to pick a manifest from the index.
+var ErrVariantIndex = errors.New("reference is an index of modelkit variants")
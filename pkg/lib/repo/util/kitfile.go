@@ -21,14 +21,31 @@ import (
 	"strings"
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
 )
 
-// IsModelKitReference returns true if the ref string "looks" like a modelkit reference
+// KitfileReference is a reference to another modelkit in an entry of a Kitfile. Model references use
+// the referenced modelkit's model as the base for the model, while dataset and code references include
+// the datasets or code of the referenced modelkit.
+type KitfileReference struct {
+	// BaseType is the type of the Kitfile entry (constants.ModelType, DatasetType, or CodeType)
+	BaseType string
+	// Index is the index of the entry in the Kitfile's datasets or code list; it is zero for the model
+	Index int
+	// Ref is the reference to the modelkit, as written in the Kitfile
+	Ref string
+}
+
+// IsModelKitReference returns true if the ref string "looks" like a modelkit reference. Paths that start with
+// './' or '../' are never references, so they can be used for local paths that would otherwise look like one.
 func IsModelKitReference(ref string) bool {
 	// If it doesn't have ':' or '@' it's probably not a reference
 	if !strings.Contains(ref, ":") && !strings.Contains(ref, "@") {
 		return false
 	}
+	if strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../") {
+		return false
+	}
 	// Does it parse?
 	if _, _, err := ParseReference(ref); err != nil {
 		return false
@@ -36,6 +53,37 @@ func IsModelKitReference(ref string) bool {
 	return true
 }
 
+// ReferencesFromKitfile returns all references to other modelkits in a Kitfile, in the order they appear.
+func ReferencesFromKitfile(kitfile *artifact.KitFile) []KitfileReference {
+	var refs []KitfileReference
+	if kitfile.Model != nil && IsModelKitReference(kitfile.Model.Path) {
+		refs = append(refs, KitfileReference{BaseType: constants.ModelType, Ref: kitfile.Model.Path})
+	}
+	for idx, dataset := range kitfile.DataSets {
+		if IsModelKitReference(dataset.Path) {
+			refs = append(refs, KitfileReference{BaseType: constants.DatasetType, Index: idx, Ref: dataset.Path})
+		}
+	}
+	for idx, code := range kitfile.Code {
+		if IsModelKitReference(code.Path) {
+			refs = append(refs, KitfileReference{BaseType: constants.CodeType, Index: idx, Ref: code.Path})
+		}
+	}
+	return refs
+}
+
+// ReferencesOfType returns the references in a Kitfile for entries of type baseType. When following a
+// reference, only references of the same type in the referenced modelkit are used.
+func ReferencesOfType(kitfile *artifact.KitFile, baseType string) []KitfileReference {
+	var refs []KitfileReference
+	for _, ref := range ReferencesFromKitfile(kitfile) {
+		if ref.BaseType == baseType {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
 func LayerPathsFromKitfile(kitfile *artifact.KitFile) []string {
 	cleanPath := func(path string) string {
 		return filepath.Clean(strings.TrimSpace(path))
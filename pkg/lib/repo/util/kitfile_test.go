@@ -0,0 +1,49 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/artifact"
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferencesFromKitfile(t *testing.T) {
+	kitfile := &artifact.KitFile{
+		Model: &artifact.Model{Path: "org/base:v1"},
+		DataSets: []artifact.DataSet{
+			{Name: "train", Path: "data/train"},
+			{Name: "eval", Path: "org/eval-data:v1"},
+		},
+		Code: []artifact.Code{
+			{Path: "org/tools@sha256:aec070645fe53ee3b3763059376134f058cc337247c978add178b6ccdfb0019f"},
+			{Path: "src"},
+		},
+	}
+	expected := []KitfileReference{
+		{BaseType: constants.ModelType, Ref: "org/base:v1"},
+		{BaseType: constants.DatasetType, Index: 1, Ref: "org/eval-data:v1"},
+		{BaseType: constants.CodeType, Index: 0, Ref: "org/tools@sha256:aec070645fe53ee3b3763059376134f058cc337247c978add178b6ccdfb0019f"},
+	}
+	assert.Equal(t, expected, ReferencesFromKitfile(kitfile))
+	assert.Equal(t, expected[1:2], ReferencesOfType(kitfile, constants.DatasetType))
+	assert.Empty(t, ReferencesFromKitfile(&artifact.KitFile{Model: &artifact.Model{Path: "model.bin"}}))
+	assert.Empty(t, ReferencesFromKitfile(&artifact.KitFile{DataSets: []artifact.DataSet{{Path: "./data:x"}, {Path: "../data/eval:v2"}}}))
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/kitops-ml/kitops/pkg/artifact"
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
+	"github.com/kitops-ml/kitops/pkg/lib/modelpack"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -176,13 +177,14 @@ func RepoPath(storagePath string, ref *registry.Reference) string {
 }
 
 // GetManifestAndConfig returns the manifest and config (Kitfile) for a manifest Descriptor.
-// Calls GetManifest and GetConfig.
+// Calls GetManifest and GetConfig. For ModelPack artifacts, the returned Kitfile is generated
+// from the ModelPack config.
 func GetManifestAndConfig(ctx context.Context, store oras.ReadOnlyTarget, manifestDesc ocispec.Descriptor) (*ocispec.Manifest, *artifact.KitFile, error) {
 	manifest, err := GetManifest(ctx, store, manifestDesc)
 	if err != nil {
 		return nil, nil, err
 	}
-	config, err := GetConfig(ctx, store, manifest.Config)
+	config, err := getConfigForManifest(ctx, store, manifest)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -192,6 +194,9 @@ func GetManifestAndConfig(ctx context.Context, store oras.ReadOnlyTarget, manife
 // GetManifest returns the Manifest described by a Descriptor. Returns an error if the manifest blob cannot be
 // resolved or does not represent a modelkit manifest.
 func GetManifest(ctx context.Context, store oras.ReadOnlyTarget, manifestDesc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	if manifestDesc.MediaType == ocispec.MediaTypeImageIndex {
+		return nil, ErrVariantIndex
+	}
 	manifestBytes, err := content.FetchAll(ctx, store, manifestDesc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read manifest %s: %w", manifestDesc.Digest, err)
@@ -200,13 +205,26 @@ func GetManifest(ctx context.Context, store oras.ReadOnlyTarget, manifestDesc oc
 	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse manifest %s: %w", manifestDesc.Digest, err)
 	}
-	if manifest.Config.MediaType != constants.ModelConfigMediaType.String() {
+	if !IsModelKitManifest(manifest) {
 		return nil, ErrNotAModelKit
 	}
 
 	return manifest, nil
 }
 
+// IsModelKitManifest returns true if manifest describes a modelkit. Modelkits are stored either as image
+// manifests with a Kitfile config or as OCI 1.1 artifacts, which additionally set an artifactType.
+// ModelPack artifacts are also treated as modelkits, as they can be read by converting their config.
+func IsModelKitManifest(manifest *ocispec.Manifest) bool {
+	if modelpack.IsModelPackManifest(manifest) {
+		return true
+	}
+	if manifest.Config.MediaType != constants.ModelConfigMediaType.String() {
+		return false
+	}
+	return manifest.ArtifactType == "" || manifest.ArtifactType == constants.ModelKitArtifactType
+}
+
 // GetConfig returns the config (Kitfile) described by a descriptor. Returns an error if the config blob cannot
 // be resolved or if the descriptor does not describe a Kitfile.
 func GetConfig(ctx context.Context, store oras.ReadOnlyTarget, configDesc ocispec.Descriptor) (*artifact.KitFile, error) {
@@ -224,6 +242,23 @@ func GetConfig(ctx context.Context, store oras.ReadOnlyTarget, configDesc ocispe
 	return config, nil
 }
 
+// getConfigForManifest returns the Kitfile for a modelkit manifest, converting the config of ModelPack
+// artifacts to a Kitfile.
+func getConfigForManifest(ctx context.Context, store oras.ReadOnlyTarget, manifest *ocispec.Manifest) (*artifact.KitFile, error) {
+	if !modelpack.IsModelPackManifest(manifest) {
+		return GetConfig(ctx, store, manifest.Config)
+	}
+	configBytes, err := content.FetchAll(ctx, store, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	config, err := modelpack.ToKitfile(manifest, configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ModelPack config: %w", err)
+	}
+	return config, nil
+}
+
 // ResolveManifest returns the manifest for a reference (tag), if present in the target store
 func ResolveManifest(ctx context.Context, store oras.Target, reference string) (ocispec.Descriptor, *ocispec.Manifest, error) {
 	desc, err := store.Resolve(ctx, reference)
@@ -238,13 +273,13 @@ func ResolveManifest(ctx context.Context, store oras.Target, reference string) (
 }
 
 // ResolveManifestAndConfig returns the manifest and config (Kitfile) for a given reference (tag), if present
-// in the store. Calls ResolveManifest and GetConfig.
+// in the store. Calls ResolveManifest and GetConfig, converting ModelPack configs as in GetManifestAndConfig.
 func ResolveManifestAndConfig(ctx context.Context, store oras.Target, reference string) (ocispec.Descriptor, *ocispec.Manifest, *artifact.KitFile, error) {
 	desc, manifest, err := ResolveManifest(ctx, store, reference)
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, nil, nil, err
 	}
-	config, err := GetConfig(ctx, store, manifest.Config)
+	config, err := getConfigForManifest(ctx, store, manifest)
 	if err != nil {
 		return ocispec.DescriptorEmptyJSON, nil, nil, err
 	}
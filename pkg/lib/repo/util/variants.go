@@ -0,0 +1,199 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// GetIndex returns the image index described by indexDesc. Only indexes whose entries are modelkit variants
+// (i.e. entries have a variant annotation) are accepted.
+func GetIndex(ctx context.Context, store oras.ReadOnlyTarget, indexDesc ocispec.Descriptor) (*ocispec.Index, error) {
+	if indexDesc.MediaType != ocispec.MediaTypeImageIndex {
+		return nil, fmt.Errorf("expected image index but got %s", indexDesc.MediaType)
+	}
+	indexBytes, err := content.FetchAll(ctx, store, indexDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", indexDesc.Digest, err)
+	}
+	index := &ocispec.Index{}
+	if err := json.Unmarshal(indexBytes, index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", indexDesc.Digest, err)
+	}
+	if !IsVariantIndex(index) {
+		return nil, ErrNotAModelKit
+	}
+	return index, nil
+}
+
+// IsVariantIndex returns true if every entry in index is a manifest annotated as a modelkit variant.
+func IsVariantIndex(index *ocispec.Index) bool {
+	if len(index.Manifests) == 0 {
+		return false
+	}
+	for _, desc := range index.Manifests {
+		if desc.MediaType != ocispec.MediaTypeImageManifest || VariantName(desc) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// VariantName returns the name of the variant described by an index entry, or an empty string if the
+// descriptor is not a variant.
+func VariantName(desc ocispec.Descriptor) string {
+	return desc.Annotations[constants.VariantAnnotation]
+}
+
+// VariantNames returns the names of all variants in index, in index order.
+func VariantNames(index *ocispec.Index) []string {
+	var names []string
+	for _, desc := range index.Manifests {
+		names = append(names, VariantName(desc))
+	}
+	return names
+}
+
+// VariantAnnotationKey returns the annotation key used to store a variant annotation. Keys that are not
+// already namespaced (contain no '.') are prefixed with constants.VariantAnnotationPrefix, so that
+// 'quantization' is stored as 'ml.kitops.modelkit.variant.quantization'.
+func VariantAnnotationKey(key string) string {
+	if strings.Contains(key, ".") {
+		return key
+	}
+	return constants.VariantAnnotationPrefix + key
+}
+
+// ParseVariantAnnotations parses a list of key=value strings into variant annotations, applying
+// VariantAnnotationKey to each key.
+func ParseVariantAnnotations(annotations []string) (map[string]string, error) {
+	parsed := map[string]string{}
+	for _, annotation := range annotations {
+		key, value, ok := strings.Cut(annotation, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variant annotation %q: must be in the format key=value", annotation)
+		}
+		fullKey := VariantAnnotationKey(key)
+		if fullKey == constants.VariantAnnotation {
+			return nil, fmt.Errorf("invalid variant annotation %q: use --variant to set the variant name", annotation)
+		}
+		parsed[fullKey] = strings.TrimSpace(value)
+	}
+	return parsed, nil
+}
+
+// SelectVariant returns the entry in index matching selector. A selector is either the name of a variant
+// (e.g. 'q4') or a comma-separated list of key=value annotations that must all match (e.g.
+// 'quantization=q4,hardware=cuda'). An error is returned if no variant or more than one variant matches.
+func SelectVariant(index *ocispec.Index, selector string) (ocispec.Descriptor, error) {
+	available := strings.Join(VariantNames(index), ", ")
+	if selector == "" {
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("reference contains multiple variants, use --variant to select one of: %s", available)
+	}
+
+	var matches []ocispec.Descriptor
+	if !strings.Contains(selector, "=") {
+		for _, desc := range index.Manifests {
+			if VariantName(desc) == selector {
+				matches = append(matches, desc)
+			}
+		}
+	} else {
+		required, err := ParseVariantAnnotations(strings.Split(selector, ","))
+		if err != nil {
+			return ocispec.DescriptorEmptyJSON, err
+		}
+		for _, desc := range index.Manifests {
+			if matchesAnnotations(desc, required) {
+				matches = append(matches, desc)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("no variant matches %q, available variants: %s", selector, available)
+	case 1:
+		return matches[0], nil
+	default:
+		var names []string
+		for _, desc := range matches {
+			names = append(names, VariantName(desc))
+		}
+		return ocispec.DescriptorEmptyJSON, fmt.Errorf("multiple variants match %q: %s", selector, strings.Join(names, ", "))
+	}
+}
+
+// ResolveVariant returns the modelkit manifest descriptor to use for desc. If desc is an image index,
+// the variant matching selector is selected from it. If desc is a manifest, it is returned unchanged, and an
+// error is returned if a selector is specified.
+func ResolveVariant(ctx context.Context, store oras.ReadOnlyTarget, desc ocispec.Descriptor, selector string) (ocispec.Descriptor, error) {
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		if selector != "" {
+			return ocispec.DescriptorEmptyJSON, fmt.Errorf("cannot select variant %q: reference does not contain variants", selector)
+		}
+		return desc, nil
+	}
+	index, err := GetIndex(ctx, store, desc)
+	if err != nil {
+		return ocispec.DescriptorEmptyJSON, err
+	}
+	return SelectVariant(index, selector)
+}
+
+// SetVariant adds manifestDesc to index as variant name, replacing any existing entry for the same
+// variant. Entries for other variants keep their position in the index.
+func SetVariant(index *ocispec.Index, manifestDesc ocispec.Descriptor, name string, annotations map[string]string) {
+	entry := ocispec.Descriptor{
+		MediaType:    manifestDesc.MediaType,
+		Digest:       manifestDesc.Digest,
+		Size:         manifestDesc.Size,
+		ArtifactType: manifestDesc.ArtifactType,
+		Annotations:  map[string]string{constants.VariantAnnotation: name},
+	}
+	for key, value := range annotations {
+		entry.Annotations[key] = value
+	}
+	idx := slices.IndexFunc(index.Manifests, func(desc ocispec.Descriptor) bool {
+		return VariantName(desc) == name
+	})
+	if idx == -1 {
+		index.Manifests = append(index.Manifests, entry)
+	} else {
+		index.Manifests[idx] = entry
+	}
+}
+
+func matchesAnnotations(desc ocispec.Descriptor, required map[string]string) bool {
+	for key, value := range required {
+		if desc.Annotations[key] != value {
+			return false
+		}
+	}
+	return true
+}
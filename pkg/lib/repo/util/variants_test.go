@@ -0,0 +1,93 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectVariant(t *testing.T) {
+	index := &ocispec.Index{
+		Manifests: []ocispec.Descriptor{
+			variantDesc("q4-cpu", map[string]string{"quantization": "q4", "hardware": "cpu"}),
+			variantDesc("q4-cuda", map[string]string{"quantization": "q4", "hardware": "cuda"}),
+			variantDesc("f16-cuda", map[string]string{"quantization": "f16", "hardware": "cuda"}),
+		},
+	}
+	tests := []struct {
+		selector        string
+		expectedVariant string
+		expectErr       bool
+	}{
+		{selector: "q4-cuda", expectedVariant: "q4-cuda"},
+		{selector: "quantization=f16", expectedVariant: "f16-cuda"},
+		{selector: "quantization=q4,hardware=cpu", expectedVariant: "q4-cpu"},
+		{selector: "quantization=q4, hardware=cuda", expectedVariant: "q4-cuda"},
+		{selector: constants.VariantAnnotationPrefix + "hardware=cpu", expectedVariant: "q4-cpu"},
+		{selector: "", expectErr: true},
+		{selector: "q8", expectErr: true},
+		{selector: "quantization=q4", expectErr: true},
+		{selector: "hardware=tpu", expectErr: true},
+		{selector: "=q4", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			desc, err := SelectVariant(index, tt.selector)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedVariant, VariantName(desc))
+			}
+		})
+	}
+}
+
+func TestSetVariant(t *testing.T) {
+	index := &ocispec.Index{}
+	first := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("first")}
+	second := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("second")}
+	updated := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("updated")}
+
+	SetVariant(index, first, "a", nil)
+	SetVariant(index, second, "b", map[string]string{VariantAnnotationKey("hardware"): "cuda"})
+	SetVariant(index, updated, "a", nil)
+
+	assert.Equal(t, []string{"a", "b"}, VariantNames(index))
+	assert.Equal(t, updated.Digest, index.Manifests[0].Digest)
+	assert.Equal(t, "cuda", index.Manifests[1].Annotations[constants.VariantAnnotationPrefix+"hardware"])
+	assert.True(t, IsVariantIndex(index))
+}
+
+func variantDesc(name string, annotations map[string]string) ocispec.Descriptor {
+	desc := ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Digest:      digest.FromString(name),
+		Annotations: map[string]string{constants.VariantAnnotation: name},
+	}
+	for key, value := range annotations {
+		desc.Annotations[VariantAnnotationKey(key)] = value
+	}
+	return desc
+}
@@ -176,6 +176,20 @@ func (t *ProgressTar) Write(b []byte) (int, error) {
 	return t.tw.Write(b)
 }
 
+// WriteUntracked writes to the tar file without updating progress. This should be used for
+// content that is not included in the total size used to create the progress bar.
+func (t *ProgressTar) WriteUntracked(b []byte) (int, error) {
+	return t.tw.Write(b)
+}
+
+// IncrementProgress updates progress for content that was processed without being written
+// to the tar file.
+func (t *ProgressTar) IncrementProgress(n int64) {
+	if t.bar != nil {
+		t.bar.IncrInt64(n)
+	}
+}
+
 func (t *ProgressTar) WriteHeader(hdr *tar.Header) error {
 	return t.tw.WriteHeader(hdr)
 }
@@ -19,6 +19,8 @@ package output
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 func FormatBytes(i int64) string {
@@ -47,3 +49,39 @@ func FormatBytes(i int64) string {
 	// Fall back to printing whatever's left as PiB
 	return fmt.Sprintf("%.1f %s", size, "PiB")
 }
+
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseBytes parses a human-readable size such as "500MB" or "1.5 GiB" into a number of bytes.
+// Both decimal (KB, MB, ...) and binary (KiB, MiB, ...) units are supported; a number without
+// a unit is interpreted as bytes.
+func ParseBytes(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	numEnd := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numEnd == -1 {
+		numEnd = len(trimmed)
+	}
+	num, err := strconv.ParseFloat(trimmed[:numEnd], 64)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit := strings.ToLower(strings.TrimSpace(trimmed[numEnd:]))
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, trimmed[numEnd:])
+	}
+	return int64(num * multiplier), nil
+}
@@ -50,3 +50,36 @@ func TestFormatBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input     string
+		output    int64
+		expectErr bool
+	}{
+		{input: "0", output: 0},
+		{input: "500", output: 500},
+		{input: "500B", output: 500},
+		{input: "2KB", output: 2000},
+		{input: "2 KiB", output: 2048},
+		{input: "1.5GiB", output: 1.5 * (1 << 30)},
+		{input: "10gb", output: 10e9},
+		{input: "1TiB", output: 1 << 40},
+		{input: "", expectErr: true},
+		{input: "GiB", expectErr: true},
+		{input: "10 XB", expectErr: true},
+		{input: "-1GB", expectErr: true},
+	}
+	for idx, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", idx), func(t *testing.T) {
+			output, err := ParseBytes(tt.input)
+			if tt.expectErr {
+				assert.Error(t, err, "Should fail to parse %q", tt.input)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equalf(t, tt.output, output, "Should convert %q to %d", tt.input, tt.output)
+			}
+		})
+	}
+}
@@ -93,6 +93,12 @@ func TestCompareManifests(t *testing.T) {
 			manifestBPath:    "manifestE.json",
 			expectedDiffPath: "mixed-diff.json",
 		},
+		{
+			name:             "ArtifactTypeMismatch",
+			manifestAPath:    "manifestA.json",
+			manifestBPath:    "manifestF.json",
+			expectedDiffPath: "artifact-type.json",
+		},
 	}
 
 	// Iterate over the test cases.
@@ -140,6 +146,9 @@ func compareDiffResults(expected, received *diff.DiffResult) error {
 	if expected.SameConfig != received.SameConfig {
 		return fmt.Errorf("SameConfig mismatch: expected %v, got %v", expected.SameConfig, received.SameConfig)
 	}
+	if expected.SameArtifactType != received.SameArtifactType {
+		return fmt.Errorf("SameArtifactType mismatch: expected %v, got %v", expected.SameArtifactType, received.SameArtifactType)
+	}
 	if expected.AnnotationsMatch != received.AnnotationsMatch {
 		return fmt.Errorf("AnnotationsMatch mismatch: expected %v, got %v", expected.AnnotationsMatch, received.AnnotationsMatch)
 	}
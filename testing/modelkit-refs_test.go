@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/kitops-ml/kitops/pkg/lib/constants"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type modelkitRefTestcase struct {
@@ -95,3 +98,250 @@ func TestModelKitReferences(t *testing.T) {
 		})
 	}
 }
+
+func TestModelKitReferenceLock(t *testing.T) {
+	testPreflight(t)
+	tmpDir := setupTempDir(t)
+
+	childPath, unpackPath, contextPath := setupTestDirs(t, tmpDir)
+	t.Setenv(constants.KitopsHomeEnvVar, contextPath)
+	parentPath := filepath.Join(tmpDir, "parent")
+	if err := os.MkdirAll(parentPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	parentKitfile := `
+manifestVersion: 1.0.0
+package:
+  name: parent
+model:
+  path: model
+`
+	childKitfile := `
+manifestVersion: 1.0.0
+package:
+  name: child
+model:
+  path: test-lock:parent
+code:
+  - path: src
+`
+	setupKitfileAndKitignore(t, parentPath, parentKitfile, "")
+	setupFiles(t, parentPath, []string{"model/weights-v1.bin"})
+	runCommand(t, expectNoError, "pack", parentPath, "-t", "test-lock:parent")
+
+	setupKitfileAndKitignore(t, childPath, childKitfile, "")
+	setupFiles(t, childPath, []string{"src/main.py"})
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-lock:child-1")
+	lockPath := filepath.Join(childPath, constants.KitfileLockName)
+	lockV1, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("Kitfile.lock should be created when packing: %s", err)
+	}
+	assert.Contains(t, string(lockV1), "reference: test-lock:parent")
+	runCommand(t, expectNoError, "info", "--resolved", "test-lock:child-1")
+
+	// Update the parent under the same tag; the child should still use the pinned parent
+	if err := os.RemoveAll(filepath.Join(parentPath, "model")); err != nil {
+		t.Fatal(err)
+	}
+	setupFiles(t, parentPath, []string{"model/weights-v2.bin"})
+	runCommand(t, expectNoError, "pack", parentPath, "-t", "test-lock:parent")
+
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-lock:child-2")
+	lockAfterPack, err := os.ReadFile(lockPath)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(lockV1), string(lockAfterPack), "Kitfile.lock should not change when packing")
+	}
+	for _, tag := range []string{"test-lock:child-1", "test-lock:child-2"} {
+		unpackDir := filepath.Join(unpackPath, tag)
+		runCommand(t, expectNoError, "unpack", tag, "-d", unpackDir)
+		checkFilesExist(t, unpackDir, []string{"model/weights-v1.bin", "src/main.py"})
+		checkFilesDoNotExist(t, unpackDir, []string{"model/weights-v2.bin"})
+	}
+
+	runCommand(t, expectNoError, "lock", childPath)
+	lockV2, err := os.ReadFile(lockPath)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, string(lockV1), string(lockV2))
+	}
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-lock:child-3")
+	unpackDir := filepath.Join(unpackPath, "child-3")
+	runCommand(t, expectNoError, "unpack", "test-lock:child-3", "-d", unpackDir)
+	checkFilesExist(t, unpackDir, []string{"model/weights-v2.bin"})
+	checkFilesDoNotExist(t, unpackDir, []string{"model/weights-v1.bin"})
+
+	// --update-lock refreshes the lock in the same way as kit lock
+	if err := os.WriteFile(lockPath, lockV1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-lock:child-4", "--update-lock")
+	lockAfterUpdate, err := os.ReadFile(lockPath)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(lockV2), string(lockAfterUpdate))
+	}
+}
+
+func TestModelKitDatasetReferences(t *testing.T) {
+	testPreflight(t)
+	tmpDir := setupTempDir(t)
+
+	childPath, unpackPath, contextPath := setupTestDirs(t, tmpDir)
+	t.Setenv(constants.KitopsHomeEnvVar, contextPath)
+	dataPath := filepath.Join(tmpDir, "eval-data")
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dataKitfile := `
+manifestVersion: 1.0.0
+package:
+  name: eval-data
+datasets:
+  - name: eval
+    path: data
+`
+	childKitfile := `
+manifestVersion: 1.0.0
+package:
+  name: child
+model:
+  path: model
+datasets:
+  - name: eval
+    path: test-ref:data
+  - name: train
+    path: train
+code:
+  - path: src
+`
+	setupKitfileAndKitignore(t, dataPath, dataKitfile, "")
+	setupFiles(t, dataPath, []string{"data/eval.csv"})
+	runCommand(t, expectNoError, "pack", dataPath, "-t", "test-ref:data")
+
+	setupKitfileAndKitignore(t, childPath, childKitfile, "")
+	setupFiles(t, childPath, []string{"model/model.bin", "train/train.csv", "src/main.py"})
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-ref:child")
+	lock, err := os.ReadFile(filepath.Join(childPath, constants.KitfileLockName))
+	if assert.NoError(t, err, "Kitfile.lock should be created when packing") {
+		assert.Contains(t, string(lock), "reference: test-ref:data")
+	}
+
+	manifest, _ := inspectModelKit(t, "test-ref:child")
+	var numDatasetLayers int
+	for _, layer := range manifest.Layers {
+		if constants.ParseMediaType(layer.MediaType).BaseType == constants.DatasetType {
+			numDatasetLayers++
+		}
+	}
+	assert.Equal(t, 1, numDatasetLayers, "Referenced datasets should not be stored in the modelkit")
+
+	unpackDir := filepath.Join(unpackPath, "all")
+	runCommand(t, expectNoError, "unpack", "test-ref:child", "-d", unpackDir)
+	checkFilesExist(t, unpackDir, []string{"model/model.bin", "data/eval.csv", "train/train.csv", "src/main.py"})
+
+	unpackDir = filepath.Join(unpackPath, "filtered")
+	runCommand(t, expectNoError, "unpack", "test-ref:child", "-d", unpackDir, "--filter", "datasets:eval")
+	checkFilesExist(t, unpackDir, []string{"data/eval.csv"})
+	checkFilesDoNotExist(t, unpackDir, []string{"model/model.bin", "train/train.csv", "src/main.py"})
+}
+
+func TestModelKitReferenceLikePaths(t *testing.T) {
+	testPreflight(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("File names cannot contain ':' on Windows")
+	}
+	tmpDir := setupTempDir(t)
+
+	modelKitPath, unpackPath, contextPath := setupTestDirs(t, tmpDir)
+	t.Setenv(constants.KitopsHomeEnvVar, contextPath)
+
+	// Paths that exist in the context directory are packed as local paths even if they look like references
+	setupKitfileAndKitignore(t, modelKitPath, `
+manifestVersion: 1.0.0
+model:
+  path: model
+datasets:
+  - name: train
+    path: data/train:2024.csv
+  - name: eval
+    path: data/eval:v2
+code:
+  - path: ./src:x
+`, "")
+	setupFiles(t, modelKitPath, []string{"model/model.bin", "data/train:2024.csv", "data/eval:v2/eval.csv", "src:x/main.py"})
+	runCommand(t, expectNoError, "pack", modelKitPath, "-t", "test-ref:local")
+	manifest, kitfile := inspectModelKit(t, "test-ref:local")
+	assert.Len(t, manifest.Layers, 4)
+	if assert.Len(t, kitfile.DataSets, 2) {
+		assert.Equal(t, "./data/train:2024.csv", kitfile.DataSets[0].Path)
+	}
+	assert.NoFileExists(t, filepath.Join(modelKitPath, constants.KitfileLockName))
+
+	runCommand(t, expectNoError, "unpack", "test-ref:local", "-d", unpackPath)
+	checkFilesExist(t, unpackPath, []string{"model/model.bin", "data/train:2024.csv", "data/eval:v2/eval.csv", "src:x/main.py"})
+
+	// Paths that do not exist are treated as references, and fail to pack if they cannot be resolved
+	if err := os.Remove(filepath.Join(modelKitPath, "data/train:2024.csv")); err != nil {
+		t.Fatal(err)
+	}
+	out := runCommand(t, expectError, "pack", modelKitPath, "-t", "test-ref:local")
+	assert.Contains(t, out, "failed to resolve referenced modelkit data/train:2024.csv")
+}
+
+func TestModelKitDeps(t *testing.T) {
+	testPreflight(t)
+	tmpDir := setupTempDir(t)
+
+	childPath, _, contextPath := setupTestDirs(t, tmpDir)
+	t.Setenv(constants.KitopsHomeEnvVar, contextPath)
+	basePath := filepath.Join(tmpDir, "base")
+	dataPath := filepath.Join(tmpDir, "data")
+	for _, dir := range []string{basePath, dataPath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setupKitfileAndKitignore(t, basePath, `
+manifestVersion: 1.0.0
+model:
+  path: model
+`, "")
+	setupFiles(t, basePath, []string{"model/model.bin"})
+	runCommand(t, expectNoError, "pack", basePath, "-t", "test-deps:base")
+
+	setupKitfileAndKitignore(t, dataPath, `
+manifestVersion: 1.0.0
+datasets:
+  - name: eval
+    path: eval
+`, "")
+	setupFiles(t, dataPath, []string{"eval/eval.csv"})
+	runCommand(t, expectNoError, "pack", dataPath, "-t", "test-deps:data")
+
+	setupKitfileAndKitignore(t, childPath, `
+manifestVersion: 1.0.0
+model:
+  path: test-deps:base
+datasets:
+  - name: eval
+    path: test-deps:data
+code:
+  - path: src
+`, "")
+	setupFiles(t, childPath, []string{"src/main.py"})
+	runCommand(t, expectNoError, "pack", childPath, "-t", "test-deps:child")
+
+	out := runCommand(t, expectNoError, "deps", "test-deps:child")
+	assert.Contains(t, out, "├── model: test-deps:base sha256:")
+	assert.Contains(t, out, "└── dataset eval: test-deps:data sha256:")
+	assert.Contains(t, out, "[local] 1 layer (dataset)")
+
+	out = runCommand(t, expectNoError, "deps", "test-deps:child", "--output", "dot")
+	assert.Contains(t, out, `"test-deps:child" -> "test-deps:data" [label="dataset eval"];`)
+
+	runCommand(t, expectNoError, "remove", "test-deps:data")
+	out = runCommand(t, expectError, "deps", "test-deps:child")
+	assert.Contains(t, out, "└── dataset eval: test-deps:data (unresolved:")
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 The KitOps Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package testing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+var (
+	registryBlobUploadPath = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/(.*)$`)
+	registryBlobPath       = regexp.MustCompile(`^/v2/(.+)/blobs/([^/]+)$`)
+	registryManifestPath   = regexp.MustCompile(`^/v2/(.+)/manifests/([^/]+)$`)
+)
+
+// testRegistry is a minimal in-memory OCI registry that records blob uploads, for testing commands
+// that push to a remote. It supports a single repository and monolithic blob uploads.
+type testRegistry struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[digest.Digest][]byte
+	uploads   map[digest.Digest]int
+	manifests map[digest.Digest]registryManifest
+	tags      map[string]digest.Digest
+	nextID    int
+}
+
+type registryManifest struct {
+	mediaType string
+	content   []byte
+}
+
+// newTestRegistry starts a testRegistry that is shut down when the test completes.
+func newTestRegistry(t *testing.T) *testRegistry {
+	reg := &testRegistry{
+		blobs:     map[digest.Digest][]byte{},
+		uploads:   map[digest.Digest]int{},
+		manifests: map[digest.Digest]registryManifest{},
+		tags:      map[string]digest.Digest{},
+	}
+	reg.server = httptest.NewServer(http.HandlerFunc(reg.serveHTTP))
+	t.Cleanup(reg.server.Close)
+	return reg
+}
+
+// host returns the host and port the registry is listening on, for use in references.
+func (reg *testRegistry) host() string {
+	return reg.server.Listener.Addr().String()
+}
+
+// uploadCount returns the number of times the blob with digest dgst was uploaded.
+func (reg *testRegistry) uploadCount(dgst string) int {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.uploads[digest.Digest(dgst)]
+}
+
+// resolve returns the digest of the manifest that reference (a tag or digest) refers to, or an
+// empty string if it does not exist.
+func (reg *testRegistry) resolve(reference string) string {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	dgst, ok := reg.tags[reference]
+	if !ok {
+		dgst = digest.Digest(reference)
+	}
+	if _, ok := reg.manifests[dgst]; !ok {
+		return ""
+	}
+	return dgst.String()
+}
+
+func (reg *testRegistry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if r.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if matches := registryBlobUploadPath.FindStringSubmatch(r.URL.Path); matches != nil {
+		reg.serveUpload(w, r, matches[1], matches[2])
+		return
+	}
+	if matches := registryBlobPath.FindStringSubmatch(r.URL.Path); matches != nil {
+		content, ok := reg.blobs[digest.Digest(matches[2])]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeRegistryContent(w, r, "application/octet-stream", matches[2], content)
+		return
+	}
+	if matches := registryManifestPath.FindStringSubmatch(r.URL.Path); matches != nil {
+		reg.serveManifest(w, r, matches[2])
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (reg *testRegistry) serveUpload(w http.ResponseWriter, r *http.Request, name, id string) {
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		reg.nextID += 1
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", name, reg.nextID))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && id != "":
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		dgst, err := digest.Parse(r.URL.Query().Get("digest"))
+		if err != nil || digest.FromBytes(content) != dgst {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reg.blobs[dgst] = content
+		reg.uploads[dgst] += 1
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", name, dgst))
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (reg *testRegistry) serveManifest(w http.ResponseWriter, r *http.Request, reference string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		dgst, ok := reg.tags[reference]
+		if !ok {
+			dgst = digest.Digest(reference)
+		}
+		manifest, ok := reg.manifests[dgst]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeRegistryContent(w, r, manifest.mediaType, dgst.String(), manifest.content)
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		dgst := digest.FromBytes(content)
+		reg.manifests[dgst] = registryManifest{mediaType: r.Header.Get("Content-Type"), content: content}
+		if _, err := digest.Parse(reference); err != nil {
+			reg.tags[reference] = dgst
+		}
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeRegistryContent(w http.ResponseWriter, r *http.Request, mediaType, dgst string, content []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Docker-Content-Digest", dgst)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		io.Copy(w, bytes.NewReader(content))
+	}
+}